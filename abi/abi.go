@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"reflect"
 	"regexp"
 	"strings"
 	"sync"
@@ -254,6 +255,14 @@ func (m *Method) Decode(data []byte) (map[string]interface{}, error) {
 	return resp, nil
 }
 
+// DecodeStruct decodes the output with this function into a Go value (see DecodeStruct)
+func (m *Method) DecodeStruct(data []byte, out interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty response")
+	}
+	return DecodeStruct(m.Outputs, data, out)
+}
+
 func NewMethod(name string) (*Method, error) {
 	name, inputs, outputs, err := parseMethodSignature(name)
 	if err != nil {
@@ -405,6 +414,19 @@ func (e *Event) ParseLog(log *ethgo.Log) (map[string]interface{}, error) {
 	return e.Inputs.ParseLog(log)
 }
 
+// ParseLogStruct parses a log with this event into a Go value (see DecodeStruct)
+func (e *Event) ParseLogStruct(log *ethgo.Log, out interface{}) error {
+	res, err := e.ParseLog(log)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("expected a non-nil pointer but found %T", out)
+	}
+	return setValue(e.Inputs, res, v.Elem(), "")
+}
+
 func buildSignature(name string, typ *Type) string {
 	types := make([]string, len(typ.tuple))
 	for i, input := range typ.tuple {
@@ -7,7 +7,6 @@ import (
 	"reflect"
 	"strconv"
 
-	"github.com/mitchellh/mapstructure"
 	"github.com/umbracle/ethgo"
 )
 
@@ -20,16 +19,20 @@ func Decode(t *Type, input []byte) (interface{}, error) {
 	return val, err
 }
 
-// DecodeStruct decodes the input with a type to a struct
+// DecodeStruct decodes the input with a type to a Go value. The tuples are
+// decoded into structs, whose fields are matched by the 'abi' tag or by the
+// name, or into maps. The arrays are decoded into slices or arrays and the
+// numbers into *big.Int or any Go integer that fits the value.
 func DecodeStruct(t *Type, input []byte, out interface{}) error {
 	val, err := Decode(t, input)
 	if err != nil {
 		return err
 	}
-	if err := mapstructure.Decode(val, out); err != nil {
-		return err
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("expected a non-nil pointer but found %T", out)
 	}
-	return nil
+	return setValue(t, val, v.Elem(), "")
 }
 
 func decode(t *Type, input []byte) (interface{}, []byte, error) {
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
-	"strconv"
 	"strings"
 
 	"github.com/umbracle/ethgo"
@@ -103,26 +102,22 @@ func encodeTuple(v reflect.Value, t *Type) ([]byte, error) {
 		v = v.Elem()
 	}
 
-	var err error
-	isList := true
+	isList, isStruct := false, false
 
 	switch v.Kind() {
 	case reflect.Slice, reflect.Array:
+		isList = true
+
 	case reflect.Map:
-		isList = false
 
 	case reflect.Struct:
-		isList = false
-		v, err = mapFromStruct(v)
-		if err != nil {
-			return nil, err
-		}
+		isStruct = true
 
 	default:
 		return nil, encodeErr(v, "tuple")
 	}
 
-	if v.Len() < len(t.tuple) {
+	if !isStruct && v.Len() < len(t.tuple) {
 		return nil, fmt.Errorf("expected at least the same length")
 	}
 
@@ -135,13 +130,17 @@ func encodeTuple(v reflect.Value, t *Type) ([]byte, error) {
 	var aux reflect.Value
 
 	for i, elem := range t.tuple {
+		name := tupleElemName(elem, i)
+
 		if isList {
 			aux = v.Index(i)
-		} else {
-			name := elem.Name
-			if name == "" {
-				name = strconv.Itoa(i)
+		} else if isStruct {
+			field, ok := fieldByName(v, name)
+			if !ok {
+				return nil, fmt.Errorf("field for '%s' not found in %s", name, v.Type())
 			}
+			aux = field
+		} else {
 			aux = v.MapIndex(reflect.ValueOf(name))
 		}
 		if aux.Kind() == reflect.Invalid {
@@ -200,18 +199,22 @@ func encodeAddress(v reflect.Value) ([]byte, error) {
 }
 
 func encodeBytes(v reflect.Value) ([]byte, error) {
+	buf, err := bytesFromValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return packBytesSlice(buf, len(buf))
+}
+
+// bytesFromValue returns the bytes of a byte slice, a byte array or a hex string
+func bytesFromValue(v reflect.Value) ([]byte, error) {
 	if v.Kind() == reflect.Array {
 		v = convertArrayToBytes(v)
 	}
 	if v.Kind() == reflect.String {
-		value, err := decodeHex(v.String())
-		if err != nil {
-			return nil, err
-		}
-
-		v = reflect.ValueOf(value)
+		return decodeHex(v.String())
 	}
-	return packBytesSlice(v.Bytes(), v.Len())
+	return v.Bytes(), nil
 }
 
 func encodeString(v reflect.Value) ([]byte, error) {
@@ -280,33 +283,6 @@ func encodeErr(v reflect.Value, t string) error {
 	return fmt.Errorf("failed to encode %s as %s", v.Kind().String(), t)
 }
 
-func mapFromStruct(v reflect.Value) (reflect.Value, error) {
-	res := map[string]interface{}{}
-	typ := v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		f := typ.Field(i)
-		if f.PkgPath != "" {
-			continue
-		}
-
-		tagValue := f.Tag.Get("abi")
-		if tagValue == "-" {
-			continue
-		}
-
-		name := f.Name
-		if tagValue != "" {
-			name = tagValue
-		}
-
-		name = strings.ToLower(name)
-		if _, ok := res[name]; !ok {
-			res[name] = v.Field(i).Interface()
-		}
-	}
-	return reflect.ValueOf(res), nil
-}
-
 var (
 	tt256   = new(big.Int).Lsh(big.NewInt(1), 256)   // 2 ** 256
 	tt256m1 = new(big.Int).Sub(tt256, big.NewInt(1)) // 2 ** 256 - 1
@@ -0,0 +1,121 @@
+package abi
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// EncodePacked encodes the values with the non-standard packed mode of
+// Solidity (abi.encodePacked). The static types use only the bytes of their
+// size, the strings and the bytes are not padded and do not include their
+// length, and the elements of the arrays are padded to 32 bytes. The values
+// accept the same Go types as Encode. The encoding is ambiguous and it cannot
+// be decoded, it is meant to be hashed (i.e. keccak256(abi.encodePacked(...))).
+func EncodePacked(values []interface{}, types []*Type) ([]byte, error) {
+	if len(values) != len(types) {
+		return nil, fmt.Errorf("expected %d values but found %d", len(types), len(values))
+	}
+
+	var ret []byte
+	for i, t := range types {
+		val, err := encodePacked(reflect.ValueOf(values[i]), t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value %d: %v", i, err)
+		}
+		ret = append(ret, val...)
+	}
+	return ret, nil
+}
+
+func encodePacked(v reflect.Value, t *Type) ([]byte, error) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch t.kind {
+	case KindSlice, KindArray:
+		return encodePackedSliceAndArray(v, t)
+
+	case KindString:
+		if v.Kind() != reflect.String {
+			return nil, encodeErr(v, "string")
+		}
+		return []byte(v.String()), nil
+
+	case KindBytes:
+		return bytesFromValue(v)
+
+	case KindTuple:
+		return nil, fmt.Errorf("packed encoding not available for tuples")
+
+	default:
+		val, err := encode(v, t)
+		if err != nil {
+			return nil, err
+		}
+		return packStatic(val, t)
+	}
+}
+
+// packStatic trims the standard 32 bytes encoding of a static type to its size
+func packStatic(val []byte, t *Type) ([]byte, error) {
+	switch t.kind {
+	case KindBool:
+		return val[31:], nil
+
+	case KindAddress:
+		return val[12:], nil
+
+	case KindFixedBytes, KindFunction:
+		return val[:t.size], nil
+
+	case KindUInt, KindInt:
+		size := t.size / 8
+		prefix, res := val[:32-size], val[32-size:]
+
+		// the bytes removed have to be the extension of the value
+		ext := byte(0)
+		if t.kind == KindInt && res[0]&0x80 != 0 {
+			ext = 0xff
+		}
+		if !bytes.Equal(prefix, bytes.Repeat([]byte{ext}, len(prefix))) {
+			return nil, fmt.Errorf("value out of range for '%s'", t.String())
+		}
+		return res, nil
+
+	default:
+		return nil, fmt.Errorf("packed encoding not available for type '%s'", t.kind)
+	}
+}
+
+func encodePackedSliceAndArray(v reflect.Value, t *Type) ([]byte, error) {
+	if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+		return nil, encodeErr(v, t.kind.String())
+	}
+	if t.kind == KindArray && t.size != v.Len() {
+		return nil, fmt.Errorf("array len incompatible")
+	}
+
+	switch t.elem.kind {
+	case KindSlice, KindArray, KindTuple, KindString, KindBytes:
+		return nil, fmt.Errorf("packed encoding not available for arrays of '%s'", t.elem.kind)
+	}
+
+	// the elements of the arrays are padded to 32 bytes
+	var ret []byte
+	for i := 0; i < v.Len(); i++ {
+		val, err := encode(v.Index(i), t.elem)
+		if err != nil {
+			return nil, err
+		}
+		if t.elem.kind == KindUInt || t.elem.kind == KindInt {
+			// check the range of the number
+			if _, err := packStatic(val, t.elem); err != nil {
+				return nil, err
+			}
+		}
+		ret = append(ret, val...)
+	}
+	return ret, nil
+}
@@ -0,0 +1,81 @@
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+)
+
+func TestEncodePacked(t *testing.T) {
+	cases := []struct {
+		types  []string
+		values []interface{}
+		res    string
+	}{
+		{
+			// example of the solidity docs
+			[]string{"int16", "bytes1", "uint16", "string"},
+			[]interface{}{int16(-1), [1]byte{0x42}, uint16(3), "Hello, world!"},
+			"ffff42000348656c6c6f2c20776f726c6421",
+		},
+		{
+			[]string{"address", "uint256"},
+			[]interface{}{ethgo.Address{0x1}, big.NewInt(1)},
+			"0100000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000001",
+		},
+		{
+			[]string{"bool", "bytes", "uint8"},
+			[]interface{}{true, "0x0102", "0x10"},
+			"01010210",
+		},
+		{
+			[]string{"uint16[]", "bool[2]"},
+			[]interface{}{[]uint16{1, 2}, [2]bool{true, false}},
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			[]string{"int24", "bytes32"},
+			[]interface{}{big.NewInt(-2), ethgo.Hash{0x1}},
+			"fffffe" + "0100000000000000000000000000000000000000000000000000000000000000",
+		},
+	}
+
+	for _, c := range cases {
+		types := []*Type{}
+		for _, typ := range c.types {
+			types = append(types, MustNewType(typ))
+		}
+		res, err := EncodePacked(c.values, types)
+		assert.NoError(t, err)
+		assert.Equal(t, c.res, hex.EncodeToString(res))
+	}
+}
+
+func TestEncodePacked_Errors(t *testing.T) {
+	cases := []struct {
+		typ string
+		val interface{}
+	}{
+		{"uint8", 256},
+		{"uint8", -1},
+		{"int8", 128},
+		{"int8[]", []int{1, -129}},
+		{"string[]", []string{"a"}},
+		{"tuple(uint256 a)", map[string]interface{}{"a": 1}},
+	}
+	for _, c := range cases {
+		_, err := EncodePacked([]interface{}{c.val}, []*Type{MustNewType(c.typ)})
+		assert.Error(t, err, c.typ)
+	}
+
+	// the number of values and types has to match
+	_, err := EncodePacked([]interface{}{1, 2}, []*Type{MustNewType("uint8")})
+	assert.Error(t, err)
+}
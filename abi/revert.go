@@ -2,10 +2,16 @@ package abi
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"math/big"
+	"strings"
 )
 
-var revertId = []byte{0x8, 0xC3, 0x79, 0xA0}
+var (
+	revertId = []byte{0x8, 0xC3, 0x79, 0xA0}
+	panicId  = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
 
 func UnpackRevertError(b []byte) (string, error) {
 	if !bytes.HasPrefix(b, revertId) {
@@ -21,3 +27,147 @@ func UnpackRevertError(b []byte) (string, error) {
 	revVal := vals.(map[string]interface{})["0"].(string)
 	return revVal, nil
 }
+
+// UnpackPanic returns the code of a Panic(uint256) error
+func UnpackPanic(b []byte) (*big.Int, error) {
+	if !bytes.HasPrefix(b, panicId) {
+		return nil, fmt.Errorf("panic prefix not found")
+	}
+
+	tt := MustNewType("tuple(uint256)")
+	vals, err := tt.Decode(b[4:])
+	if err != nil {
+		return nil, err
+	}
+	return vals.(map[string]interface{})["0"].(*big.Int), nil
+}
+
+// panicReasons are the reasons of the panic codes of the solidity compiler
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to uninitialized internal function",
+}
+
+// PanicReason returns the description of a panic code
+func PanicReason(code *big.Int) string {
+	if code.IsUint64() {
+		if reason, ok := panicReasons[code.Uint64()]; ok {
+			return reason
+		}
+	}
+	return fmt.Sprintf("unknown panic code 0x%x", code)
+}
+
+// Sig returns the signature of the error
+func (e *Error) Sig() string {
+	return buildSignature(e.Name, e.Inputs)
+}
+
+// ID returns the selector of the error
+func (e *Error) ID() []byte {
+	k := acquireKeccak()
+	k.Write([]byte(e.Sig()))
+	dst := k.Sum(nil)[:4]
+	releaseKeccak(k)
+	return dst
+}
+
+// Decode decodes the arguments of the error from the revert data
+func (e *Error) Decode(data []byte) (map[string]interface{}, error) {
+	if !bytes.HasPrefix(data, e.ID()) {
+		return nil, fmt.Errorf("error selector of '%s' not found", e.Name)
+	}
+	resp, err := Decode(e.Inputs, data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return resp.(map[string]interface{}), nil
+}
+
+// DecodedError is the error decoded from the revert data of a call
+type DecodedError struct {
+	// Name is the name of the error. It is 'Error' for the revert
+	// reasons and 'Panic' for the panics.
+	Name string
+
+	// Abi is the custom error of the abi, nil for the revert reasons and the panics
+	Abi *Error
+
+	// Args are the decoded arguments of a custom error
+	Args map[string]interface{}
+
+	// Reason is the revert reason or the description of the panic code
+	Reason string
+
+	// PanicCode is the code of the panic
+	PanicCode *big.Int
+
+	// Data is the raw revert data
+	Data []byte
+}
+
+// Error implements the error interface
+func (d *DecodedError) Error() string {
+	switch {
+	case d.Abi != nil:
+		args := make([]string, len(d.Abi.Inputs.tuple))
+		for i, elem := range d.Abi.Inputs.tuple {
+			name := elem.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", i)
+			}
+			args[i] = fmt.Sprintf("%s: %v", name, d.Args[name])
+		}
+		return fmt.Sprintf("%s(%s)", d.Name, strings.Join(args, ", "))
+	case d.PanicCode != nil:
+		return fmt.Sprintf("panic: %s (0x%x)", d.Reason, d.PanicCode)
+	default:
+		return d.Reason
+	}
+}
+
+// DecodeError decodes the revert data of a call. It decodes the revert reasons,
+// the panic codes and the custom errors of the abi. It can be called on a nil
+// abi to decode only the revert reasons and the panics.
+func (a *ABI) DecodeError(data []byte) (*DecodedError, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("revert data too short")
+	}
+
+	if bytes.HasPrefix(data, revertId) {
+		reason, err := UnpackRevertError(data)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedError{Name: "Error", Reason: reason, Data: data}, nil
+	}
+	if bytes.HasPrefix(data, panicId) {
+		code, err := UnpackPanic(data)
+		if err != nil {
+			return nil, err
+		}
+		return &DecodedError{Name: "Panic", Reason: PanicReason(code), PanicCode: code, Data: data}, nil
+	}
+
+	if a != nil {
+		for _, e := range a.Errors {
+			if !bytes.Equal(e.ID(), data[:4]) {
+				continue
+			}
+			args, err := e.Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			return &DecodedError{Name: e.Name, Abi: e, Args: args, Data: data}, nil
+		}
+	}
+	return nil, fmt.Errorf("error with selector 0x%s not found", hex.EncodeToString(data[:4]))
+}
@@ -1,6 +1,8 @@
 package abi
 
 import (
+	"encoding/hex"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,3 +18,40 @@ func TestUnpackRevertError(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "revert reason", reason)
 }
+
+func TestDecodeError_Panic(t *testing.T) {
+	data := "4e487b710000000000000000000000000000000000000000000000000000000000000011"
+
+	raw, err := decodeHex(data)
+	assert.NoError(t, err)
+
+	var a *ABI
+	decoded, err := a.DecodeError(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, "Panic", decoded.Name)
+	assert.Equal(t, big.NewInt(0x11), decoded.PanicCode)
+	assert.Equal(t, "panic: arithmetic underflow or overflow (0x11)", decoded.Error())
+}
+
+func TestDecodeError_Custom(t *testing.T) {
+	a, err := NewABIFromList([]string{
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	})
+	assert.NoError(t, err)
+
+	e := a.Errors["InsufficientBalance"]
+	assert.Equal(t, "0xcf479181", "0x"+hex.EncodeToString(e.ID()))
+
+	args, err := e.Inputs.Encode([]interface{}{big.NewInt(1), big.NewInt(2)})
+	assert.NoError(t, err)
+
+	decoded, err := a.DecodeError(append(e.ID(), args...))
+	assert.NoError(t, err)
+	assert.Equal(t, "InsufficientBalance", decoded.Name)
+	assert.Equal(t, big.NewInt(2), decoded.Args["required"])
+	assert.Equal(t, "InsufficientBalance(available: 1, required: 2)", decoded.Error())
+
+	// unknown selector
+	_, err = a.DecodeError([]byte{0x1, 0x2, 0x3, 0x4})
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package abi
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldByName returns the field of a struct for the name of a tuple element.
+// The field is matched by its 'abi' tag or, if it has no tag, by its name
+// without case sensitivity. The fields with the '-' tag are ignored.
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	typ := v.Type()
+
+	match := -1
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag := f.Tag.Get("abi")
+		if tag == "-" {
+			continue
+		}
+		if tag != "" {
+			if tag == name {
+				return v.Field(i), true
+			}
+			continue
+		}
+		if match == -1 && strings.EqualFold(f.Name, name) {
+			match = i
+		}
+	}
+	if match == -1 {
+		return reflect.Value{}, false
+	}
+	return v.Field(match), true
+}
+
+// tupleElemName returns the name of the element of a tuple or its
+// position if it does not have a name
+func tupleElemName(elem *TupleElem, indx int) string {
+	if elem.Name != "" {
+		return elem.Name
+	}
+	return strconv.Itoa(indx)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func decodeErr(t *Type, dst reflect.Value, path string) error {
+	if path == "" {
+		return fmt.Errorf("cannot decode '%s' into %s", t.String(), dst.Type())
+	}
+	return fmt.Errorf("cannot decode '%s' into %s at '%s'", t.String(), dst.Type(), path)
+}
+
+// setValue sets the decoded value of a type into a Go value
+func setValue(t *Type, val interface{}, dst reflect.Value, path string) error {
+	// allocate the pointers, big.Int is the only type set as a pointer
+	if dst.Kind() == reflect.Ptr && dst.Type() != bigIntT {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return setValue(t, val, dst.Elem(), path)
+	}
+	if dst.Kind() == reflect.Interface && dst.NumMethod() == 0 {
+		dst.Set(reflect.ValueOf(val))
+		return nil
+	}
+
+	switch t.kind {
+	case KindTuple:
+		return setTuple(t, val, dst, path)
+
+	case KindSlice, KindArray:
+		return setSliceAndArray(t, val, dst, path)
+
+	case KindInt, KindUInt:
+		return setNum(t, val, dst, path)
+	}
+
+	// the rest of the types are set if the Go types are compatible
+	// (i.e. bytes32 into ethgo.Hash or [32]byte)
+	v := reflect.ValueOf(val)
+	if v.Type().AssignableTo(dst.Type()) {
+		dst.Set(v)
+		return nil
+	}
+	if v.Kind() == dst.Kind() && v.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(v.Convert(dst.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Array && dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8 {
+		dst.SetBytes(convertArrayToBytes(v).Bytes())
+		return nil
+	}
+	return decodeErr(t, dst, path)
+}
+
+func setTuple(t *Type, val interface{}, dst reflect.Value, path string) error {
+	obj, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a tuple value but found %T", val)
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		for indx, elem := range t.tuple {
+			name := tupleElemName(elem, indx)
+
+			elemVal, ok := obj[name]
+			if !ok {
+				return fmt.Errorf("value for '%s' not found", joinPath(path, name))
+			}
+			field, ok := fieldByName(dst, name)
+			if !ok {
+				return fmt.Errorf("field for '%s' not found in %s", joinPath(path, name), dst.Type())
+			}
+			if err := setValue(elem.Elem, elemVal, field, joinPath(path, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			return decodeErr(t, dst, path)
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for indx, elem := range t.tuple {
+			name := tupleElemName(elem, indx)
+
+			elemVal := reflect.New(dst.Type().Elem()).Elem()
+			if err := setValue(elem.Elem, obj[name], elemVal, joinPath(path, name)); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(name).Convert(dst.Type().Key()), elemVal)
+		}
+		return nil
+
+	default:
+		return decodeErr(t, dst, path)
+	}
+}
+
+func setSliceAndArray(t *Type, val interface{}, dst reflect.Value, path string) error {
+	vals, ok := val.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected an array value but found %T", val)
+	}
+
+	switch dst.Kind() {
+	case reflect.Slice:
+		dst.Set(reflect.MakeSlice(dst.Type(), len(vals), len(vals)))
+
+	case reflect.Array:
+		if dst.Len() != len(vals) {
+			return fmt.Errorf("cannot decode %d elements into %s at '%s'", len(vals), dst.Type(), path)
+		}
+
+	default:
+		return decodeErr(t, dst, path)
+	}
+
+	for indx, elem := range vals {
+		if err := setValue(t.elem, elem, dst.Index(indx), fmt.Sprintf("%s[%d]", path, indx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setNum(t *Type, val interface{}, dst reflect.Value, path string) error {
+	var num *big.Int
+
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num = big.NewInt(v.Int())
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num = new(big.Int).SetUint64(v.Uint())
+
+	case reflect.Ptr:
+		n, ok := val.(*big.Int)
+		if !ok {
+			return fmt.Errorf("expected a number but found %T", val)
+		}
+		num = n
+
+	default:
+		return fmt.Errorf("expected a number but found %T", val)
+	}
+
+	overflowErr := func() error {
+		return fmt.Errorf("value %s of '%s' overflows %s", num.String(), path, dst.Type())
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		// only *big.Int reaches here
+		dst.Set(reflect.ValueOf(new(big.Int).Set(num)))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !num.IsInt64() || dst.OverflowInt(num.Int64()) {
+			return overflowErr()
+		}
+		dst.SetInt(num.Int64())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if num.Sign() < 0 || !num.IsUint64() || dst.OverflowUint(num.Uint64()) {
+			return overflowErr()
+		}
+		dst.SetUint(num.Uint64())
+
+	default:
+		return decodeErr(t, dst, path)
+	}
+	return nil
+}
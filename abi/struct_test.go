@@ -0,0 +1,165 @@
+package abi
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+)
+
+type structTestItem struct {
+	ID    *big.Int `abi:"itemId"`
+	Owner ethgo.Address
+	Tags  [2][4]byte
+}
+
+type structTestObj struct {
+	Amount uint64
+	Hash   ethgo.Hash `abi:"hash"`
+	Data   []byte
+	Item   structTestItem
+	Items  []*structTestItem
+	Ignore string `abi:"-"`
+}
+
+func TestDecodeStruct(t *testing.T) {
+	typ := MustNewType("tuple(uint256 amount, bytes32 hash, bytes data, tuple(uint256 itemId, address owner, bytes4[2] tags) item, tuple(uint256 itemId, address owner, bytes4[2] tags)[] items)")
+
+	obj := structTestObj{
+		Amount: 10,
+		Hash:   ethgo.Hash{0x1},
+		Data:   []byte{0x1, 0x2},
+		Item: structTestItem{
+			ID:    big.NewInt(1),
+			Owner: ethgo.Address{0x1},
+			Tags:  [2][4]byte{{0x1}, {0x2}},
+		},
+		Items: []*structTestItem{
+			{ID: big.NewInt(2), Owner: ethgo.Address{0x2}, Tags: [2][4]byte{{0x3}, {0x4}}},
+			{ID: big.NewInt(3), Owner: ethgo.Address{0x3}, Tags: [2][4]byte{{0x5}, {0x6}}},
+		},
+	}
+
+	// the encoding honours the same tags
+	encoded, err := typ.Encode(&obj)
+	assert.NoError(t, err)
+
+	var obj2 structTestObj
+	assert.NoError(t, typ.DecodeStruct(encoded, &obj2))
+	assert.True(t, reflect.DeepEqual(obj, obj2))
+
+	// the tuples can be decoded into maps
+	var obj3 map[string]interface{}
+	assert.NoError(t, typ.DecodeStruct(encoded, &obj3))
+	assert.Equal(t, big.NewInt(10), obj3["amount"])
+}
+
+func TestDecodeStruct_Errors(t *testing.T) {
+	typ := MustNewType("tuple(uint256 a, tuple(int16 c)[] b)")
+
+	encoded, err := typ.Encode(map[string]interface{}{
+		"a": big.NewInt(300),
+		"b": []map[string]interface{}{
+			{"c": int16(-1)},
+		},
+	})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		out interface{}
+		err string
+	}{
+		{
+			&struct{ A *big.Int }{},
+			"field for 'b' not found",
+		},
+		{
+			&struct {
+				A uint8
+				B []struct{ C int16 }
+			}{},
+			"value 300 of 'a' overflows uint8",
+		},
+		{
+			&struct {
+				A uint64
+				B []struct{ C uint16 }
+			}{},
+			"value -1 of 'b[0].c' overflows uint16",
+		},
+		{
+			&struct {
+				A string
+				B []struct{ C int16 }
+			}{},
+			"cannot decode 'uint256' into string at 'a'",
+		},
+		{
+			&struct {
+				A *big.Int
+				B [2]struct{ C int16 }
+			}{},
+			"cannot decode 1 elements",
+		},
+	}
+	for _, c := range cases {
+		err := typ.DecodeStruct(encoded, c.out)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), c.err)
+		}
+	}
+
+	var obj struct{}
+	assert.Error(t, typ.DecodeStruct(encoded, obj))
+}
+
+func TestMethodAndEvent_DecodeStruct(t *testing.T) {
+	a, err := NewABIFromList([]string{
+		"function getPerson(uint256 id) returns (tuple(string name, uint16 age) person)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+	})
+	assert.NoError(t, err)
+
+	type Person struct {
+		Name string
+		Age  uint16
+	}
+	m := a.GetMethod("getPerson")
+
+	data, err := m.Outputs.Encode(map[string]interface{}{
+		"person": map[string]interface{}{"name": "a", "age": uint16(10)},
+	})
+	assert.NoError(t, err)
+
+	var out struct {
+		Person *Person
+	}
+	assert.NoError(t, m.DecodeStruct(data, &out))
+	assert.Equal(t, &Person{Name: "a", Age: 10}, out.Person)
+
+	// event
+	evnt := a.Events["Transfer"]
+	value, err := Encode([]interface{}{big.NewInt(5)}, MustNewType("tuple(uint256)"))
+	assert.NoError(t, err)
+
+	log := &ethgo.Log{
+		Topics: []ethgo.Hash{
+			evnt.ID(),
+			ethgo.BytesToHash(ethgo.Address{0x1}.Bytes()),
+			ethgo.BytesToHash(ethgo.Address{0x2}.Bytes()),
+		},
+		Data: value,
+	}
+
+	var transfer struct {
+		From  ethgo.Address
+		To    [20]byte
+		Value *big.Int
+	}
+	assert.NoError(t, evnt.ParseLogStruct(log, &transfer))
+	assert.Equal(t, ethgo.Address{0x1}, transfer.From)
+	assert.Equal(t, [20]byte{0x2}, transfer.To)
+	assert.Equal(t, big.NewInt(5), transfer.Value)
+}
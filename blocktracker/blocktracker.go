@@ -10,6 +10,8 @@ import (
 
 	"github.com/umbracle/ethgo"
 	"github.com/umbracle/ethgo/jsonrpc"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+	"github.com/umbracle/ethgo/metrics"
 )
 
 // BlockProvider are the eth1x methods required by the block tracker
@@ -38,6 +40,7 @@ type BlockTracker struct {
 type Config struct {
 	Tracker         BlockTrackerInterface
 	MaxBlockBacklog uint64
+	Tracer          metrics.Tracer
 }
 
 func DefaultConfig() *Config {
@@ -60,6 +63,13 @@ func WithTracker(b BlockTrackerInterface) ConfigOption {
 	}
 }
 
+// WithTracer traces the polls of the default json block tracker
+func WithTracer(t metrics.Tracer) ConfigOption {
+	return func(c *Config) {
+		c.Tracer = t
+	}
+}
+
 func NewBlockTracker(provider BlockProvider, opts ...ConfigOption) *BlockTracker {
 	config := DefaultConfig()
 	for _, opt := range opts {
@@ -67,7 +77,9 @@ func NewBlockTracker(provider BlockProvider, opts ...ConfigOption) *BlockTracker
 	}
 	tracker := config.Tracker
 	if tracker == nil {
-		tracker = NewJSONBlockTracker(log.New(os.Stderr, "", log.LstdFlags), provider)
+		jsonTracker := NewJSONBlockTracker(log.New(os.Stderr, "", log.LstdFlags), provider)
+		jsonTracker.Tracer = config.Tracer
+		tracker = jsonTracker
 	}
 	return &BlockTracker{
 		blocks:     []*ethgo.Block{},
@@ -315,6 +327,7 @@ const (
 type JSONBlockTracker struct {
 	logger       *log.Logger
 	PollInterval time.Duration
+	Tracer       metrics.Tracer
 	provider     BlockProvider
 }
 
@@ -338,7 +351,7 @@ func (k *JSONBlockTracker) Track(ctx context.Context, handle func(block *ethgo.B
 				return
 
 			case <-time.After(k.PollInterval):
-				block, err := k.provider.GetBlockByNumber(ethgo.Latest, false)
+				block, err := k.poll(ctx)
 				if err != nil {
 					k.logger.Printf("[ERR]: Tracker failed to get last block: %v", err)
 					continue
@@ -360,11 +373,31 @@ func (k *JSONBlockTracker) Track(ctx context.Context, handle func(block *ethgo.B
 	return nil
 }
 
+// poll queries the head of the chain
+func (k *JSONBlockTracker) poll(ctx context.Context) (*ethgo.Block, error) {
+	ctx, span := metrics.StartSpan(ctx, k.Tracer, "blocktracker.poll")
+
+	var provider BlockProvider = k.provider
+	if p, ok := provider.(jsonrpc.ContextProvider); ok {
+		provider = p.WithContext(ctx)
+	}
+	block, err := provider.GetBlockByNumber(ethgo.Latest, false)
+	if err == nil {
+		span.SetAttributes(metrics.Attr("block.number", block.Number))
+	}
+	metrics.EndSpan(span, err)
+
+	return block, err
+}
+
 // SubscriptionBlockTracker is an interface to track new blocks using
 // the newHeads subscription endpoint
 type SubscriptionBlockTracker struct {
 	logger *log.Logger
 	client *jsonrpc.Client
+
+	// Tracer traces the polls of the head after a reconnect
+	Tracer metrics.Tracer
 }
 
 // NewSubscriptionBlockTracker creates a new block tracker using the subscription endpoint
@@ -389,6 +422,23 @@ func (s *SubscriptionBlockTracker) Track(ctx context.Context, handle func(block
 		return err
 	}
 
+	// the subscription is started again by the transport after a reconnect
+	// but the blocks produced while it was down are lost. Query the head once
+	// the connection is back and let the tracker backfill the gap.
+	reconnectCh := make(chan struct{}, 1)
+	removeHook, err := s.client.OnConnState(func(state transport.ConnState) {
+		if state == transport.ConnStateConnected {
+			select {
+			case reconnectCh <- struct{}{}:
+			default:
+			}
+		}
+	})
+	if err != nil {
+		// the transport does not reconnect
+		removeHook = func() {}
+	}
+
 	go func() {
 		for {
 			select {
@@ -400,8 +450,20 @@ func (s *SubscriptionBlockTracker) Track(ctx context.Context, handle func(block
 					handle(&block)
 				}
 
+			case <-reconnectCh:
+				spanCtx, span := metrics.StartSpan(ctx, s.Tracer, "blocktracker.poll", metrics.Attr("reconnect", true))
+				block, err := s.client.Eth().WithContext(spanCtx).GetBlockByNumber(ethgo.Latest, false)
+				metrics.EndSpan(span, err)
+				if err != nil {
+					s.logger.Printf("[ERR]: Tracker failed to get last block after reconnect: %v", err)
+				} else if err := handle(block); err != nil {
+					s.logger.Printf("[ERROR]: blocktracker: Failed to handle block: %v", err)
+				}
+
 			case <-ctx.Done():
+				removeHook()
 				cancel()
+				return
 			}
 		}
 	}()
@@ -2,6 +2,7 @@ package contract
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -29,7 +30,7 @@ func (j *jsonRPCNodeProvider) Call(addr ethgo.Address, input []byte, opts *CallO
 	if opts.From != ethgo.ZeroAddress {
 		msg.From = opts.From
 	}
-	rawStr, err := j.client.Call(msg, opts.Block)
+	rawStr, err := j.client.CallWithOverrides(msg, opts.Block, opts.StateOverride, opts.BlockOverrides)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +150,7 @@ func (j *jsonrpcTransaction) Wait() (*ethgo.Receipt, error) {
 	for {
 		receipt, err := j.client.GetTransactionReceipt(j.hash)
 		if err != nil {
-			if err.Error() != "not found" {
+			if !errors.Is(err, jsonrpc.ErrNotFound) {
 				return nil, err
 			}
 		}
@@ -159,6 +160,48 @@ func (j *jsonrpcTransaction) Wait() (*ethgo.Receipt, error) {
 	}
 }
 
+// RevertError is the error of a call or a transaction of the contract whose
+// execution reverted. The revert data is decoded with the errors of the abi.
+type RevertError struct {
+	// Err is the decoded revert data, nil if the data does not match
+	// a revert reason, a panic or an error of the abi
+	Err *abi.DecodedError
+
+	// Data is the raw revert data
+	Data []byte
+
+	err error
+}
+
+// Error implements the error interface
+func (r *RevertError) Error() string {
+	if r.Err != nil {
+		return fmt.Sprintf("execution reverted: %s", r.Err.Error())
+	}
+	return r.err.Error()
+}
+
+// Unwrap returns the error of the provider
+func (r *RevertError) Unwrap() error {
+	return r.err
+}
+
+// decodeRevert wraps the reverted executions of the provider in a RevertError
+func (a *Contract) decodeRevert(err error) error {
+	var revertErr *jsonrpc.RevertError
+	if !errors.As(err, &revertErr) {
+		return err
+	}
+	res := &RevertError{
+		Data: revertErr.Data,
+		err:  err,
+	}
+	if decoded, decodeErr := a.abi.DecodeError(revertErr.Data); decodeErr == nil {
+		res.Err = decoded
+	}
+	return res
+}
+
 // Txn is the transaction object returned
 type Txn interface {
 	Hash() ethgo.Hash
@@ -296,7 +339,7 @@ func (a *Contract) Txn(method string, args ...interface{}) (Txn, error) {
 
 	txn, err := a.provider.Txn(a.addr, a.key, input, &TxnOpts{})
 	if err != nil {
-		return nil, err
+		return nil, a.decodeRevert(err)
 	}
 	return txn, nil
 }
@@ -304,6 +347,12 @@ func (a *Contract) Txn(method string, args ...interface{}) (Txn, error) {
 type CallOpts struct {
 	Block ethgo.BlockNumber
 	From  ethgo.Address
+
+	// StateOverride replaces the state of some accounts during the call
+	StateOverride ethgo.StateOverride
+
+	// BlockOverrides replaces fields of the block header during the call
+	BlockOverrides *ethgo.BlockOverrides
 }
 
 func (a *Contract) Call(method string, block ethgo.BlockNumber, args ...interface{}) (map[string]interface{}, error) {
@@ -319,6 +368,21 @@ func (a *Contract) Call(method string, block ethgo.BlockNumber, args ...interfac
 	return a.CallByMathodAndData(m, block, data)
 }
 
+// CallWithOpts calls a method of the contract with the given call options.
+// If From is empty, the address of the key of the contract is used.
+func (a *Contract) CallWithOpts(method string, opts *CallOpts, args ...interface{}) (map[string]interface{}, error) {
+	m := a.abi.GetMethod(method)
+	if m == nil {
+		return nil, fmt.Errorf("method %s not found", method)
+	}
+
+	data, err := m.Encode(args)
+	if err != nil {
+		return nil, err
+	}
+	return a.callByMethodAndData(m, opts, data)
+}
+
 func (a *Contract) CallByData(method string, block ethgo.BlockNumber, data []byte) (map[string]interface{}, error) {
 	m := a.abi.GetMethod(method)
 	return a.CallByMathodAndData(m, block, data)
@@ -328,15 +392,21 @@ func (a *Contract) CallByMathodAndData(m *abi.Method, block ethgo.BlockNumber, d
 	if m == nil {
 		return nil, fmt.Errorf("method not found")
 	}
-	opts := &CallOpts{
-		Block: block,
+	return a.callByMethodAndData(m, &CallOpts{Block: block}, data)
+}
+
+func (a *Contract) callByMethodAndData(m *abi.Method, opts *CallOpts, data []byte) (map[string]interface{}, error) {
+	if opts == nil {
+		opts = &CallOpts{Block: ethgo.Latest}
 	}
-	if a.key != nil {
-		opts.From = a.key.Address()
+	if opts.From == ethgo.ZeroAddress && a.key != nil {
+		opts2 := *opts
+		opts2.From = a.key.Address()
+		opts = &opts2
 	}
 	rawOutput, err := a.provider.Call(a.addr, data, opts)
 	if err != nil {
-		return nil, err
+		return nil, a.decodeRevert(err)
 	}
 
 	resp, err := m.Decode(rawOutput)
@@ -386,7 +456,7 @@ func (a *Contract) SendByMethodAndData(m *abi.Method, opts *TxnOpts, input []byt
 	}
 	txn, err := a.provider.Txn(a.addr, key, input, options)
 	if err != nil {
-		return nil, err
+		return nil, a.decodeRevert(err)
 	}
 	err = txn.Do()
 	if err != nil {
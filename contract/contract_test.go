@@ -2,6 +2,7 @@ package contract
 
 import (
 	"encoding/hex"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -164,3 +165,76 @@ func TestContract_Transaction(t *testing.T) {
 		assert.Len(t, receipt.Logs, 1)
 	}
 }
+
+func TestContract_CallStateOverride(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	cc := &testutil.Contract{}
+	cc.AddCallback(func() string {
+		return `function example() public view returns (uint256) {
+			return msg.sender.balance;
+		}`
+	})
+
+	contract, addr := s.DeployContract(cc)
+
+	abi, err := abi.NewABI(contract.Abi)
+	assert.NoError(t, err)
+
+	from := ethgo.Address{0x1}
+	c := NewContract(addr, abi, WithSender(from), WithJsonRPCEndpoint(s.HTTPAddr()))
+
+	resp, err := c.CallWithOpts("example", &CallOpts{
+		Block: ethgo.Latest,
+		StateOverride: ethgo.StateOverride{
+			from: {Balance: big.NewInt(1000)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, resp["0"], big.NewInt(1000))
+}
+
+type revertProvider struct {
+	data []byte
+}
+
+func (r *revertProvider) Call(ethgo.Address, []byte, *CallOpts) ([]byte, error) {
+	return nil, &jsonrpc.RevertError{Data: r.data}
+}
+
+func (r *revertProvider) Txn(ethgo.Address, ethgo.Key, []byte, *TxnOpts) (Txn, error) {
+	return nil, &jsonrpc.RevertError{Data: r.data}
+}
+
+func TestContract_RevertError(t *testing.T) {
+	abi0, err := abi.NewABIFromList([]string{
+		"function withdraw(uint256 amount)",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+	})
+	assert.NoError(t, err)
+
+	args, err := abi0.Errors["InsufficientBalance"].Inputs.Encode([]interface{}{big.NewInt(1), big.NewInt(2)})
+	assert.NoError(t, err)
+	data := append(abi0.Errors["InsufficientBalance"].ID(), args...)
+
+	key, _ := wallet.GenerateKey()
+	c := NewContract(addr0B, abi0, WithProvider(&revertProvider{data: data}), WithSender(key))
+
+	_, err = c.Call("withdraw", ethgo.Latest, big.NewInt(2))
+	var revertErr *RevertError
+	assert.True(t, errors.As(err, &revertErr))
+	assert.Equal(t, "InsufficientBalance", revertErr.Err.Name)
+	assert.Equal(t, data, revertErr.Data)
+	assert.Equal(t, "execution reverted: InsufficientBalance(available: 1, required: 2)", err.Error())
+
+	_, err = c.Txn("withdraw", big.NewInt(2))
+	assert.True(t, errors.As(err, &revertErr))
+	assert.Equal(t, big.NewInt(1), revertErr.Err.Args["available"])
+
+	// the data of an unknown error is not decoded
+	c = NewContract(addr0B, abi0, WithProvider(&revertProvider{data: []byte{0x1, 0x2, 0x3, 0x4}}))
+	_, err = c.Call("withdraw", ethgo.Latest, big.NewInt(2))
+	assert.True(t, errors.As(err, &revertErr))
+	assert.Nil(t, revertErr.Err)
+}
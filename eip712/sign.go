@@ -0,0 +1,38 @@
+package eip712
+
+import (
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/wallet"
+)
+
+// SignTypedData signs the typed data with the key. The signature is in the
+// [R || S || V] format with V being 27 or 28 as in eth_signTypedData_v4.
+func SignTypedData(key ethgo.Key, data *TypedData) ([]byte, error) {
+	hash, err := data.Hash()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := key.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := wallet.ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Bytes27(), nil
+}
+
+// RecoverTypedData returns the address that signed the typed data. The V
+// value of the signature can be either 0 or 1 or 27 or 28.
+func RecoverTypedData(data *TypedData, signature []byte) (ethgo.Address, error) {
+	sig, err := wallet.ParseSignature(signature)
+	if err != nil {
+		return ethgo.Address{}, err
+	}
+	hash, err := data.Hash()
+	if err != nil {
+		return ethgo.Address{}, err
+	}
+	return wallet.Ecrecover(hash, sig.Bytes())
+}
@@ -0,0 +1,389 @@
+package eip712
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+)
+
+// DomainType is the name of the type of the domain
+const DomainType = "EIP712Domain"
+
+// Field is a field of a struct type
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types are the struct types of the typed data by name
+type Types map[string][]*Field
+
+// Domain is the domain of the typed data. The fields not set are
+// not part of the domain separator.
+type Domain struct {
+	Name              string         `json:"name,omitempty"`
+	Version           string         `json:"version,omitempty"`
+	ChainID           *big.Int       `json:"chainId,omitempty"`
+	VerifyingContract *ethgo.Address `json:"verifyingContract,omitempty"`
+	Salt              *ethgo.Hash    `json:"salt,omitempty"`
+}
+
+// UnmarshalJSON implements the json unmarshal interface. The chain id
+// can be either a number or a decimal or hex string.
+func (d *Domain) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Name              string          `json:"name"`
+		Version           string          `json:"version"`
+		ChainID           json.RawMessage `json:"chainId"`
+		VerifyingContract *ethgo.Address  `json:"verifyingContract"`
+		Salt              *ethgo.Hash     `json:"salt"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	d.Name = obj.Name
+	d.Version = obj.Version
+	d.VerifyingContract = obj.VerifyingContract
+	d.Salt = obj.Salt
+	d.ChainID = nil
+
+	if len(obj.ChainID) != 0 && string(obj.ChainID) != "null" {
+		str := string(obj.ChainID)
+		if strings.HasPrefix(str, `"`) {
+			if err := json.Unmarshal(obj.ChainID, &str); err != nil {
+				return err
+			}
+		}
+		chainID, ok := parseBigInt(str)
+		if !ok {
+			return fmt.Errorf("invalid chain id '%s'", str)
+		}
+		d.ChainID = chainID
+	}
+	return nil
+}
+
+// Types returns the fields of the domain type for the fields set in the domain
+func (d *Domain) Types() []*Field {
+	fields := []*Field{}
+	if d.Name != "" {
+		fields = append(fields, &Field{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		fields = append(fields, &Field{Name: "version", Type: "string"})
+	}
+	if d.ChainID != nil {
+		fields = append(fields, &Field{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != nil {
+		fields = append(fields, &Field{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Salt != nil {
+		fields = append(fields, &Field{Name: "salt", Type: "bytes32"})
+	}
+	return fields
+}
+
+// Map returns the fields set in the domain as a message
+func (d *Domain) Map() map[string]interface{} {
+	m := map[string]interface{}{}
+	if d.Name != "" {
+		m["name"] = d.Name
+	}
+	if d.Version != "" {
+		m["version"] = d.Version
+	}
+	if d.ChainID != nil {
+		m["chainId"] = d.ChainID
+	}
+	if d.VerifyingContract != nil {
+		m["verifyingContract"] = *d.VerifyingContract
+	}
+	if d.Salt != nil {
+		m["salt"] = *d.Salt
+	}
+	return m
+}
+
+// TypedData is the structured data of EIP-712 as used by eth_signTypedData_v4
+type TypedData struct {
+	Types       Types                  `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      *Domain                `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// UnmarshalJSON implements the json unmarshal interface. The numbers
+// of the message are decoded without losing precision.
+func (t *TypedData) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Types       Types                  `json:"types"`
+		PrimaryType string                 `json:"primaryType"`
+		Domain      *Domain                `json:"domain"`
+		Message     map[string]interface{} `json:"message"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return err
+	}
+	*t = TypedData(obj)
+	return nil
+}
+
+// ParseTypedData parses the json typed data of eth_signTypedData_v4
+func ParseTypedData(data []byte) (*TypedData, error) {
+	var t *TypedData
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("empty typed data")
+	}
+	return t, nil
+}
+
+// fields returns the fields of a struct type. The domain type
+// is derived from the domain if it is not declared.
+func (t *TypedData) fields(name string) ([]*Field, bool) {
+	fields, ok := t.Types[name]
+	if !ok && name == DomainType && t.Domain != nil {
+		return t.Domain.Types(), true
+	}
+	return fields, ok
+}
+
+// baseType removes the array dimensions of the type
+func baseType(typ string) string {
+	if indx := strings.Index(typ, "["); indx != -1 {
+		return typ[:indx]
+	}
+	return typ
+}
+
+func (t *TypedData) dependencies(name string, deps map[string]struct{}) error {
+	if _, ok := deps[name]; ok {
+		return nil
+	}
+	fields, ok := t.fields(name)
+	if !ok {
+		return fmt.Errorf("type '%s' not found", name)
+	}
+	deps[name] = struct{}{}
+
+	for _, field := range fields {
+		base := baseType(field.Type)
+		if _, ok := t.Types[base]; ok {
+			if err := t.dependencies(base, deps); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EncodeType returns the encoding of the type with the types it references
+// (i.e. 'Mail(Person from,Person to,string contents)Person(string name,address wallet)')
+func (t *TypedData) EncodeType(name string) (string, error) {
+	deps := map[string]struct{}{}
+	if err := t.dependencies(name, deps); err != nil {
+		return "", err
+	}
+	delete(deps, name)
+
+	names := make([]string, 0, len(deps))
+	for dep := range deps {
+		names = append(names, dep)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	for _, typ := range append([]string{name}, names...) {
+		fields, _ := t.fields(typ)
+
+		buf.WriteString(typ)
+		buf.WriteString("(")
+		for i, field := range fields {
+			if i != 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(field.Type)
+			buf.WriteString(" ")
+			buf.WriteString(field.Name)
+		}
+		buf.WriteString(")")
+	}
+	return buf.String(), nil
+}
+
+// TypeHash returns the hash of the encoding of the type
+func (t *TypedData) TypeHash(name string) ([]byte, error) {
+	typ, err := t.EncodeType(name)
+	if err != nil {
+		return nil, err
+	}
+	return ethgo.Keccak256([]byte(typ)), nil
+}
+
+// EncodeData returns the encoding of the values of a struct type
+func (t *TypedData) EncodeData(name string, data map[string]interface{}) ([]byte, error) {
+	typeHash, err := t.TypeHash(name)
+	if err != nil {
+		return nil, err
+	}
+	fields, _ := t.fields(name)
+
+	buf := append([]byte{}, typeHash...)
+	for _, field := range fields {
+		val, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("field '%s' of '%s' not found", field.Name, name)
+		}
+		res, err := t.encodeValue(field.Type, val)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field '%s' of '%s': %v", field.Name, name, err)
+		}
+		buf = append(buf, res...)
+	}
+	return buf, nil
+}
+
+// HashStruct returns the hash of the encoding of the values of a struct type
+func (t *TypedData) HashStruct(name string, data map[string]interface{}) ([]byte, error) {
+	enc, err := t.EncodeData(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return ethgo.Keccak256(enc), nil
+}
+
+// DomainSeparator returns the hash of the domain
+func (t *TypedData) DomainSeparator() ([]byte, error) {
+	domain := t.Domain
+	if domain == nil {
+		domain = &Domain{}
+	}
+	return t.HashStruct(DomainType, domain.Map())
+}
+
+// Hash returns the hash of the typed data that is signed
+func (t *TypedData) Hash() ([]byte, error) {
+	separator, err := t.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+	if t.PrimaryType == "" {
+		return nil, fmt.Errorf("primary type not set")
+	}
+	hash, err := t.HashStruct(t.PrimaryType, t.Message)
+	if err != nil {
+		return nil, err
+	}
+	return ethgo.Keccak256([]byte{0x19, 0x01}, separator, hash), nil
+}
+
+// encodeValue encodes a value as a 32 bytes word. The structs, the arrays
+// and the dynamic types are encoded by their hash.
+func (t *TypedData) encodeValue(typ string, val interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "]") {
+		return t.encodeArray(typ, val)
+	}
+	if _, ok := t.Types[typ]; ok {
+		data, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object for '%s' but found %T", typ, val)
+		}
+		return t.HashStruct(typ, data)
+	}
+
+	abiType, err := abi.NewType(typ)
+	if err != nil {
+		return nil, err
+	}
+	switch abiType.Kind() {
+	case abi.KindString:
+		str, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string but found %T", val)
+		}
+		return ethgo.Keccak256([]byte(str)), nil
+
+	case abi.KindBytes:
+		buf, err := decodeBytes(val)
+		if err != nil {
+			return nil, err
+		}
+		return ethgo.Keccak256(buf), nil
+
+	case abi.KindBool, abi.KindAddress, abi.KindUInt, abi.KindInt, abi.KindFixedBytes:
+		return abi.Encode(val, abiType)
+
+	default:
+		return nil, fmt.Errorf("type '%s' not supported", typ)
+	}
+}
+
+// encodeArray encodes an array as the hash of the encodings of its elements
+func (t *TypedData) encodeArray(typ string, val interface{}) ([]byte, error) {
+	indx := strings.LastIndex(typ, "[")
+	if indx == -1 {
+		return nil, fmt.Errorf("invalid array type '%s'", typ)
+	}
+	elemType := typ[:indx]
+
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected an array for '%s' but found %T", typ, val)
+	}
+	if size := typ[indx+1 : len(typ)-1]; size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array type '%s'", typ)
+		}
+		if v.Len() != n {
+			return nil, fmt.Errorf("expected %d elements for '%s' but found %d", n, typ, v.Len())
+		}
+	}
+
+	buf := []byte{}
+	for i := 0; i < v.Len(); i++ {
+		res, err := t.encodeValue(elemType, v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, res...)
+	}
+	return ethgo.Keccak256(buf), nil
+}
+
+func decodeBytes(val interface{}) ([]byte, error) {
+	switch obj := val.(type) {
+	case []byte:
+		return obj, nil
+	case string:
+		if !strings.HasPrefix(obj, "0x") {
+			return nil, fmt.Errorf("expected a hex string but found '%s'", obj)
+		}
+		return hex.DecodeString(obj[2:])
+	default:
+		return nil, fmt.Errorf("expected bytes but found %T", val)
+	}
+}
+
+// parseBigInt parses a decimal or a hex number
+func parseBigInt(str string) (*big.Int, bool) {
+	if strings.HasPrefix(str, "0x") {
+		return new(big.Int).SetString(str[2:], 16)
+	}
+	return new(big.Int).SetString(str, 10)
+}
@@ -0,0 +1,157 @@
+package eip712
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/wallet"
+)
+
+// mailTypedData is the example of the EIP-712 specification
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func decodeHex(t *testing.T, str string) []byte {
+	buf, err := hex.DecodeString(str)
+	assert.NoError(t, err)
+	return buf
+}
+
+func TestTypedData_Mail(t *testing.T) {
+	data, err := ParseTypedData([]byte(mailTypedData))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), data.Domain.ChainID)
+
+	typ, err := data.EncodeType("Mail")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", typ)
+
+	typeHash, err := data.TypeHash("Mail")
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHex(t, "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"), typeHash)
+
+	separator, err := data.DomainSeparator()
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHex(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"), separator)
+
+	hash, err := data.HashStruct("Mail", data.Message)
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHex(t, "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"), hash)
+
+	hash, err = data.Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHex(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"), hash)
+
+	// the domain type is derived from the domain if it is not declared
+	delete(data.Types, DomainType)
+	separator2, err := data.DomainSeparator()
+	assert.NoError(t, err)
+	assert.Equal(t, separator, separator2)
+}
+
+func TestTypedData_Sign(t *testing.T) {
+	data, err := ParseTypedData([]byte(mailTypedData))
+	assert.NoError(t, err)
+
+	key, err := wallet.NewWalletFromPrivKey(ethgo.Keccak256([]byte("cow")))
+	assert.NoError(t, err)
+	assert.Equal(t, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", key.Address().String())
+
+	sig, err := SignTypedData(key, data)
+	assert.NoError(t, err)
+	assert.Equal(t, decodeHex(t, "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d"), sig[:32])
+	assert.Equal(t, decodeHex(t, "07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"), sig[32:64])
+	assert.Equal(t, byte(28), sig[64])
+
+	addr, err := RecoverTypedData(data, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Address(), addr)
+
+	// modified data recovers a different address
+	data.Message["contents"] = "Hello, Alice!"
+	addr, err = RecoverTypedData(data, sig)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.Address(), addr)
+}
+
+func TestTypedData_Arrays(t *testing.T) {
+	data := &TypedData{
+		Types: Types{
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallets", Type: "address[]"},
+			},
+			"Group": {
+				{Name: "members", Type: "Person[]"},
+				{Name: "data", Type: "bytes"},
+				{Name: "ids", Type: "uint256[2]"},
+			},
+		},
+		PrimaryType: "Group",
+		Domain: &Domain{
+			Name:    "Groups",
+			ChainID: big.NewInt(1),
+		},
+		Message: map[string]interface{}{
+			"members": []interface{}{
+				map[string]interface{}{
+					"name":    "Cow",
+					"wallets": []interface{}{"0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+				},
+			},
+			"data": "0x0102",
+			"ids":  []interface{}{1, big.NewInt(2)},
+		},
+	}
+
+	typ, err := data.EncodeType("Group")
+	assert.NoError(t, err)
+	assert.Equal(t, "Group(Person[] members,bytes data,uint256[2] ids)Person(string name,address[] wallets)", typ)
+
+	enc, err := data.EncodeData("Group", data.Message)
+	assert.NoError(t, err)
+	assert.Len(t, enc, 4*32)
+	assert.Equal(t, ethgo.Keccak256([]byte{0x1, 0x2}), enc[64:96])
+
+	// the fixed arrays have to match the size
+	data.Message["ids"] = []interface{}{1}
+	_, err = data.Hash()
+	assert.Error(t, err)
+
+	// missing fields
+	delete(data.Message, "ids")
+	_, err = data.Hash()
+	assert.Error(t, err)
+}
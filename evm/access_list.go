@@ -0,0 +1,59 @@
+package evm
+
+import (
+	"github.com/umbracle/ethgo"
+)
+
+// accessList is the set of warm accounts and storage slots of EIP-2929
+type accessList struct {
+	addresses map[ethgo.Address]map[ethgo.Hash]struct{}
+}
+
+func newAccessList() *accessList {
+	return &accessList{
+		addresses: map[ethgo.Address]map[ethgo.Hash]struct{}{},
+	}
+}
+
+func (a *accessList) containsAddress(addr ethgo.Address) bool {
+	_, ok := a.addresses[addr]
+	return ok
+}
+
+func (a *accessList) containsSlot(addr ethgo.Address, slot ethgo.Hash) bool {
+	slots, ok := a.addresses[addr]
+	if !ok {
+		return false
+	}
+	_, ok = slots[slot]
+	return ok
+}
+
+func (a *accessList) addAddress(addr ethgo.Address) {
+	if _, ok := a.addresses[addr]; !ok {
+		a.addresses[addr] = map[ethgo.Hash]struct{}{}
+	}
+}
+
+// addSlot adds the slot and returns true if the address was also added
+func (a *accessList) addSlot(addr ethgo.Address, slot ethgo.Hash) bool {
+	slots, ok := a.addresses[addr]
+	if !ok {
+		slots = map[ethgo.Hash]struct{}{}
+		a.addresses[addr] = slots
+	}
+	slots[slot] = struct{}{}
+	return !ok
+}
+
+func (a *accessList) deleteAddress(addr ethgo.Address) {
+	delete(a.addresses, addr)
+}
+
+func (a *accessList) deleteSlot(addr ethgo.Address, slot ethgo.Hash, addrAdded bool) {
+	if addrAdded {
+		delete(a.addresses, addr)
+		return
+	}
+	delete(a.addresses[addr], slot)
+}
@@ -0,0 +1,504 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/fastrlp"
+)
+
+// limits of the execution
+const (
+	callDepthLimit    = 1024
+	maxCodeSize       = 24576
+	maxInitCodeSize   = 2 * maxCodeSize
+	callStipend       = 2300
+	maxRefundQuotient = 5
+	createDataGas     = 200
+)
+
+// Errors of the execution. Any error besides ErrExecutionReverted
+// consumes all the gas of the call.
+var (
+	ErrOutOfGas                 = errors.New("out of gas")
+	ErrCodeStoreOutOfGas        = errors.New("contract creation code storage out of gas")
+	ErrDepth                    = errors.New("max call depth exceeded")
+	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
+	ErrContractAddressCollision = errors.New("contract address collision")
+	ErrExecutionReverted        = errors.New("execution reverted")
+	ErrMaxCodeSizeExceeded      = errors.New("max code size exceeded")
+	ErrMaxInitCodeSizeExceeded  = errors.New("max initcode size exceeded")
+	ErrInvalidJump              = errors.New("invalid jump destination")
+	ErrWriteProtection          = errors.New("write protection")
+	ErrReturnDataOutOfBounds    = errors.New("return data out of bounds")
+	ErrGasUintOverflow          = errors.New("gas uint64 overflow")
+	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
+	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
+	ErrStackUnderflow           = errors.New("stack underflow")
+	ErrStackOverflow            = errors.New("stack limit reached")
+	ErrRefundUnderflow          = errors.New("refund counter below zero")
+
+	// errStopToken stops the execution without an error
+	errStopToken = errors.New("stop token")
+)
+
+// ErrInvalidOpCode happens when the code has an undefined instruction
+type ErrInvalidOpCode struct {
+	OpCode OpCode
+}
+
+func (e *ErrInvalidOpCode) Error() string {
+	return fmt.Sprintf("invalid opcode: %s", e.OpCode)
+}
+
+// State is the world state on which the code is executed. The
+// accounts are created when they are modified for the first time.
+type State interface {
+	Empty(addr ethgo.Address) bool
+	GetBalance(addr ethgo.Address) *big.Int
+	AddBalance(addr ethgo.Address, amount *big.Int)
+	SubBalance(addr ethgo.Address, amount *big.Int)
+	GetNonce(addr ethgo.Address) uint64
+	SetNonce(addr ethgo.Address, nonce uint64)
+	GetCode(addr ethgo.Address) []byte
+	SetCode(addr ethgo.Address, code []byte)
+	GetState(addr ethgo.Address, key ethgo.Hash) ethgo.Hash
+	SetState(addr ethgo.Address, key, value ethgo.Hash)
+	DeleteAccount(addr ethgo.Address)
+	Snapshot() int
+	RevertToSnapshot(id int)
+}
+
+// BlockContext is the block in which the code is executed
+type BlockContext struct {
+	Coinbase   ethgo.Address
+	GasLimit   uint64
+	Number     uint64
+	Timestamp  uint64
+	Difficulty *big.Int
+	BaseFee    *big.Int
+	ChainID    *big.Int
+
+	// Random is the value of PREVRANDAO. The difficulty is used if it is not set.
+	Random *ethgo.Hash
+
+	// GetHash returns the hash of a previous block
+	GetHash func(num uint64) ethgo.Hash
+}
+
+// TxContext is the transaction in which the code is executed
+type TxContext struct {
+	Origin   ethgo.Address
+	GasPrice *big.Int
+}
+
+// Config is the configuration of the evm
+type Config struct {
+	// Tracer is called on every step of the execution
+	Tracer Tracer
+}
+
+// EVM executes the code of the contracts on top of a state. An EVM
+// instance executes a single transaction and must not be reused.
+type EVM struct {
+	Block *BlockContext
+	Tx    *TxContext
+	State State
+
+	config *Config
+	depth  int
+
+	// readOnly is true during an static call
+	readOnly bool
+
+	// callGasTemp is the gas of the next call computed by the dynamic gas
+	callGasTemp uint64
+
+	accessList *accessList
+	refund     uint64
+	logs       []*ethgo.Log
+
+	// originals are the values of the storage slots before the transaction
+	originals map[ethgo.Address]map[ethgo.Hash]ethgo.Hash
+
+	// destructs are the accounts removed at the end of the transaction
+	destructs map[ethgo.Address]struct{}
+
+	// journal reverts the changes of the evm that are not part of the state
+	journal []func()
+}
+
+// NewEVM creates an evm to execute a transaction on top of the state
+func NewEVM(block *BlockContext, tx *TxContext, state State, config *Config) *EVM {
+	if config == nil {
+		config = &Config{}
+	}
+	if tx == nil {
+		tx = &TxContext{}
+	}
+	if tx.GasPrice == nil {
+		tx.GasPrice = new(big.Int)
+	}
+	return &EVM{
+		Block:      block,
+		Tx:         tx,
+		State:      state,
+		config:     config,
+		accessList: newAccessList(),
+		originals:  map[ethgo.Address]map[ethgo.Hash]ethgo.Hash{},
+		destructs:  map[ethgo.Address]struct{}{},
+	}
+}
+
+type snapshot struct {
+	state   int
+	journal int
+}
+
+func (e *EVM) snapshot() snapshot {
+	return snapshot{state: e.State.Snapshot(), journal: len(e.journal)}
+}
+
+func (e *EVM) revertToSnapshot(s snapshot) {
+	for i := len(e.journal) - 1; i >= s.journal; i-- {
+		e.journal[i]()
+	}
+	e.journal = e.journal[:s.journal]
+	e.State.RevertToSnapshot(s.state)
+}
+
+func (e *EVM) addRefund(gas uint64) {
+	prev := e.refund
+	e.refund += gas
+	e.journal = append(e.journal, func() {
+		e.refund = prev
+	})
+}
+
+func (e *EVM) subRefund(gas uint64) error {
+	prev := e.refund
+	if gas > e.refund {
+		return fmt.Errorf("%w (gas: %d > refund: %d)", ErrRefundUnderflow, gas, e.refund)
+	}
+	e.refund -= gas
+	e.journal = append(e.journal, func() {
+		e.refund = prev
+	})
+	return nil
+}
+
+func (e *EVM) addLog(log *ethgo.Log) {
+	e.logs = append(e.logs, log)
+	e.journal = append(e.journal, func() {
+		e.logs = e.logs[:len(e.logs)-1]
+	})
+}
+
+func (e *EVM) destruct(addr ethgo.Address) {
+	if _, ok := e.destructs[addr]; ok {
+		return
+	}
+	e.destructs[addr] = struct{}{}
+	e.journal = append(e.journal, func() {
+		delete(e.destructs, addr)
+	})
+}
+
+func (e *EVM) warmAddress(addr ethgo.Address) bool {
+	if e.accessList.containsAddress(addr) {
+		return true
+	}
+	e.accessList.addAddress(addr)
+	e.journal = append(e.journal, func() {
+		e.accessList.deleteAddress(addr)
+	})
+	return false
+}
+
+func (e *EVM) warmSlot(addr ethgo.Address, slot ethgo.Hash) bool {
+	if e.accessList.containsSlot(addr, slot) {
+		return true
+	}
+	addrAdded := e.accessList.addSlot(addr, slot)
+	e.journal = append(e.journal, func() {
+		e.accessList.deleteSlot(addr, slot, addrAdded)
+	})
+	return false
+}
+
+// original returns the value of the slot before the transaction
+func (e *EVM) original(addr ethgo.Address, slot ethgo.Hash) ethgo.Hash {
+	slots, ok := e.originals[addr]
+	if !ok {
+		slots = map[ethgo.Hash]ethgo.Hash{}
+		e.originals[addr] = slots
+	}
+	val, ok := slots[slot]
+	if !ok {
+		val = e.State.GetState(addr, slot)
+		slots[slot] = val
+	}
+	return val
+}
+
+func (e *EVM) canTransfer(addr ethgo.Address, value *big.Int) bool {
+	return value.Sign() == 0 || e.State.GetBalance(addr).Cmp(value) >= 0
+}
+
+func (e *EVM) transfer(from, to ethgo.Address, value *big.Int) {
+	if value.Sign() == 0 {
+		return
+	}
+	e.State.SubBalance(from, value)
+	e.State.AddBalance(to, value)
+}
+
+// Call executes the code of the address with the input. The value is
+// transferred from the caller to the address.
+func (e *EVM) Call(caller, addr ethgo.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error) {
+	return e.call(CALL, caller, addr, addr, input, gas, value, value)
+}
+
+// CallCode executes the code of the address in the context of the caller
+func (e *EVM) CallCode(caller, addr ethgo.Address, input []byte, gas uint64, value *big.Int) ([]byte, uint64, error) {
+	return e.call(CALLCODE, caller, caller, addr, input, gas, value, value)
+}
+
+// DelegateCall executes the code of the address in the context of the contract.
+// The caller and the value are the ones of the contract.
+func (e *EVM) DelegateCall(contract *Contract, addr ethgo.Address, input []byte, gas uint64) ([]byte, uint64, error) {
+	return e.call(DELEGATECALL, contract.Caller, contract.Address, addr, input, gas, contract.Value, nil)
+}
+
+// StaticCall executes the code of the address without modifications of the state
+func (e *EVM) StaticCall(caller, addr ethgo.Address, input []byte, gas uint64) ([]byte, uint64, error) {
+	return e.call(STATICCALL, caller, addr, addr, input, gas, new(big.Int), nil)
+}
+
+// call runs the code of codeAddr in the context of addr. The transfer is
+// the value moved from the caller to the address (nil for no transfer).
+func (e *EVM) call(typ OpCode, caller, addr, codeAddr ethgo.Address, input []byte, gas uint64, value, transfer *big.Int) ([]byte, uint64, error) {
+	if e.depth > callDepthLimit {
+		return nil, gas, ErrDepth
+	}
+	if transfer != nil && !e.canTransfer(caller, transfer) {
+		return nil, gas, ErrInsufficientBalance
+	}
+
+	snapshot := e.snapshot()
+	if typ == CALL {
+		e.transfer(caller, addr, transfer)
+	}
+
+	var ret []byte
+	var err error
+	if p, ok := precompiles[codeAddr]; ok {
+		ret, gas, err = runPrecompile(p, input, gas)
+	} else if code := e.State.GetCode(codeAddr); len(code) != 0 {
+		contract := newContract(caller, addr, codeAddr, code, value, gas)
+		ret, err = e.run(contract, input, typ == STATICCALL)
+		gas = contract.Gas
+	}
+
+	if err != nil {
+		e.revertToSnapshot(snapshot)
+		if err != ErrExecutionReverted {
+			gas = 0
+			ret = nil
+		}
+	}
+	return ret, gas, err
+}
+
+// Create creates a contract with the code. The address of the contract
+// depends on the address and the nonce of the caller.
+func (e *EVM) Create(caller ethgo.Address, code []byte, gas uint64, value *big.Int) ([]byte, ethgo.Address, uint64, error) {
+	addr := CreateAddress(caller, e.State.GetNonce(caller))
+	return e.create(caller, code, gas, value, addr, true)
+}
+
+// Create2 creates a contract with the code. The address of the contract
+// depends on the address of the caller, the salt and the code.
+func (e *EVM) Create2(caller ethgo.Address, code []byte, gas uint64, value *big.Int, salt ethgo.Hash) ([]byte, ethgo.Address, uint64, error) {
+	addr := CreateAddress2(caller, salt, code)
+	return e.create(caller, code, gas, value, addr, true)
+}
+
+func (e *EVM) create(caller ethgo.Address, code []byte, gas uint64, value *big.Int, addr ethgo.Address, incNonce bool) ([]byte, ethgo.Address, uint64, error) {
+	if e.depth > callDepthLimit {
+		return nil, ethgo.Address{}, gas, ErrDepth
+	}
+	if !e.canTransfer(caller, value) {
+		return nil, ethgo.Address{}, gas, ErrInsufficientBalance
+	}
+	if incNonce {
+		nonce := e.State.GetNonce(caller)
+		if nonce+1 < nonce {
+			return nil, ethgo.Address{}, gas, ErrNonceUintOverflow
+		}
+		e.State.SetNonce(caller, nonce+1)
+	}
+
+	// the address is warm even if the creation fails
+	e.warmAddress(addr)
+
+	if e.State.GetNonce(addr) != 0 || len(e.State.GetCode(addr)) != 0 {
+		return nil, ethgo.Address{}, 0, ErrContractAddressCollision
+	}
+
+	snapshot := e.snapshot()
+	e.State.SetNonce(addr, 1)
+	e.transfer(caller, addr, value)
+
+	contract := newContract(caller, addr, addr, code, value, gas)
+	ret, err := e.run(contract, nil, false)
+
+	if err == nil && len(ret) > maxCodeSize {
+		err = ErrMaxCodeSizeExceeded
+	}
+	if err == nil && len(ret) != 0 && ret[0] == 0xef {
+		err = ErrInvalidCode
+	}
+	if err == nil {
+		if contract.useGas(uint64(len(ret)) * createDataGas) {
+			e.State.SetCode(addr, ret)
+		} else {
+			err = ErrCodeStoreOutOfGas
+		}
+	}
+
+	if err != nil {
+		e.revertToSnapshot(snapshot)
+		if err != ErrExecutionReverted {
+			contract.Gas = 0
+		}
+	}
+	return ret, addr, contract.Gas, err
+}
+
+// Message is a transaction or a call executed with ApplyMessage
+type Message struct {
+	From  ethgo.Address
+	To    *ethgo.Address
+	Nonce uint64
+	Value *big.Int
+	Data  []byte
+
+	// AccessList are the accounts and slots of EIP-2930 that are warm
+	// from the start of the execution
+	AccessList ethgo.AccessList
+
+	// Gas is the gas available for the execution without the
+	// intrinsic gas of the transaction
+	Gas uint64
+
+	// IntrinsicGas is the gas paid by the transaction before the
+	// execution. It counts for the cap of the refund of EIP-3529.
+	IntrinsicGas uint64
+}
+
+// ExecutionResult is the result of ApplyMessage
+type ExecutionResult struct {
+	// ReturnData is the output of the call or the revert data
+	ReturnData []byte
+
+	// GasLeft is the unused gas including the refund
+	GasLeft uint64
+
+	// Refund is the gas refunded by the storage changes
+	Refund uint64
+
+	// Logs are the logs emitted by the execution
+	Logs []*ethgo.Log
+
+	// ContractAddress is the address of the created contract
+	ContractAddress ethgo.Address
+
+	// Err is the failure of the execution
+	Err error
+}
+
+// ApplyMessage executes the message of a transaction. The caller is in charge
+// of the nonce and the gas payment of the sender. The contract address of a
+// creation is derived from the nonce of the message.
+func (e *EVM) ApplyMessage(msg *Message) *ExecutionResult {
+	value := msg.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	// access list of EIP-2929 and EIP-3651
+	e.accessList.addAddress(msg.From)
+	e.accessList.addAddress(e.Block.Coinbase)
+	for addr := range precompiles {
+		e.accessList.addAddress(addr)
+	}
+	for _, entry := range msg.AccessList {
+		e.accessList.addAddress(entry.Address)
+		for _, slot := range entry.Storage {
+			e.accessList.addSlot(entry.Address, slot)
+		}
+	}
+
+	if tracer := e.config.Tracer; tracer != nil {
+		to := ethgo.Address{}
+		if msg.To != nil {
+			to = *msg.To
+		}
+		tracer.CaptureStart(e, msg.From, to, msg.To == nil, msg.Data, msg.Gas, value)
+	}
+
+	res := &ExecutionResult{}
+	var gasLeft uint64
+	if msg.To == nil {
+		if len(msg.Data) > maxInitCodeSize {
+			res.Err = ErrMaxInitCodeSizeExceeded
+		} else {
+			res.ContractAddress = CreateAddress(msg.From, msg.Nonce)
+			res.ReturnData, _, gasLeft, res.Err = e.create(msg.From, msg.Data, msg.Gas, value, res.ContractAddress, false)
+		}
+	} else {
+		e.accessList.addAddress(*msg.To)
+		res.ReturnData, gasLeft, res.Err = e.Call(msg.From, *msg.To, msg.Data, msg.Gas, value)
+	}
+
+	// the refund is capped to a fifth of the gas used by the whole
+	// transaction, intrinsic gas included
+	res.Refund = e.refund
+	if max := (msg.IntrinsicGas + msg.Gas - gasLeft) / maxRefundQuotient; res.Refund > max {
+		res.Refund = max
+	}
+	res.GasLeft = gasLeft + res.Refund
+
+	if res.Err == nil {
+		res.Logs = e.logs
+	}
+	for addr := range e.destructs {
+		e.State.DeleteAccount(addr)
+	}
+
+	if tracer := e.config.Tracer; tracer != nil {
+		tracer.CaptureEnd(res.ReturnData, msg.Gas-res.GasLeft, res.Err)
+	}
+	return res
+}
+
+// CreateAddress returns the address of a contract created with CREATE
+func CreateAddress(addr ethgo.Address, nonce uint64) ethgo.Address {
+	a := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(a)
+
+	v := a.NewArray()
+	v.Set(a.NewCopyBytes(addr[:]))
+	v.Set(a.NewUint(nonce))
+
+	return ethgo.BytesToAddress(ethgo.Keccak256(v.MarshalTo(nil))[12:])
+}
+
+// CreateAddress2 returns the address of a contract created with CREATE2
+func CreateAddress2(addr ethgo.Address, salt ethgo.Hash, code []byte) ethgo.Address {
+	hash := ethgo.Keccak256([]byte{0xff}, addr[:], salt[:], ethgo.Keccak256(code))
+	return ethgo.BytesToAddress(hash[12:])
+}
@@ -0,0 +1,443 @@
+package evm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/wallet"
+)
+
+type testAccount struct {
+	nonce   uint64
+	balance *big.Int
+	code    []byte
+	storage map[ethgo.Hash]ethgo.Hash
+}
+
+// testState is an in-memory state for the tests
+type testState struct {
+	accounts map[ethgo.Address]*testAccount
+	journal  []func()
+}
+
+func newTestState() *testState {
+	return &testState{accounts: map[ethgo.Address]*testAccount{}}
+}
+
+func (s *testState) account(addr ethgo.Address) *testAccount {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		acct = &testAccount{balance: new(big.Int), storage: map[ethgo.Hash]ethgo.Hash{}}
+		s.accounts[addr] = acct
+		s.journal = append(s.journal, func() { delete(s.accounts, addr) })
+	}
+	return acct
+}
+
+func (s *testState) Empty(addr ethgo.Address) bool {
+	acct := s.account(addr)
+	return acct.nonce == 0 && acct.balance.Sign() == 0 && len(acct.code) == 0
+}
+
+func (s *testState) GetBalance(addr ethgo.Address) *big.Int {
+	return new(big.Int).Set(s.account(addr).balance)
+}
+
+func (s *testState) setBalance(addr ethgo.Address, balance *big.Int) {
+	acct := s.account(addr)
+	prev := acct.balance
+	acct.balance = balance
+	s.journal = append(s.journal, func() { acct.balance = prev })
+}
+
+func (s *testState) AddBalance(addr ethgo.Address, amount *big.Int) {
+	s.setBalance(addr, new(big.Int).Add(s.GetBalance(addr), amount))
+}
+
+func (s *testState) SubBalance(addr ethgo.Address, amount *big.Int) {
+	s.setBalance(addr, new(big.Int).Sub(s.GetBalance(addr), amount))
+}
+
+func (s *testState) GetNonce(addr ethgo.Address) uint64 {
+	return s.account(addr).nonce
+}
+
+func (s *testState) SetNonce(addr ethgo.Address, nonce uint64) {
+	acct := s.account(addr)
+	prev := acct.nonce
+	acct.nonce = nonce
+	s.journal = append(s.journal, func() { acct.nonce = prev })
+}
+
+func (s *testState) GetCode(addr ethgo.Address) []byte {
+	return s.account(addr).code
+}
+
+func (s *testState) SetCode(addr ethgo.Address, code []byte) {
+	acct := s.account(addr)
+	prev := acct.code
+	acct.code = code
+	s.journal = append(s.journal, func() { acct.code = prev })
+}
+
+func (s *testState) GetState(addr ethgo.Address, key ethgo.Hash) ethgo.Hash {
+	return s.account(addr).storage[key]
+}
+
+func (s *testState) SetState(addr ethgo.Address, key, value ethgo.Hash) {
+	acct := s.account(addr)
+	prev := acct.storage[key]
+	acct.storage[key] = value
+	s.journal = append(s.journal, func() { acct.storage[key] = prev })
+}
+
+func (s *testState) DeleteAccount(addr ethgo.Address) {
+	prev := s.account(addr)
+	delete(s.accounts, addr)
+	s.journal = append(s.journal, func() { s.accounts[addr] = prev })
+}
+
+func (s *testState) Snapshot() int {
+	return len(s.journal)
+}
+
+func (s *testState) RevertToSnapshot(id int) {
+	for i := len(s.journal) - 1; i >= id; i-- {
+		s.journal[i]()
+	}
+	s.journal = s.journal[:id]
+}
+
+var (
+	testSender   = ethgo.Address{0x1}
+	testContract = ethgo.Address{0x2}
+)
+
+func newTestEVM(state State, config *Config) *EVM {
+	block := &BlockContext{
+		Number:   10,
+		GasLimit: 30000000,
+		ChainID:  big.NewInt(1337),
+	}
+	return NewEVM(block, &TxContext{Origin: testSender}, state, config)
+}
+
+// deployCode returns the init code that deploys the runtime code
+func deployCode(runtime []byte) []byte {
+	code := []byte{byte(PUSH1) + byte(len(runtime)-1)}
+	code = append(code, runtime...)
+	return append(code,
+		byte(PUSH1), 0x0, byte(MSTORE),
+		byte(PUSH1), byte(len(runtime)), byte(PUSH1), byte(32-len(runtime)), byte(RETURN),
+	)
+}
+
+func TestEVM_Arithmetic(t *testing.T) {
+	cases := []struct {
+		code   []byte
+		output string
+	}{
+		{
+			// 2 + 3
+			[]byte{byte(PUSH1), 0x3, byte(PUSH1), 0x2, byte(ADD)},
+			"0000000000000000000000000000000000000000000000000000000000000005",
+		},
+		{
+			// 0 - 1 wraps around
+			[]byte{byte(PUSH1), 0x1, byte(PUSH0), byte(SUB)},
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		},
+		{
+			// -4 / 2
+			[]byte{byte(PUSH1), 0x2, byte(PUSH1), 0x4, byte(PUSH0), byte(SUB), byte(SDIV)},
+			"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe",
+		},
+		{
+			// -1 >> 4 (arithmetic)
+			[]byte{byte(PUSH1), 0x1, byte(PUSH0), byte(SUB), byte(PUSH1), 0x4, byte(SAR)},
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		},
+		{
+			// signextend(0, 0xff)
+			[]byte{byte(PUSH1), 0xff, byte(PUSH0), byte(SIGNEXTEND)},
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		},
+		{
+			// 2 ** 10
+			[]byte{byte(PUSH1), 0xa, byte(PUSH1), 0x2, byte(EXP)},
+			"0000000000000000000000000000000000000000000000000000000000000400",
+		},
+		{
+			// (5 + 4) % 7
+			[]byte{byte(PUSH1), 0x7, byte(PUSH1), 0x4, byte(PUSH1), 0x5, byte(ADDMOD)},
+			"0000000000000000000000000000000000000000000000000000000000000002",
+		},
+	}
+
+	for _, c := range cases {
+		// return the value at the top of the stack
+		code := append(c.code, byte(PUSH0), byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH0), byte(RETURN))
+
+		state := newTestState()
+		state.SetCode(testContract, code)
+
+		res := newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+		assert.NoError(t, res.Err)
+		assert.Equal(t, c.output, hex.EncodeToString(res.ReturnData))
+	}
+}
+
+func TestEVM_Gas(t *testing.T) {
+	state := newTestState()
+
+	// PUSH1 1 PUSH0 SSTORE
+	state.SetCode(testContract, []byte{byte(PUSH1), 0x1, byte(PUSH0), byte(SSTORE)})
+
+	res := newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, uint64(100000-3-2-2100-20000), res.GasLeft)
+	assert.Equal(t, ethgo.BytesToHash([]byte{0x1}), state.GetState(testContract, ethgo.Hash{}))
+
+	// clearing the slot refunds part of the gas
+	state.SetCode(testContract, []byte{byte(PUSH0), byte(PUSH0), byte(SSTORE)})
+
+	res = newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.NoError(t, res.Err)
+	used := uint64(2 + 2 + 2100 + 2900)
+	assert.Equal(t, used/5, res.Refund)
+	assert.Equal(t, 100000-used+used/5, res.GasLeft)
+
+	// the call runs out of gas and consumes all of it
+	res = newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 2000})
+	assert.Equal(t, ErrOutOfGas, res.Err)
+	assert.Equal(t, uint64(0), res.GasLeft)
+}
+
+func TestEVM_RefundCap(t *testing.T) {
+	clear := func(slots int) *ExecutionResult {
+		state := newTestState()
+
+		// PUSH1 0 PUSH1 slot SSTORE for every slot set to 1
+		code := []byte{}
+		for i := 0; i < slots; i++ {
+			state.SetState(testContract, ethgo.BytesToHash([]byte{byte(i)}), ethgo.BytesToHash([]byte{0x1}))
+			code = append(code, byte(PUSH1), 0x0, byte(PUSH1), byte(i), byte(SSTORE))
+		}
+		state.SetCode(testContract, code)
+
+		msg := &Message{From: testSender, To: &testContract, Gas: 100000 - 21000, IntrinsicGas: 21000}
+		res := newTestEVM(state, nil).ApplyMessage(msg)
+		assert.NoError(t, res.Err)
+		return res
+	}
+
+	// the gas used by geth for a transaction that clears a slot is
+	// 21000 + 5006 - 4800, the refund is below the cap
+	res := clear(1)
+	assert.Equal(t, uint64(4800), res.Refund)
+	assert.Equal(t, uint64(21206), 100000-res.GasLeft)
+
+	// and for one that clears two slots is 21000 + 10012 - 31012/5,
+	// the cap includes the intrinsic gas
+	res = clear(2)
+	assert.Equal(t, uint64(31012/5), res.Refund)
+	assert.Equal(t, uint64(24810), 100000-res.GasLeft)
+}
+
+func TestEVM_SubRefund(t *testing.T) {
+	e := newTestEVM(newTestState(), nil)
+	e.addRefund(10)
+
+	assert.NoError(t, e.subRefund(5))
+	assert.True(t, errors.Is(e.subRefund(10), ErrRefundUnderflow))
+	assert.Equal(t, uint64(5), e.refund)
+}
+
+func TestEVM_Revert(t *testing.T) {
+	state := newTestState()
+
+	// SSTORE(0, 1) and REVERT with 0xaa
+	state.SetCode(testContract, []byte{
+		byte(PUSH1), 0x1, byte(PUSH0), byte(SSTORE),
+		byte(PUSH1), 0xaa, byte(PUSH0), byte(MSTORE8),
+		byte(PUSH1), 0x1, byte(PUSH0), byte(REVERT),
+	})
+
+	res := newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.Equal(t, ErrExecutionReverted, res.Err)
+	assert.Equal(t, []byte{0xaa}, res.ReturnData)
+	assert.NotZero(t, res.GasLeft)
+	assert.Equal(t, ethgo.Hash{}, state.GetState(testContract, ethgo.Hash{}))
+
+	// invalid opcode
+	state.SetCode(testContract, []byte{byte(INVALID)})
+
+	res = newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.Error(t, res.Err)
+	assert.Equal(t, uint64(0), res.GasLeft)
+
+	// invalid jump
+	state.SetCode(testContract, []byte{byte(PUSH1), 0x1, byte(JUMP)})
+
+	res = newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.Equal(t, ErrInvalidJump, res.Err)
+}
+
+func TestEVM_CreateAndCall(t *testing.T) {
+	state := newTestState()
+	state.AddBalance(testSender, big.NewInt(100))
+
+	// the contract emits a log with the caller and returns the input
+	runtime := []byte{
+		byte(CALLER), byte(PUSH0), byte(PUSH0), byte(LOG1),
+		byte(CALLDATASIZE), byte(PUSH0), byte(PUSH0), byte(CALLDATACOPY),
+		byte(CALLDATASIZE), byte(PUSH0), byte(RETURN),
+	}
+
+	res := newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, Data: deployCode(runtime), Gas: 100000, Value: big.NewInt(10)})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, CreateAddress(testSender, 0), res.ContractAddress)
+
+	addr := res.ContractAddress
+	assert.Equal(t, runtime, state.GetCode(addr))
+	assert.Equal(t, uint64(1), state.GetNonce(addr))
+	assert.Equal(t, big.NewInt(10), state.GetBalance(addr))
+
+	res = newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &addr, Data: []byte{0x1, 0x2}, Gas: 100000})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []byte{0x1, 0x2}, res.ReturnData)
+	assert.Len(t, res.Logs, 1)
+	assert.Equal(t, addr, res.Logs[0].Address)
+	assert.Equal(t, ethgo.BytesToHash(testSender[:]), res.Logs[0].Topics[0])
+
+	// a contract that calls the deployed one with STATICCALL and returns the status
+	caller := []byte{
+		byte(PUSH1), 0x20, byte(PUSH0), byte(PUSH0), byte(PUSH0),
+		byte(PUSH20),
+	}
+	caller = append(caller, addr[:]...)
+	caller = append(caller, byte(GAS), byte(STATICCALL), byte(PUSH0), byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH0), byte(RETURN))
+	state.SetCode(testContract, caller)
+
+	// the log is a modification of the state in a static call
+	res = newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, make([]byte, 32), res.ReturnData)
+	assert.Len(t, res.Logs, 0)
+}
+
+func TestEVM_Create2(t *testing.T) {
+	// example 0 of EIP-1014
+	addr := CreateAddress2(ethgo.Address{}, ethgo.Hash{}, []byte{0x0})
+	assert.Equal(t, "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38", addr.String())
+
+	addr = CreateAddress(ethgo.HexToAddress("0x970e8128ab834e8eac17ab8e3812f010678cf791"), 0)
+	assert.Equal(t, ethgo.HexToAddress("0x333c3310824b7c685133f2bedb2ca4b8b4df633d"), addr)
+
+	state := newTestState()
+
+	// CREATE2 with the init code of an empty contract and the salt 1
+	initCode := deployCode([]byte{byte(STOP)})
+	code := []byte{byte(PUSH1) + byte(len(initCode)-1)}
+	code = append(code, initCode...)
+	code = append(code,
+		byte(PUSH0), byte(MSTORE),
+		byte(PUSH1), 0x1, byte(PUSH1), byte(len(initCode)), byte(PUSH1), byte(32-len(initCode)), byte(PUSH0), byte(CREATE2),
+		byte(PUSH0), byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH0), byte(RETURN),
+	)
+	state.SetCode(testContract, code)
+
+	res := newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 200000})
+	assert.NoError(t, res.Err)
+
+	expected := CreateAddress2(testContract, ethgo.BytesToHash([]byte{0x1}), initCode)
+	assert.Equal(t, expected, ethgo.BytesToAddress(res.ReturnData))
+	assert.Equal(t, []byte{byte(STOP)}, state.GetCode(expected))
+	assert.Equal(t, uint64(1), state.GetNonce(testContract))
+}
+
+func TestEVM_Precompiles(t *testing.T) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	hash := ethgo.Keccak256([]byte("hello"))
+	sig, err := key.Sign(hash)
+	assert.NoError(t, err)
+
+	input := append([]byte{}, hash...)
+	input = append(input, ethgo.BytesToHash([]byte{sig[64] + 27}).Bytes()...)
+	input = append(input, sig[:64]...)
+
+	cases := []struct {
+		addr   byte
+		input  []byte
+		output []byte
+	}{
+		{1, input, ethgo.BytesToHash(key.Address().Bytes()).Bytes()},
+		{2, []byte("hello"), func() []byte { h := sha256.Sum256([]byte("hello")); return h[:] }()},
+		{3, []byte{}, mustDecodeHex("0000000000000000000000009c1185a5c5e9fc54612808977ee8f548b2258d31")},
+		{4, []byte{0x1, 0x2}, []byte{0x1, 0x2}},
+		// 3 ** 5 % 7
+		{5, append(append(append(append(
+			ethgo.BytesToHash([]byte{1}).Bytes(),
+			ethgo.BytesToHash([]byte{1}).Bytes()...),
+			ethgo.BytesToHash([]byte{1}).Bytes()...),
+			0x3, 0x5), 0x7), []byte{0x5}},
+	}
+
+	for _, c := range cases {
+		state := newTestState()
+		to := ethgo.BytesToAddress([]byte{c.addr})
+
+		res := newTestEVM(state, nil).ApplyMessage(&Message{From: testSender, To: &to, Data: c.input, Gas: 100000})
+		assert.NoError(t, res.Err)
+		assert.Equal(t, c.output, res.ReturnData)
+	}
+}
+
+func mustDecodeHex(str string) []byte {
+	buf, err := hex.DecodeString(str)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+func TestEVM_StructLogger(t *testing.T) {
+	state := newTestState()
+
+	// SSTORE(0, SLOAD(0) + 1)
+	state.SetCode(testContract, []byte{
+		byte(PUSH0), byte(SLOAD), byte(PUSH1), 0x1, byte(ADD), byte(PUSH0), byte(SSTORE),
+	})
+
+	tracer := NewStructLogger()
+	res := newTestEVM(state, &Config{Tracer: tracer}).ApplyMessage(&Message{From: testSender, To: &testContract, Gas: 100000})
+	assert.NoError(t, res.Err)
+
+	trace := tracer.Trace()
+	assert.False(t, trace.Failed)
+	assert.Equal(t, 100000-res.GasLeft, trace.Gas)
+	assert.Len(t, trace.StructLogs, 7)
+
+	ops := []string{}
+	for _, log := range trace.StructLogs {
+		ops = append(ops, log.Op)
+	}
+	assert.Equal(t, []string{"PUSH0", "SLOAD", "PUSH1", "ADD", "PUSH0", "SSTORE", "STOP"}, ops)
+
+	sload := trace.StructLogs[1]
+	assert.Equal(t, 1, sload.Depth)
+	assert.Equal(t, 2100, sload.GasCost)
+	assert.Equal(t, []string{"0x0"}, sload.Stack)
+
+	sstore := trace.StructLogs[5]
+	assert.Equal(t, []string{"0x1", "0x0"}, sstore.Stack)
+	assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000001",
+		sstore.Storage["0000000000000000000000000000000000000000000000000000000000000000"])
+}
@@ -0,0 +1,367 @@
+package evm
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/umbracle/ethgo"
+)
+
+// gas costs of the instructions
+const (
+	gasQuickStep   uint64 = 2
+	gasFastestStep uint64 = 3
+	gasFastStep    uint64 = 5
+	gasMidStep     uint64 = 8
+	gasSlowStep    uint64 = 10
+	gasExtStep     uint64 = 20
+
+	memoryGas        uint64 = 3
+	quadCoeffDiv     uint64 = 512
+	copyGas          uint64 = 3
+	expByteGas       uint64 = 50
+	keccak256Gas     uint64 = 30
+	keccak256WordGas uint64 = 6
+	jumpdestGas      uint64 = 1
+	logGas           uint64 = 375
+	logTopicGas      uint64 = 375
+	logDataGas       uint64 = 8
+	createGas        uint64 = 32000
+	initCodeWordGas  uint64 = 2
+
+	callValueTransferGas uint64 = 9000
+	callNewAccountGas    uint64 = 25000
+
+	selfdestructGas          uint64 = 5000
+	createBySelfdestructGas  uint64 = 25000
+	coldAccountAccessCost    uint64 = 2600
+	coldSloadCost            uint64 = 2100
+	warmStorageReadCost      uint64 = 100
+	sstoreSetGas             uint64 = 20000
+	sstoreResetGas           uint64 = 5000
+	sstoreSentryGas          uint64 = 2300
+	sstoreClearsScheduleGas  uint64 = sstoreResetGas - coldSloadCost + 1900
+	maxMemorySize            uint64 = 0x1FFFFFFFE0
+	blockhashGas             uint64 = 20
+	blockhashHistory         uint64 = 256
+	callCreateDepthPrecision uint64 = 64
+)
+
+func safeAdd(x, y uint64) (uint64, bool) {
+	sum, carry := bits.Add64(x, y, 0)
+	return sum, carry != 0
+}
+
+func safeMul(x, y uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(x, y)
+	return lo, hi != 0
+}
+
+// toWordSize returns the number of 32 bytes words of the size
+func toWordSize(size uint64) uint64 {
+	if size > math.MaxUint64-31 {
+		return math.MaxUint64/32 + 1
+	}
+	return (size + 31) / 32
+}
+
+// memoryGasCost returns the cost of expanding the memory to the new size
+func memoryGasCost(mem *memory, newMemSize uint64) (uint64, error) {
+	if newMemSize == 0 {
+		return 0, nil
+	}
+	if newMemSize > maxMemorySize {
+		return 0, ErrGasUintOverflow
+	}
+	words := toWordSize(newMemSize)
+	if words*32 <= uint64(mem.len()) {
+		return 0, nil
+	}
+	total := words*memoryGas + words*words/quadCoeffDiv
+	fee := total - mem.lastGasCost
+	mem.lastGasCost = total
+	return fee, nil
+}
+
+func gasMemory(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	return memoryGasCost(scope.memory, memorySize)
+}
+
+// memoryCopierGas returns the cost of the instructions that copy
+// the data at the n-th position of the stack into the memory
+func memoryCopierGas(n int) dynamicGasFunc {
+	return func(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+		gas, err := memoryGasCost(scope.memory, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		size := scope.stack.peek(n)
+		if !size.IsUint64() {
+			return 0, ErrGasUintOverflow
+		}
+		words, overflow := safeMul(toWordSize(size.Uint64()), copyGas)
+		if overflow {
+			return 0, ErrGasUintOverflow
+		}
+		if gas, overflow = safeAdd(gas, words); overflow {
+			return 0, ErrGasUintOverflow
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasCallDataCopy   = memoryCopierGas(2)
+	gasCodeCopy       = memoryCopierGas(2)
+	gasReturnDataCopy = memoryCopierGas(2)
+	gasExtCodeCopy    = memoryCopierGas(3)
+)
+
+func gasExtCodeCopyEIP2929(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	gas, err := gasExtCodeCopy(e, scope, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	addr := ethgo.BytesToAddress(scope.stack.peek(0).Bytes())
+	if !e.warmAddress(addr) {
+		var overflow bool
+		if gas, overflow = safeAdd(gas, coldAccountAccessCost-warmStorageReadCost); overflow {
+			return 0, ErrGasUintOverflow
+		}
+	}
+	return gas, nil
+}
+
+func gasKeccak256(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	gas, err := memoryGasCost(scope.memory, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	size := scope.stack.peek(1)
+	if !size.IsUint64() {
+		return 0, ErrGasUintOverflow
+	}
+	words, overflow := safeMul(toWordSize(size.Uint64()), keccak256WordGas)
+	if overflow {
+		return 0, ErrGasUintOverflow
+	}
+	if gas, overflow = safeAdd(gas, words); overflow {
+		return 0, ErrGasUintOverflow
+	}
+	return gas, nil
+}
+
+func gasExp(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	byteLen := uint64((scope.stack.peek(1).BitLen() + 7) / 8)
+	return byteLen * expByteGas, nil
+}
+
+func makeGasLog(n uint64) dynamicGasFunc {
+	return func(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+		size := scope.stack.peek(1)
+		if !size.IsUint64() {
+			return 0, ErrGasUintOverflow
+		}
+		gas, err := memoryGasCost(scope.memory, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		var overflow bool
+		if gas, overflow = safeAdd(gas, logGas+n*logTopicGas); overflow {
+			return 0, ErrGasUintOverflow
+		}
+		dataGas, overflow := safeMul(size.Uint64(), logDataGas)
+		if overflow {
+			return 0, ErrGasUintOverflow
+		}
+		if gas, overflow = safeAdd(gas, dataGas); overflow {
+			return 0, ErrGasUintOverflow
+		}
+		return gas, nil
+	}
+}
+
+// gasAccountCheck charges the cold access of the account at the top
+// of the stack (BALANCE, EXTCODESIZE and EXTCODEHASH)
+func gasAccountCheck(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	addr := ethgo.BytesToAddress(scope.stack.peek(0).Bytes())
+	if !e.warmAddress(addr) {
+		return coldAccountAccessCost - warmStorageReadCost, nil
+	}
+	return 0, nil
+}
+
+func gasSLoad(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	slot := bigToHash(scope.stack.peek(0))
+	if !e.warmSlot(scope.Contract.Address, slot) {
+		return coldSloadCost, nil
+	}
+	return warmStorageReadCost, nil
+}
+
+// gasSStore is the cost of SSTORE with the rules of EIP-2200, EIP-2929 and EIP-3529
+func gasSStore(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	if scope.Contract.Gas <= sstoreSentryGas {
+		return 0, ErrOutOfGas
+	}
+
+	addr := scope.Contract.Address
+	slot := bigToHash(scope.stack.peek(0))
+	value := bigToHash(scope.stack.peek(1))
+
+	var cost uint64
+	if !e.warmSlot(addr, slot) {
+		cost = coldSloadCost
+	}
+
+	current := e.State.GetState(addr, slot)
+	if current == value {
+		return cost + warmStorageReadCost, nil
+	}
+	original := e.original(addr, slot)
+	if original == current {
+		if original == (ethgo.Hash{}) {
+			return cost + sstoreSetGas, nil
+		}
+		if value == (ethgo.Hash{}) {
+			e.addRefund(sstoreClearsScheduleGas)
+		}
+		return cost + (sstoreResetGas - coldSloadCost), nil
+	}
+	if original != (ethgo.Hash{}) {
+		if current == (ethgo.Hash{}) {
+			if err := e.subRefund(sstoreClearsScheduleGas); err != nil {
+				return 0, err
+			}
+		} else if value == (ethgo.Hash{}) {
+			e.addRefund(sstoreClearsScheduleGas)
+		}
+	}
+	if original == value {
+		if original == (ethgo.Hash{}) {
+			e.addRefund(sstoreSetGas - warmStorageReadCost)
+		} else {
+			e.addRefund(sstoreResetGas - coldSloadCost - warmStorageReadCost)
+		}
+	}
+	return cost + warmStorageReadCost, nil
+}
+
+func gasCreate(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	return createGasCost(scope, memorySize, initCodeWordGas)
+}
+
+func gasCreate2(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	return createGasCost(scope, memorySize, initCodeWordGas+keccak256WordGas)
+}
+
+func createGasCost(scope *ScopeContext, memorySize uint64, wordGas uint64) (uint64, error) {
+	gas, err := memoryGasCost(scope.memory, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	size := scope.stack.peek(2)
+	if !size.IsUint64() {
+		return 0, ErrGasUintOverflow
+	}
+	if size.Uint64() > maxInitCodeSize {
+		return 0, ErrMaxInitCodeSizeExceeded
+	}
+	words := toWordSize(size.Uint64()) * wordGas
+	var overflow bool
+	if gas, overflow = safeAdd(gas, words); overflow {
+		return 0, ErrGasUintOverflow
+	}
+	return gas, nil
+}
+
+// callGas returns the gas sent to a call with the 63/64 rule of EIP-150
+func callGas(available, base uint64, callCost *big.Int) (uint64, error) {
+	if available < base {
+		return 0, ErrOutOfGas
+	}
+	available = available - base
+	gas := available - available/callCreateDepthPrecision
+	if !callCost.IsUint64() || gas < callCost.Uint64() {
+		return gas, nil
+	}
+	return callCost.Uint64(), nil
+}
+
+func gasCall(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	var gas uint64
+	transfersValue := scope.stack.peek(2).Sign() != 0
+	addr := ethgo.BytesToAddress(scope.stack.peek(1).Bytes())
+	if transfersValue && e.State.Empty(addr) {
+		gas += callNewAccountGas
+	}
+	if transfersValue {
+		gas += callValueTransferGas
+	}
+	return callGasCost(e, scope, memorySize, gas)
+}
+
+func gasCallCode(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	var gas uint64
+	if scope.stack.peek(2).Sign() != 0 {
+		gas += callValueTransferGas
+	}
+	return callGasCost(e, scope, memorySize, gas)
+}
+
+func gasDelegateOrStaticCall(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	return callGasCost(e, scope, memorySize, 0)
+}
+
+func callGasCost(e *EVM, scope *ScopeContext, memorySize uint64, gas uint64) (uint64, error) {
+	memGas, err := memoryGasCost(scope.memory, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	var overflow bool
+	if gas, overflow = safeAdd(gas, memGas); overflow {
+		return 0, ErrGasUintOverflow
+	}
+	if e.callGasTemp, err = callGas(scope.Contract.Gas, gas, scope.stack.peek(0)); err != nil {
+		return 0, err
+	}
+	if gas, overflow = safeAdd(gas, e.callGasTemp); overflow {
+		return 0, ErrGasUintOverflow
+	}
+	return gas, nil
+}
+
+// makeCallVariantGasEIP2929 charges the cold access of the called account. The cold
+// cost is paid before the gas of the call is computed with the 63/64 rule.
+func makeCallVariantGasEIP2929(gasFunc dynamicGasFunc) dynamicGasFunc {
+	return func(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+		addr := ethgo.BytesToAddress(scope.stack.peek(1).Bytes())
+		warm := e.warmAddress(addr)
+
+		coldCost := coldAccountAccessCost - warmStorageReadCost
+		if !warm && !scope.Contract.useGas(coldCost) {
+			return 0, ErrOutOfGas
+		}
+		gas, err := gasFunc(e, scope, memorySize)
+		if warm || err != nil {
+			return gas, err
+		}
+
+		// the cold cost is charged again as part of the dynamic gas
+		scope.Contract.Gas += coldCost
+		return gas + coldCost, nil
+	}
+}
+
+func gasSelfdestruct(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error) {
+	var gas uint64
+	addr := ethgo.BytesToAddress(scope.stack.peek(0).Bytes())
+	if !e.warmAddress(addr) {
+		gas = coldAccountAccessCost
+	}
+	if e.State.Empty(addr) && e.State.GetBalance(scope.Contract.Address).Sign() != 0 {
+		gas += createBySelfdestructGas
+	}
+	return gas, nil
+}
@@ -0,0 +1,685 @@
+package evm
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+)
+
+var one = big.NewInt(1)
+
+func bigToHash(x *big.Int) ethgo.Hash {
+	return ethgo.BytesToHash(x.Bytes())
+}
+
+func bigToAddress(x *big.Int) ethgo.Address {
+	return ethgo.BytesToAddress(x.Bytes())
+}
+
+func boolToBig(b bool) *big.Int {
+	if b {
+		return big.NewInt(1)
+	}
+	return big.NewInt(0)
+}
+
+// uint64OrMax returns the value as uint64 or the max uint64 if it overflows
+func uint64OrMax(x *big.Int) uint64 {
+	if !x.IsUint64() {
+		return math.MaxUint64
+	}
+	return x.Uint64()
+}
+
+// getData returns the slice of the data right padded with zeros
+func getData(data []byte, start, size uint64) []byte {
+	length := uint64(len(data))
+	if start > length {
+		start = length
+	}
+	end := start + size
+	if end > length || end < start {
+		end = length
+	}
+	res := make([]byte, size)
+	copy(res, data[start:end])
+	return res
+}
+
+func opAdd(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(u256(x.Add(x, y)))
+	return nil, nil
+}
+
+func opSub(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(u256(x.Sub(x, y)))
+	return nil, nil
+}
+
+func opMul(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(u256(x.Mul(x, y)))
+	return nil, nil
+}
+
+func opDiv(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	if y.Sign() == 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(x.Div(x, y))
+	}
+	return nil, nil
+}
+
+func opSdiv(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := s256(scope.stack.pop()), s256(scope.stack.pop())
+	if y.Sign() == 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(u256(new(big.Int).Quo(x, y)))
+	}
+	return nil, nil
+}
+
+func opMod(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	if y.Sign() == 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(x.Mod(x, y))
+	}
+	return nil, nil
+}
+
+func opSmod(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := s256(scope.stack.pop()), s256(scope.stack.pop())
+	if y.Sign() == 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(u256(new(big.Int).Rem(x, y)))
+	}
+	return nil, nil
+}
+
+func opAddmod(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y, z := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	if z.Sign() == 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		x.Add(x, y)
+		scope.stack.push(x.Mod(x, z))
+	}
+	return nil, nil
+}
+
+func opMulmod(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y, z := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	if z.Sign() == 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		x.Mul(x, y)
+		scope.stack.push(x.Mod(x, z))
+	}
+	return nil, nil
+}
+
+func opExp(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	base, exponent := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(base.Exp(base, exponent, tt256))
+	return nil, nil
+}
+
+func opSignExtend(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	back, num := scope.stack.pop(), scope.stack.pop()
+	if back.Cmp(big.NewInt(31)) < 0 {
+		bit := uint(back.Uint64()*8 + 7)
+		mask := new(big.Int).Lsh(one, bit)
+		mask.Sub(mask, one)
+		if num.Bit(int(bit)) == 1 {
+			num.Or(num, new(big.Int).Xor(tt256m1, mask))
+		} else {
+			num.And(num, mask)
+		}
+	}
+	scope.stack.push(u256(num))
+	return nil, nil
+}
+
+func opLt(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(boolToBig(x.Cmp(y) < 0))
+	return nil, nil
+}
+
+func opGt(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(boolToBig(x.Cmp(y) > 0))
+	return nil, nil
+}
+
+func opSlt(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := s256(scope.stack.pop()), s256(scope.stack.pop())
+	scope.stack.push(boolToBig(x.Cmp(y) < 0))
+	return nil, nil
+}
+
+func opSgt(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := s256(scope.stack.pop()), s256(scope.stack.pop())
+	scope.stack.push(boolToBig(x.Cmp(y) > 0))
+	return nil, nil
+}
+
+func opEq(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(boolToBig(x.Cmp(y) == 0))
+	return nil, nil
+}
+
+func opIszero(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x := scope.stack.pop()
+	scope.stack.push(boolToBig(x.Sign() == 0))
+	return nil, nil
+}
+
+func opAnd(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(x.And(x, y))
+	return nil, nil
+}
+
+func opOr(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(x.Or(x, y))
+	return nil, nil
+}
+
+func opXor(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x, y := scope.stack.pop(), scope.stack.pop()
+	scope.stack.push(x.Xor(x, y))
+	return nil, nil
+}
+
+func opNot(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	x := scope.stack.pop()
+	scope.stack.push(x.Xor(x, tt256m1))
+	return nil, nil
+}
+
+func opByte(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	th, val := scope.stack.pop(), scope.stack.pop()
+	if th.Cmp(big.NewInt(32)) < 0 {
+		word := bigToHash(val)
+		scope.stack.push(big.NewInt(int64(word[th.Uint64()])))
+	} else {
+		scope.stack.push(new(big.Int))
+	}
+	return nil, nil
+}
+
+func opSHL(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	shift, value := scope.stack.pop(), scope.stack.pop()
+	if shift.Cmp(big.NewInt(256)) >= 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(u256(value.Lsh(value, uint(shift.Uint64()))))
+	}
+	return nil, nil
+}
+
+func opSHR(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	shift, value := scope.stack.pop(), scope.stack.pop()
+	if shift.Cmp(big.NewInt(256)) >= 0 {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(value.Rsh(value, uint(shift.Uint64())))
+	}
+	return nil, nil
+}
+
+func opSAR(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	shift, value := scope.stack.pop(), s256(scope.stack.pop())
+	if shift.Cmp(big.NewInt(256)) >= 0 {
+		if value.Sign() < 0 {
+			scope.stack.push(new(big.Int).Set(tt256m1))
+		} else {
+			scope.stack.push(new(big.Int))
+		}
+	} else {
+		scope.stack.push(u256(new(big.Int).Rsh(value, uint(shift.Uint64()))))
+	}
+	return nil, nil
+}
+
+func opKeccak256(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset, size := scope.stack.pop(), scope.stack.pop()
+	data := scope.memory.get(offset.Int64(), size.Int64())
+	scope.stack.push(new(big.Int).SetBytes(ethgo.Keccak256(data)))
+	return nil, nil
+}
+
+func opAddress(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetBytes(scope.Contract.Address[:]))
+	return nil, nil
+}
+
+func opBalance(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	addr := bigToAddress(scope.stack.pop())
+	scope.stack.push(e.State.GetBalance(addr))
+	return nil, nil
+}
+
+func opOrigin(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetBytes(e.Tx.Origin[:]))
+	return nil, nil
+}
+
+func opCaller(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetBytes(scope.Contract.Caller[:]))
+	return nil, nil
+}
+
+func opCallValue(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).Set(scope.Contract.Value))
+	return nil, nil
+}
+
+func opCallDataLoad(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset := scope.stack.pop()
+	data := getData(scope.Contract.Input, uint64OrMax(offset), 32)
+	scope.stack.push(new(big.Int).SetBytes(data))
+	return nil, nil
+}
+
+func opCallDataSize(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(big.NewInt(int64(len(scope.Contract.Input))))
+	return nil, nil
+}
+
+func opCallDataCopy(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	memOffset, dataOffset, length := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	data := getData(scope.Contract.Input, uint64OrMax(dataOffset), length.Uint64())
+	scope.memory.set(memOffset.Uint64(), length.Uint64(), data)
+	return nil, nil
+}
+
+func opCodeSize(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(big.NewInt(int64(len(scope.Contract.Code))))
+	return nil, nil
+}
+
+func opCodeCopy(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	memOffset, codeOffset, length := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	data := getData(scope.Contract.Code, uint64OrMax(codeOffset), length.Uint64())
+	scope.memory.set(memOffset.Uint64(), length.Uint64(), data)
+	return nil, nil
+}
+
+func opGasPrice(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).Set(e.Tx.GasPrice))
+	return nil, nil
+}
+
+func opExtCodeSize(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	addr := bigToAddress(scope.stack.pop())
+	scope.stack.push(big.NewInt(int64(len(e.State.GetCode(addr)))))
+	return nil, nil
+}
+
+func opExtCodeCopy(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	addr := bigToAddress(scope.stack.pop())
+	memOffset, codeOffset, length := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	data := getData(e.State.GetCode(addr), uint64OrMax(codeOffset), length.Uint64())
+	scope.memory.set(memOffset.Uint64(), length.Uint64(), data)
+	return nil, nil
+}
+
+func opReturnDataSize(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(big.NewInt(int64(len(scope.returnData))))
+	return nil, nil
+}
+
+func opReturnDataCopy(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	memOffset, dataOffset, length := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	if !dataOffset.IsUint64() {
+		return nil, ErrReturnDataOutOfBounds
+	}
+	end, overflow := safeAdd(dataOffset.Uint64(), length.Uint64())
+	if overflow || uint64(len(scope.returnData)) < end {
+		return nil, ErrReturnDataOutOfBounds
+	}
+	scope.memory.set(memOffset.Uint64(), length.Uint64(), scope.returnData[dataOffset.Uint64():end])
+	return nil, nil
+}
+
+func opExtCodeHash(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	addr := bigToAddress(scope.stack.pop())
+	if e.State.Empty(addr) {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(new(big.Int).SetBytes(ethgo.Keccak256(e.State.GetCode(addr))))
+	}
+	return nil, nil
+}
+
+func opBlockhash(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	num := scope.stack.pop()
+	if !num.IsUint64() || e.Block.GetHash == nil {
+		scope.stack.push(new(big.Int))
+		return nil, nil
+	}
+
+	var lower uint64
+	if e.Block.Number > blockhashHistory {
+		lower = e.Block.Number - blockhashHistory
+	}
+	if n := num.Uint64(); n >= lower && n < e.Block.Number {
+		hash := e.Block.GetHash(n)
+		scope.stack.push(new(big.Int).SetBytes(hash[:]))
+	} else {
+		scope.stack.push(new(big.Int))
+	}
+	return nil, nil
+}
+
+func opCoinbase(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetBytes(e.Block.Coinbase[:]))
+	return nil, nil
+}
+
+func opTimestamp(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetUint64(e.Block.Timestamp))
+	return nil, nil
+}
+
+func opNumber(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetUint64(e.Block.Number))
+	return nil, nil
+}
+
+func opPrevRandao(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	if e.Block.Random != nil {
+		scope.stack.push(new(big.Int).SetBytes(e.Block.Random[:]))
+	} else {
+		scope.stack.push(bigOrZero(e.Block.Difficulty))
+	}
+	return nil, nil
+}
+
+func opGasLimit(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetUint64(e.Block.GasLimit))
+	return nil, nil
+}
+
+func opChainID(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(bigOrZero(e.Block.ChainID))
+	return nil, nil
+}
+
+func opSelfBalance(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(e.State.GetBalance(scope.Contract.Address))
+	return nil, nil
+}
+
+func opBaseFee(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(bigOrZero(e.Block.BaseFee))
+	return nil, nil
+}
+
+func bigOrZero(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(x)
+}
+
+func opPop(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.pop()
+	return nil, nil
+}
+
+func opMload(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset := scope.stack.pop()
+	scope.stack.push(new(big.Int).SetBytes(scope.memory.get(offset.Int64(), 32)))
+	return nil, nil
+}
+
+func opMstore(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset, val := scope.stack.pop(), scope.stack.pop()
+	scope.memory.set32(offset.Uint64(), val)
+	return nil, nil
+}
+
+func opMstore8(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset, val := scope.stack.pop(), scope.stack.pop()
+	scope.memory.data[offset.Uint64()] = byte(val.Uint64())
+	return nil, nil
+}
+
+func opSload(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	slot := bigToHash(scope.stack.pop())
+	val := e.State.GetState(scope.Contract.Address, slot)
+	scope.stack.push(new(big.Int).SetBytes(val[:]))
+	return nil, nil
+}
+
+func opSstore(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	slot, val := scope.stack.pop(), scope.stack.pop()
+	e.State.SetState(scope.Contract.Address, bigToHash(slot), bigToHash(val))
+	return nil, nil
+}
+
+func opJump(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	pos := scope.stack.pop()
+	if !scope.Contract.validJumpdest(pos) {
+		return nil, ErrInvalidJump
+	}
+	// the pc is increased after the instruction
+	*pc = pos.Uint64() - 1
+	return nil, nil
+}
+
+func opJumpi(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	pos, cond := scope.stack.pop(), scope.stack.pop()
+	if cond.Sign() != 0 {
+		if !scope.Contract.validJumpdest(pos) {
+			return nil, ErrInvalidJump
+		}
+		*pc = pos.Uint64() - 1
+	}
+	return nil, nil
+}
+
+func opJumpdest(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	return nil, nil
+}
+
+func opPc(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetUint64(*pc))
+	return nil, nil
+}
+
+func opMsize(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(big.NewInt(int64(scope.memory.len())))
+	return nil, nil
+}
+
+func opGas(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int).SetUint64(scope.Contract.Gas))
+	return nil, nil
+}
+
+func opPush0(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.push(new(big.Int))
+	return nil, nil
+}
+
+func makePush(size uint64) executionFunc {
+	return func(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+		data := getData(scope.Contract.Code, *pc+1, size)
+		scope.stack.push(new(big.Int).SetBytes(data))
+		*pc += size
+		return nil, nil
+	}
+}
+
+func makeDup(n int) executionFunc {
+	return func(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+		scope.stack.dup(n)
+		return nil, nil
+	}
+}
+
+func makeSwap(n int) executionFunc {
+	return func(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+		scope.stack.swap(n)
+		return nil, nil
+	}
+}
+
+func makeLog(n int) executionFunc {
+	return func(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+		offset, size := scope.stack.pop(), scope.stack.pop()
+		topics := make([]ethgo.Hash, n)
+		for i := 0; i < n; i++ {
+			topics[i] = bigToHash(scope.stack.pop())
+		}
+		e.addLog(&ethgo.Log{
+			Address:     scope.Contract.Address,
+			Topics:      topics,
+			Data:        scope.memory.get(offset.Int64(), size.Int64()),
+			BlockNumber: e.Block.Number,
+		})
+		return nil, nil
+	}
+}
+
+func opCreate(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	value, offset, size := scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	input := scope.memory.get(offset.Int64(), size.Int64())
+
+	gas := scope.Contract.Gas
+	gas -= gas / callCreateDepthPrecision
+	scope.Contract.useGas(gas)
+
+	res, addr, returnGas, err := e.Create(scope.Contract.Address, input, gas, value)
+	return createResult(scope, res, addr, returnGas, err)
+}
+
+func opCreate2(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	value, offset, size, salt := scope.stack.pop(), scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+	input := scope.memory.get(offset.Int64(), size.Int64())
+
+	gas := scope.Contract.Gas
+	gas -= gas / callCreateDepthPrecision
+	scope.Contract.useGas(gas)
+
+	res, addr, returnGas, err := e.Create2(scope.Contract.Address, input, gas, value, bigToHash(salt))
+	return createResult(scope, res, addr, returnGas, err)
+}
+
+func createResult(scope *ScopeContext, res []byte, addr ethgo.Address, returnGas uint64, err error) ([]byte, error) {
+	if err != nil {
+		scope.stack.push(new(big.Int))
+	} else {
+		scope.stack.push(new(big.Int).SetBytes(addr[:]))
+	}
+	scope.Contract.Gas += returnGas
+
+	if err == ErrExecutionReverted {
+		scope.returnData = res
+		return res, nil
+	}
+	scope.returnData = nil
+	return nil, nil
+}
+
+func opCall(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.pop()
+	addr, value := bigToAddress(scope.stack.pop()), scope.stack.pop()
+	inOffset, inSize, retOffset, retSize := scope.stack.pop(), scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+
+	if e.readOnly && value.Sign() != 0 {
+		return nil, ErrWriteProtection
+	}
+	gas := e.callGasTemp
+	if value.Sign() != 0 {
+		gas += callStipend
+	}
+
+	args := scope.memory.get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := e.Call(scope.Contract.Address, addr, args, gas, value)
+	return callResult(scope, ret, returnGas, err, retOffset, retSize)
+}
+
+func opCallCode(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.pop()
+	addr, value := bigToAddress(scope.stack.pop()), scope.stack.pop()
+	inOffset, inSize, retOffset, retSize := scope.stack.pop(), scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+
+	gas := e.callGasTemp
+	if value.Sign() != 0 {
+		gas += callStipend
+	}
+
+	args := scope.memory.get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := e.CallCode(scope.Contract.Address, addr, args, gas, value)
+	return callResult(scope, ret, returnGas, err, retOffset, retSize)
+}
+
+func opDelegateCall(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.pop()
+	addr := bigToAddress(scope.stack.pop())
+	inOffset, inSize, retOffset, retSize := scope.stack.pop(), scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+
+	args := scope.memory.get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := e.DelegateCall(scope.Contract, addr, args, e.callGasTemp)
+	return callResult(scope, ret, returnGas, err, retOffset, retSize)
+}
+
+func opStaticCall(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	scope.stack.pop()
+	addr := bigToAddress(scope.stack.pop())
+	inOffset, inSize, retOffset, retSize := scope.stack.pop(), scope.stack.pop(), scope.stack.pop(), scope.stack.pop()
+
+	args := scope.memory.get(inOffset.Int64(), inSize.Int64())
+	ret, returnGas, err := e.StaticCall(scope.Contract.Address, addr, args, e.callGasTemp)
+	return callResult(scope, ret, returnGas, err, retOffset, retSize)
+}
+
+func callResult(scope *ScopeContext, ret []byte, returnGas uint64, err error, retOffset, retSize *big.Int) ([]byte, error) {
+	scope.stack.push(boolToBig(err == nil))
+	if err == nil || err == ErrExecutionReverted {
+		scope.memory.set(retOffset.Uint64(), retSize.Uint64(), ret)
+	}
+	scope.Contract.Gas += returnGas
+	scope.returnData = ret
+	return ret, nil
+}
+
+func opReturn(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset, size := scope.stack.pop(), scope.stack.pop()
+	return scope.memory.get(offset.Int64(), size.Int64()), errStopToken
+}
+
+func opRevert(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	offset, size := scope.stack.pop(), scope.stack.pop()
+	scope.returnData = scope.memory.get(offset.Int64(), size.Int64())
+	return scope.returnData, ErrExecutionReverted
+}
+
+func opStop(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	return nil, errStopToken
+}
+
+func opSelfdestruct(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error) {
+	beneficiary := bigToAddress(scope.stack.pop())
+	balance := e.State.GetBalance(scope.Contract.Address)
+	e.State.AddBalance(beneficiary, balance)
+	e.State.SubBalance(scope.Contract.Address, balance)
+	e.destruct(scope.Contract.Address)
+	return nil, errStopToken
+}
@@ -0,0 +1,183 @@
+package evm
+
+import (
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+)
+
+// Contract is the code executed in a call frame
+type Contract struct {
+	// Caller is the account that started the call
+	Caller ethgo.Address
+
+	// Address is the account in which context the code runs
+	Address ethgo.Address
+
+	// CodeAddress is the account that owns the code
+	CodeAddress ethgo.Address
+
+	Code  []byte
+	Input []byte
+	Value *big.Int
+	Gas   uint64
+
+	// jumpdests are the valid destinations of a jump
+	jumpdests []bool
+}
+
+func newContract(caller, addr, codeAddr ethgo.Address, code []byte, value *big.Int, gas uint64) *Contract {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return &Contract{
+		Caller:      caller,
+		Address:     addr,
+		CodeAddress: codeAddr,
+		Code:        code,
+		Value:       value,
+		Gas:         gas,
+	}
+}
+
+func (c *Contract) useGas(gas uint64) bool {
+	if c.Gas < gas {
+		return false
+	}
+	c.Gas -= gas
+	return true
+}
+
+func (c *Contract) getOp(pc uint64) OpCode {
+	if pc < uint64(len(c.Code)) {
+		return OpCode(c.Code[pc])
+	}
+	return STOP
+}
+
+func (c *Contract) validJumpdest(dest *big.Int) bool {
+	if !dest.IsUint64() || dest.Uint64() >= uint64(len(c.Code)) {
+		return false
+	}
+	if c.jumpdests == nil {
+		c.jumpdests = analyzeJumpdests(c.Code)
+	}
+	return c.jumpdests[dest.Uint64()]
+}
+
+// analyzeJumpdests returns the positions of the JUMPDEST instructions
+// that are not part of the data of a push
+func analyzeJumpdests(code []byte) []bool {
+	res := make([]bool, len(code))
+	for pc := 0; pc < len(code); pc++ {
+		op := OpCode(code[pc])
+		if op == JUMPDEST {
+			res[pc] = true
+		} else if op.IsPush() {
+			pc += int(op - PUSH0)
+		}
+	}
+	return res
+}
+
+// ScopeContext is the state of a call frame
+type ScopeContext struct {
+	Contract *Contract
+
+	stack      *stack
+	memory     *memory
+	returnData []byte
+}
+
+// Stack returns the values of the stack from the bottom to the top
+func (s *ScopeContext) Stack() []*big.Int {
+	return s.stack.data
+}
+
+// Memory returns the memory of the frame
+func (s *ScopeContext) Memory() []byte {
+	return s.memory.data
+}
+
+// run executes the code of the contract
+func (e *EVM) run(contract *Contract, input []byte, readOnly bool) ([]byte, error) {
+	e.depth++
+	defer func() {
+		e.depth--
+	}()
+
+	if readOnly && !e.readOnly {
+		e.readOnly = true
+		defer func() {
+			e.readOnly = false
+		}()
+	}
+
+	contract.Input = input
+	scope := &ScopeContext{
+		Contract: contract,
+		stack:    newStack(),
+		memory:   &memory{},
+	}
+	tracer := e.config.Tracer
+
+	var pc uint64
+	for {
+		op := contract.getOp(pc)
+		operation := jumpTable[op]
+		if operation == nil {
+			return nil, &ErrInvalidOpCode{OpCode: op}
+		}
+		if size := scope.stack.len(); size < operation.minStack {
+			return nil, ErrStackUnderflow
+		} else if size > operation.maxStack {
+			return nil, ErrStackOverflow
+		}
+		if e.readOnly && operation.writes {
+			return nil, ErrWriteProtection
+		}
+
+		gas := contract.Gas
+		cost := operation.constantGas
+		if !contract.useGas(cost) {
+			return nil, ErrOutOfGas
+		}
+
+		var memorySize uint64
+		if operation.memorySize != nil {
+			size, overflow := operation.memorySize(scope.stack)
+			if overflow {
+				return nil, ErrGasUintOverflow
+			}
+			if memorySize, overflow = safeMul(toWordSize(size), 32); overflow {
+				return nil, ErrGasUintOverflow
+			}
+		}
+		if operation.dynamicGas != nil {
+			dynamicCost, err := operation.dynamicGas(e, scope, memorySize)
+			if err != nil {
+				return nil, err
+			}
+			cost += dynamicCost
+			if !contract.useGas(dynamicCost) {
+				return nil, ErrOutOfGas
+			}
+		}
+
+		if tracer != nil {
+			tracer.CaptureState(pc, op, gas, cost, scope, e.depth)
+		}
+		if memorySize > 0 {
+			scope.memory.resize(memorySize)
+		}
+
+		res, err := operation.execute(&pc, e, scope)
+		if err == errStopToken {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		pc++
+	}
+}
@@ -0,0 +1,220 @@
+package evm
+
+import (
+	"math/big"
+)
+
+type (
+	executionFunc  func(pc *uint64, e *EVM, scope *ScopeContext) ([]byte, error)
+	dynamicGasFunc func(e *EVM, scope *ScopeContext, memorySize uint64) (uint64, error)
+	memorySizeFunc func(s *stack) (uint64, bool)
+)
+
+type operation struct {
+	execute     executionFunc
+	constantGas uint64
+	dynamicGas  dynamicGasFunc
+	memorySize  memorySizeFunc
+
+	// minStack and maxStack are the bounds of the stack before the execution
+	minStack int
+	maxStack int
+
+	// writes is true if the operation modifies the state
+	writes bool
+}
+
+// calcMemSize returns the end of the memory region with the offset and the length
+func calcMemSize(offset, length *big.Int) (uint64, bool) {
+	if length.Sign() == 0 {
+		return 0, false
+	}
+	if !offset.IsUint64() || !length.IsUint64() {
+		return 0, true
+	}
+	return safeAdd(offset.Uint64(), length.Uint64())
+}
+
+func calcMemSizeUint(offset *big.Int, length uint64) (uint64, bool) {
+	if !offset.IsUint64() {
+		return 0, true
+	}
+	return safeAdd(offset.Uint64(), length)
+}
+
+// memoryRegion returns the memory size function of the region with the offset
+// and the length at the given positions of the stack
+func memoryRegion(offset, length int) memorySizeFunc {
+	return func(s *stack) (uint64, bool) {
+		return calcMemSize(s.peek(offset), s.peek(length))
+	}
+}
+
+func memoryWord(size uint64) memorySizeFunc {
+	return func(s *stack) (uint64, bool) {
+		return calcMemSizeUint(s.peek(0), size)
+	}
+}
+
+// memoryCall returns the memory size function of a call which is the
+// largest of the input and the output regions
+func memoryCall(in, out int) memorySizeFunc {
+	return func(s *stack) (uint64, bool) {
+		x, overflow := calcMemSize(s.peek(out), s.peek(out+1))
+		if overflow {
+			return 0, true
+		}
+		y, overflow := calcMemSize(s.peek(in), s.peek(in+1))
+		if overflow {
+			return 0, true
+		}
+		if x > y {
+			return x, false
+		}
+		return y, false
+	}
+}
+
+var jumpTable [256]*operation
+
+func simpleOp(execute executionFunc, gas uint64, pops, push int) *operation {
+	return &operation{
+		execute:     execute,
+		constantGas: gas,
+		minStack:    pops,
+		maxStack:    stackLimit + pops - push,
+	}
+}
+
+func init() {
+	// jump table of the Shanghai fork
+	jt := map[OpCode]*operation{
+		STOP:       simpleOp(opStop, 0, 0, 0),
+		ADD:        simpleOp(opAdd, gasFastestStep, 2, 1),
+		MUL:        simpleOp(opMul, gasFastStep, 2, 1),
+		SUB:        simpleOp(opSub, gasFastestStep, 2, 1),
+		DIV:        simpleOp(opDiv, gasFastStep, 2, 1),
+		SDIV:       simpleOp(opSdiv, gasFastStep, 2, 1),
+		MOD:        simpleOp(opMod, gasFastStep, 2, 1),
+		SMOD:       simpleOp(opSmod, gasFastStep, 2, 1),
+		ADDMOD:     simpleOp(opAddmod, gasMidStep, 3, 1),
+		MULMOD:     simpleOp(opMulmod, gasMidStep, 3, 1),
+		EXP:        simpleOp(opExp, gasSlowStep, 2, 1),
+		SIGNEXTEND: simpleOp(opSignExtend, gasFastStep, 2, 1),
+
+		LT:     simpleOp(opLt, gasFastestStep, 2, 1),
+		GT:     simpleOp(opGt, gasFastestStep, 2, 1),
+		SLT:    simpleOp(opSlt, gasFastestStep, 2, 1),
+		SGT:    simpleOp(opSgt, gasFastestStep, 2, 1),
+		EQ:     simpleOp(opEq, gasFastestStep, 2, 1),
+		ISZERO: simpleOp(opIszero, gasFastestStep, 1, 1),
+		AND:    simpleOp(opAnd, gasFastestStep, 2, 1),
+		OR:     simpleOp(opOr, gasFastestStep, 2, 1),
+		XOR:    simpleOp(opXor, gasFastestStep, 2, 1),
+		NOT:    simpleOp(opNot, gasFastestStep, 1, 1),
+		BYTE:   simpleOp(opByte, gasFastestStep, 2, 1),
+		SHL:    simpleOp(opSHL, gasFastestStep, 2, 1),
+		SHR:    simpleOp(opSHR, gasFastestStep, 2, 1),
+		SAR:    simpleOp(opSAR, gasFastestStep, 2, 1),
+
+		SHA3: simpleOp(opKeccak256, keccak256Gas, 2, 1),
+
+		ADDRESS:        simpleOp(opAddress, gasQuickStep, 0, 1),
+		BALANCE:        simpleOp(opBalance, warmStorageReadCost, 1, 1),
+		ORIGIN:         simpleOp(opOrigin, gasQuickStep, 0, 1),
+		CALLER:         simpleOp(opCaller, gasQuickStep, 0, 1),
+		CALLVALUE:      simpleOp(opCallValue, gasQuickStep, 0, 1),
+		CALLDATALOAD:   simpleOp(opCallDataLoad, gasFastestStep, 1, 1),
+		CALLDATASIZE:   simpleOp(opCallDataSize, gasQuickStep, 0, 1),
+		CALLDATACOPY:   simpleOp(opCallDataCopy, gasFastestStep, 3, 0),
+		CODESIZE:       simpleOp(opCodeSize, gasQuickStep, 0, 1),
+		CODECOPY:       simpleOp(opCodeCopy, gasFastestStep, 3, 0),
+		GASPRICE:       simpleOp(opGasPrice, gasQuickStep, 0, 1),
+		EXTCODESIZE:    simpleOp(opExtCodeSize, warmStorageReadCost, 1, 1),
+		EXTCODECOPY:    simpleOp(opExtCodeCopy, warmStorageReadCost, 4, 0),
+		RETURNDATASIZE: simpleOp(opReturnDataSize, gasQuickStep, 0, 1),
+		RETURNDATACOPY: simpleOp(opReturnDataCopy, gasFastestStep, 3, 0),
+		EXTCODEHASH:    simpleOp(opExtCodeHash, warmStorageReadCost, 1, 1),
+
+		BLOCKHASH:   simpleOp(opBlockhash, blockhashGas, 1, 1),
+		COINBASE:    simpleOp(opCoinbase, gasQuickStep, 0, 1),
+		TIMESTAMP:   simpleOp(opTimestamp, gasQuickStep, 0, 1),
+		NUMBER:      simpleOp(opNumber, gasQuickStep, 0, 1),
+		PREVRANDAO:  simpleOp(opPrevRandao, gasQuickStep, 0, 1),
+		GASLIMIT:    simpleOp(opGasLimit, gasQuickStep, 0, 1),
+		CHAINID:     simpleOp(opChainID, gasQuickStep, 0, 1),
+		SELFBALANCE: simpleOp(opSelfBalance, gasFastStep, 0, 1),
+		BASEFEE:     simpleOp(opBaseFee, gasQuickStep, 0, 1),
+
+		POP:      simpleOp(opPop, gasQuickStep, 1, 0),
+		MLOAD:    simpleOp(opMload, gasFastestStep, 1, 1),
+		MSTORE:   simpleOp(opMstore, gasFastestStep, 2, 0),
+		MSTORE8:  simpleOp(opMstore8, gasFastestStep, 2, 0),
+		SLOAD:    simpleOp(opSload, 0, 1, 1),
+		SSTORE:   simpleOp(opSstore, 0, 2, 0),
+		JUMP:     simpleOp(opJump, gasMidStep, 1, 0),
+		JUMPI:    simpleOp(opJumpi, gasSlowStep, 2, 0),
+		PC:       simpleOp(opPc, gasQuickStep, 0, 1),
+		MSIZE:    simpleOp(opMsize, gasQuickStep, 0, 1),
+		GAS:      simpleOp(opGas, gasQuickStep, 0, 1),
+		JUMPDEST: simpleOp(opJumpdest, jumpdestGas, 0, 0),
+		PUSH0:    simpleOp(opPush0, gasQuickStep, 0, 1),
+
+		CREATE:       simpleOp(opCreate, createGas, 3, 1),
+		CALL:         simpleOp(opCall, warmStorageReadCost, 7, 1),
+		CALLCODE:     simpleOp(opCallCode, warmStorageReadCost, 7, 1),
+		RETURN:       simpleOp(opReturn, 0, 2, 0),
+		DELEGATECALL: simpleOp(opDelegateCall, warmStorageReadCost, 6, 1),
+		CREATE2:      simpleOp(opCreate2, createGas, 4, 1),
+		STATICCALL:   simpleOp(opStaticCall, warmStorageReadCost, 6, 1),
+		REVERT:       simpleOp(opRevert, 0, 2, 0),
+		SELFDESTRUCT: simpleOp(opSelfdestruct, selfdestructGas, 1, 0),
+	}
+
+	for i := 0; i < 32; i++ {
+		jt[PUSH1+OpCode(i)] = simpleOp(makePush(uint64(i+1)), gasFastestStep, 0, 1)
+	}
+	for i := 0; i < 16; i++ {
+		jt[DUP1+OpCode(i)] = simpleOp(makeDup(i+1), gasFastestStep, i+1, i+2)
+		jt[SWAP1+OpCode(i)] = simpleOp(makeSwap(i+1), gasFastestStep, i+2, i+2)
+	}
+	for i := 0; i < 5; i++ {
+		op := simpleOp(makeLog(i), 0, i+2, 0)
+		op.dynamicGas = makeGasLog(uint64(i))
+		op.memorySize = memoryRegion(0, 1)
+		op.writes = true
+		jt[LOG0+OpCode(i)] = op
+	}
+
+	// dynamic gas and memory of the operations
+	jt[EXP].dynamicGas = gasExp
+	jt[SHA3].dynamicGas, jt[SHA3].memorySize = gasKeccak256, memoryRegion(0, 1)
+
+	jt[BALANCE].dynamicGas = gasAccountCheck
+	jt[EXTCODESIZE].dynamicGas = gasAccountCheck
+	jt[EXTCODEHASH].dynamicGas = gasAccountCheck
+	jt[CALLDATACOPY].dynamicGas, jt[CALLDATACOPY].memorySize = gasCallDataCopy, memoryRegion(0, 2)
+	jt[CODECOPY].dynamicGas, jt[CODECOPY].memorySize = gasCodeCopy, memoryRegion(0, 2)
+	jt[EXTCODECOPY].dynamicGas, jt[EXTCODECOPY].memorySize = gasExtCodeCopyEIP2929, memoryRegion(1, 3)
+	jt[RETURNDATACOPY].dynamicGas, jt[RETURNDATACOPY].memorySize = gasReturnDataCopy, memoryRegion(0, 2)
+
+	jt[MLOAD].dynamicGas, jt[MLOAD].memorySize = gasMemory, memoryWord(32)
+	jt[MSTORE].dynamicGas, jt[MSTORE].memorySize = gasMemory, memoryWord(32)
+	jt[MSTORE8].dynamicGas, jt[MSTORE8].memorySize = gasMemory, memoryWord(1)
+	jt[SLOAD].dynamicGas = gasSLoad
+	jt[SSTORE].dynamicGas, jt[SSTORE].writes = gasSStore, true
+
+	jt[CREATE].dynamicGas, jt[CREATE].memorySize, jt[CREATE].writes = gasCreate, memoryRegion(1, 2), true
+	jt[CREATE2].dynamicGas, jt[CREATE2].memorySize, jt[CREATE2].writes = gasCreate2, memoryRegion(1, 2), true
+	jt[CALL].dynamicGas, jt[CALL].memorySize = makeCallVariantGasEIP2929(gasCall), memoryCall(3, 5)
+	jt[CALLCODE].dynamicGas, jt[CALLCODE].memorySize = makeCallVariantGasEIP2929(gasCallCode), memoryCall(3, 5)
+	jt[DELEGATECALL].dynamicGas, jt[DELEGATECALL].memorySize = makeCallVariantGasEIP2929(gasDelegateOrStaticCall), memoryCall(2, 4)
+	jt[STATICCALL].dynamicGas, jt[STATICCALL].memorySize = makeCallVariantGasEIP2929(gasDelegateOrStaticCall), memoryCall(2, 4)
+	jt[RETURN].dynamicGas, jt[RETURN].memorySize = gasMemory, memoryRegion(0, 1)
+	jt[REVERT].dynamicGas, jt[REVERT].memorySize = gasMemory, memoryRegion(0, 1)
+	jt[SELFDESTRUCT].dynamicGas, jt[SELFDESTRUCT].writes = gasSelfdestruct, true
+
+	for op, operation := range jt {
+		jumpTable[op] = operation
+	}
+}
@@ -0,0 +1,300 @@
+package evm
+
+import "fmt"
+
+// OpCode is an instruction of the evm
+type OpCode byte
+
+// 0x0 range - arithmetic ops
+const (
+	STOP OpCode = iota
+	ADD
+	MUL
+	SUB
+	DIV
+	SDIV
+	MOD
+	SMOD
+	ADDMOD
+	MULMOD
+	EXP
+	SIGNEXTEND
+)
+
+// 0x10 range - comparison ops
+const (
+	LT OpCode = iota + 0x10
+	GT
+	SLT
+	SGT
+	EQ
+	ISZERO
+	AND
+	OR
+	XOR
+	NOT
+	BYTE
+	SHL
+	SHR
+	SAR
+)
+
+// 0x20 range - crypto
+const (
+	SHA3 OpCode = 0x20
+)
+
+// 0x30 range - closure state
+const (
+	ADDRESS OpCode = iota + 0x30
+	BALANCE
+	ORIGIN
+	CALLER
+	CALLVALUE
+	CALLDATALOAD
+	CALLDATASIZE
+	CALLDATACOPY
+	CODESIZE
+	CODECOPY
+	GASPRICE
+	EXTCODESIZE
+	EXTCODECOPY
+	RETURNDATASIZE
+	RETURNDATACOPY
+	EXTCODEHASH
+)
+
+// 0x40 range - block operations
+const (
+	BLOCKHASH OpCode = iota + 0x40
+	COINBASE
+	TIMESTAMP
+	NUMBER
+	PREVRANDAO
+	GASLIMIT
+	CHAINID
+	SELFBALANCE
+	BASEFEE
+)
+
+// 0x50 range - storage and execution
+const (
+	POP OpCode = iota + 0x50
+	MLOAD
+	MSTORE
+	MSTORE8
+	SLOAD
+	SSTORE
+	JUMP
+	JUMPI
+	PC
+	MSIZE
+	GAS
+	JUMPDEST
+)
+
+// 0x5f range - pushes
+const (
+	PUSH0 OpCode = iota + 0x5f
+	PUSH1
+	PUSH2
+	PUSH3
+	PUSH4
+	PUSH5
+	PUSH6
+	PUSH7
+	PUSH8
+	PUSH9
+	PUSH10
+	PUSH11
+	PUSH12
+	PUSH13
+	PUSH14
+	PUSH15
+	PUSH16
+	PUSH17
+	PUSH18
+	PUSH19
+	PUSH20
+	PUSH21
+	PUSH22
+	PUSH23
+	PUSH24
+	PUSH25
+	PUSH26
+	PUSH27
+	PUSH28
+	PUSH29
+	PUSH30
+	PUSH31
+	PUSH32
+)
+
+// 0x80 range - dups
+const (
+	DUP1 OpCode = iota + 0x80
+	DUP2
+	DUP3
+	DUP4
+	DUP5
+	DUP6
+	DUP7
+	DUP8
+	DUP9
+	DUP10
+	DUP11
+	DUP12
+	DUP13
+	DUP14
+	DUP15
+	DUP16
+)
+
+// 0x90 range - swaps
+const (
+	SWAP1 OpCode = iota + 0x90
+	SWAP2
+	SWAP3
+	SWAP4
+	SWAP5
+	SWAP6
+	SWAP7
+	SWAP8
+	SWAP9
+	SWAP10
+	SWAP11
+	SWAP12
+	SWAP13
+	SWAP14
+	SWAP15
+	SWAP16
+)
+
+// 0xa0 range - logging ops
+const (
+	LOG0 OpCode = iota + 0xa0
+	LOG1
+	LOG2
+	LOG3
+	LOG4
+)
+
+// 0xf0 range - closures
+const (
+	CREATE       OpCode = 0xf0
+	CALL         OpCode = 0xf1
+	CALLCODE     OpCode = 0xf2
+	RETURN       OpCode = 0xf3
+	DELEGATECALL OpCode = 0xf4
+	CREATE2      OpCode = 0xf5
+	STATICCALL   OpCode = 0xfa
+	REVERT       OpCode = 0xfd
+	INVALID      OpCode = 0xfe
+	SELFDESTRUCT OpCode = 0xff
+)
+
+var opCodeNames = map[OpCode]string{
+	STOP:           "STOP",
+	ADD:            "ADD",
+	MUL:            "MUL",
+	SUB:            "SUB",
+	DIV:            "DIV",
+	SDIV:           "SDIV",
+	MOD:            "MOD",
+	SMOD:           "SMOD",
+	ADDMOD:         "ADDMOD",
+	MULMOD:         "MULMOD",
+	EXP:            "EXP",
+	SIGNEXTEND:     "SIGNEXTEND",
+	LT:             "LT",
+	GT:             "GT",
+	SLT:            "SLT",
+	SGT:            "SGT",
+	EQ:             "EQ",
+	ISZERO:         "ISZERO",
+	AND:            "AND",
+	OR:             "OR",
+	XOR:            "XOR",
+	NOT:            "NOT",
+	BYTE:           "BYTE",
+	SHL:            "SHL",
+	SHR:            "SHR",
+	SAR:            "SAR",
+	SHA3:           "SHA3",
+	ADDRESS:        "ADDRESS",
+	BALANCE:        "BALANCE",
+	ORIGIN:         "ORIGIN",
+	CALLER:         "CALLER",
+	CALLVALUE:      "CALLVALUE",
+	CALLDATALOAD:   "CALLDATALOAD",
+	CALLDATASIZE:   "CALLDATASIZE",
+	CALLDATACOPY:   "CALLDATACOPY",
+	CODESIZE:       "CODESIZE",
+	CODECOPY:       "CODECOPY",
+	GASPRICE:       "GASPRICE",
+	EXTCODESIZE:    "EXTCODESIZE",
+	EXTCODECOPY:    "EXTCODECOPY",
+	RETURNDATASIZE: "RETURNDATASIZE",
+	RETURNDATACOPY: "RETURNDATACOPY",
+	EXTCODEHASH:    "EXTCODEHASH",
+	BLOCKHASH:      "BLOCKHASH",
+	COINBASE:       "COINBASE",
+	TIMESTAMP:      "TIMESTAMP",
+	NUMBER:         "NUMBER",
+	PREVRANDAO:     "PREVRANDAO",
+	GASLIMIT:       "GASLIMIT",
+	CHAINID:        "CHAINID",
+	SELFBALANCE:    "SELFBALANCE",
+	BASEFEE:        "BASEFEE",
+	POP:            "POP",
+	MLOAD:          "MLOAD",
+	MSTORE:         "MSTORE",
+	MSTORE8:        "MSTORE8",
+	SLOAD:          "SLOAD",
+	SSTORE:         "SSTORE",
+	JUMP:           "JUMP",
+	JUMPI:          "JUMPI",
+	PC:             "PC",
+	MSIZE:          "MSIZE",
+	GAS:            "GAS",
+	JUMPDEST:       "JUMPDEST",
+	PUSH0:          "PUSH0",
+	LOG0:           "LOG0",
+	LOG1:           "LOG1",
+	LOG2:           "LOG2",
+	LOG3:           "LOG3",
+	LOG4:           "LOG4",
+	CREATE:         "CREATE",
+	CALL:           "CALL",
+	CALLCODE:       "CALLCODE",
+	RETURN:         "RETURN",
+	DELEGATECALL:   "DELEGATECALL",
+	CREATE2:        "CREATE2",
+	STATICCALL:     "STATICCALL",
+	REVERT:         "REVERT",
+	INVALID:        "INVALID",
+	SELFDESTRUCT:   "SELFDESTRUCT",
+}
+
+func init() {
+	for i := 1; i <= 32; i++ {
+		opCodeNames[PUSH0+OpCode(i)] = fmt.Sprintf("PUSH%d", i)
+	}
+	for i := 1; i <= 16; i++ {
+		opCodeNames[DUP1+OpCode(i-1)] = fmt.Sprintf("DUP%d", i)
+		opCodeNames[SWAP1+OpCode(i-1)] = fmt.Sprintf("SWAP%d", i)
+	}
+}
+
+// String returns the name of the opcode
+func (op OpCode) String() string {
+	if name, ok := opCodeNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("opcode 0x%x not defined", byte(op))
+}
+
+// IsPush returns true if the opcode is a PUSH1-PUSH32 instruction
+func (op OpCode) IsPush() bool {
+	return op >= PUSH1 && op <= PUSH32
+}
@@ -0,0 +1,184 @@
+package evm
+
+import (
+	"crypto/sha256"
+	"math"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/wallet"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// precompile is a contract implemented natively
+type precompile interface {
+	requiredGas(input []byte) uint64
+	run(input []byte) ([]byte, error)
+}
+
+var precompiles = map[ethgo.Address]precompile{
+	ethgo.BytesToAddress([]byte{1}): &ecrecover{},
+	ethgo.BytesToAddress([]byte{2}): &sha256hash{},
+	ethgo.BytesToAddress([]byte{3}): &ripemd160hash{},
+	ethgo.BytesToAddress([]byte{4}): &identity{},
+	ethgo.BytesToAddress([]byte{5}): &modexp{},
+}
+
+func runPrecompile(p precompile, input []byte, gas uint64) ([]byte, uint64, error) {
+	cost := p.requiredGas(input)
+	if gas < cost {
+		return nil, 0, ErrOutOfGas
+	}
+	out, err := p.run(input)
+	return out, gas - cost, err
+}
+
+func wordGas(input []byte, base, perWord uint64) uint64 {
+	return base + uint64(len(input)+31)/32*perWord
+}
+
+var secp256k1N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+type ecrecover struct{}
+
+func (e *ecrecover) requiredGas(input []byte) uint64 {
+	return 3000
+}
+
+func (e *ecrecover) run(input []byte) ([]byte, error) {
+	input = getData(input, 0, 128)
+
+	// v is a 32 bytes word with the value 27 or 28
+	for _, b := range input[32:63] {
+		if b != 0 {
+			return nil, nil
+		}
+	}
+	v := input[63]
+	if v != 27 && v != 28 {
+		return nil, nil
+	}
+	r, s := new(big.Int).SetBytes(input[64:96]), new(big.Int).SetBytes(input[96:128])
+	if r.Sign() == 0 || s.Sign() == 0 || r.Cmp(secp256k1N) >= 0 || s.Cmp(secp256k1N) >= 0 {
+		return nil, nil
+	}
+
+	sig := append(append([]byte{}, input[64:128]...), v-27)
+	addr, err := wallet.Ecrecover(input[:32], sig)
+	if err != nil {
+		return nil, nil
+	}
+	return ethgo.BytesToHash(addr[:]).Bytes(), nil
+}
+
+type sha256hash struct{}
+
+func (s *sha256hash) requiredGas(input []byte) uint64 {
+	return wordGas(input, 60, 12)
+}
+
+func (s *sha256hash) run(input []byte) ([]byte, error) {
+	h := sha256.Sum256(input)
+	return h[:], nil
+}
+
+type ripemd160hash struct{}
+
+func (r *ripemd160hash) requiredGas(input []byte) uint64 {
+	return wordGas(input, 600, 120)
+}
+
+func (r *ripemd160hash) run(input []byte) ([]byte, error) {
+	h := ripemd160.New()
+	h.Write(input)
+	return ethgo.BytesToHash(h.Sum(nil)).Bytes(), nil
+}
+
+type identity struct{}
+
+func (i *identity) requiredGas(input []byte) uint64 {
+	return wordGas(input, 15, 3)
+}
+
+func (i *identity) run(input []byte) ([]byte, error) {
+	return append([]byte{}, input...), nil
+}
+
+// modexp is the modular exponentiation with the pricing of EIP-2565
+type modexp struct{}
+
+func (m *modexp) lengths(input []byte) (*big.Int, *big.Int, *big.Int) {
+	header := getData(input, 0, 96)
+	return new(big.Int).SetBytes(header[0:32]), new(big.Int).SetBytes(header[32:64]), new(big.Int).SetBytes(header[64:96])
+}
+
+func (m *modexp) requiredGas(input []byte) uint64 {
+	baseLen, expLen, modLen := m.lengths(input)
+
+	// the first 32 bytes of the exponent
+	var expHead *big.Int
+	if !baseLen.IsUint64() || baseLen.Uint64() > math.MaxUint32 {
+		expHead = new(big.Int)
+	} else {
+		headLen := uint64(32)
+		if expLen.Cmp(big.NewInt(32)) < 0 {
+			headLen = expLen.Uint64()
+		}
+		expHead = new(big.Int).SetBytes(getData(input, 96+baseLen.Uint64(), headLen))
+	}
+
+	// multiplication complexity
+	maxLen := baseLen
+	if modLen.Cmp(baseLen) > 0 {
+		maxLen = modLen
+	}
+	words := new(big.Int).Add(maxLen, big.NewInt(7))
+	words.Div(words, big.NewInt(8))
+	gas := words.Mul(words, words)
+
+	// iteration count
+	iterations := new(big.Int)
+	if expLen.Cmp(big.NewInt(32)) > 0 {
+		iterations.Sub(expLen, big.NewInt(32))
+		iterations.Mul(iterations, big.NewInt(8))
+	}
+	if bitLen := expHead.BitLen(); bitLen > 0 {
+		iterations.Add(iterations, big.NewInt(int64(bitLen-1)))
+	}
+	if iterations.Sign() == 0 {
+		iterations.SetUint64(1)
+	}
+
+	gas.Mul(gas, iterations)
+	gas.Div(gas, big.NewInt(3))
+	if !gas.IsUint64() {
+		return math.MaxUint64
+	}
+	if gas.Uint64() < 200 {
+		return 200
+	}
+	return gas.Uint64()
+}
+
+func (m *modexp) run(input []byte) ([]byte, error) {
+	baseLenBig, expLenBig, modLenBig := m.lengths(input)
+
+	// the lengths are bounded by the gas of the call
+	baseLen, expLen, modLen := baseLenBig.Uint64(), expLenBig.Uint64(), modLenBig.Uint64()
+	if baseLen == 0 && modLen == 0 {
+		return []byte{}, nil
+	}
+
+	data := getData(input, 96, baseLen+expLen+modLen)
+	base := new(big.Int).SetBytes(data[:baseLen])
+	exp := new(big.Int).SetBytes(data[baseLen : baseLen+expLen])
+	mod := new(big.Int).SetBytes(data[baseLen+expLen:])
+
+	res := make([]byte, modLen)
+	if mod.Sign() == 0 {
+		return res, nil
+	}
+	out := new(big.Int).Exp(base, exp, mod).Bytes()
+	copy(res[modLen-uint64(len(out)):], out)
+	return res, nil
+}
@@ -0,0 +1,225 @@
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc"
+)
+
+type remoteAccount struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[ethgo.Hash]ethgo.Hash
+
+	// deleted is true if the account was removed during the execution.
+	// The storage is not fetched from the node anymore.
+	deleted bool
+}
+
+// RemoteState is a State that fetches the accounts and the storage slots
+// lazily from a node at a given block. The modifications are kept in memory.
+type RemoteState struct {
+	eth      *jsonrpc.Eth
+	block    ethgo.BlockNumberOrHash
+	accounts map[ethgo.Address]*remoteAccount
+	journal  []func()
+	err      error
+}
+
+// NewRemoteState creates a state backed by the node at the block
+func NewRemoteState(eth *jsonrpc.Eth, block ethgo.BlockNumberOrHash) *RemoteState {
+	return &RemoteState{
+		eth:      eth,
+		block:    block,
+		accounts: map[ethgo.Address]*remoteAccount{},
+	}
+}
+
+// Err returns the first error fetching data from the node. The missing
+// values are read as zero so the result of the execution is not valid.
+func (r *RemoteState) Err() error {
+	return r.err
+}
+
+func (r *RemoteState) setErr(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *RemoteState) account(addr ethgo.Address) *remoteAccount {
+	if acct, ok := r.accounts[addr]; ok {
+		return acct
+	}
+
+	acct := &remoteAccount{
+		balance: new(big.Int),
+		storage: map[ethgo.Hash]ethgo.Hash{},
+	}
+	if balance, err := r.eth.GetBalance(addr, r.block); err != nil {
+		r.setErr(err)
+	} else {
+		acct.balance = balance
+	}
+	if nonce, err := r.eth.GetNonce(addr, r.block); err != nil {
+		r.setErr(err)
+	} else {
+		acct.nonce = nonce
+	}
+	if code, err := r.eth.GetCode(addr, r.block); err != nil {
+		r.setErr(err)
+	} else if acct.code, err = hex.DecodeString(strings.TrimPrefix(code, "0x")); err != nil {
+		r.setErr(err)
+	}
+
+	// the fetched accounts are not part of the journal
+	r.accounts[addr] = acct
+	return acct
+}
+
+// Empty implements the State interface
+func (r *RemoteState) Empty(addr ethgo.Address) bool {
+	acct := r.account(addr)
+	return acct.nonce == 0 && acct.balance.Sign() == 0 && len(acct.code) == 0
+}
+
+// GetBalance implements the State interface
+func (r *RemoteState) GetBalance(addr ethgo.Address) *big.Int {
+	return new(big.Int).Set(r.account(addr).balance)
+}
+
+func (r *RemoteState) setBalance(addr ethgo.Address, balance *big.Int) {
+	acct := r.account(addr)
+	prev := acct.balance
+	acct.balance = balance
+	r.journal = append(r.journal, func() {
+		acct.balance = prev
+	})
+}
+
+// AddBalance implements the State interface
+func (r *RemoteState) AddBalance(addr ethgo.Address, amount *big.Int) {
+	r.setBalance(addr, new(big.Int).Add(r.GetBalance(addr), amount))
+}
+
+// SubBalance implements the State interface
+func (r *RemoteState) SubBalance(addr ethgo.Address, amount *big.Int) {
+	r.setBalance(addr, new(big.Int).Sub(r.GetBalance(addr), amount))
+}
+
+// GetNonce implements the State interface
+func (r *RemoteState) GetNonce(addr ethgo.Address) uint64 {
+	return r.account(addr).nonce
+}
+
+// SetNonce implements the State interface
+func (r *RemoteState) SetNonce(addr ethgo.Address, nonce uint64) {
+	acct := r.account(addr)
+	prev := acct.nonce
+	acct.nonce = nonce
+	r.journal = append(r.journal, func() {
+		acct.nonce = prev
+	})
+}
+
+// GetCode implements the State interface
+func (r *RemoteState) GetCode(addr ethgo.Address) []byte {
+	return r.account(addr).code
+}
+
+// SetCode implements the State interface
+func (r *RemoteState) SetCode(addr ethgo.Address, code []byte) {
+	acct := r.account(addr)
+	prev := acct.code
+	acct.code = append([]byte{}, code...)
+	r.journal = append(r.journal, func() {
+		acct.code = prev
+	})
+}
+
+// GetState implements the State interface
+func (r *RemoteState) GetState(addr ethgo.Address, key ethgo.Hash) ethgo.Hash {
+	acct := r.account(addr)
+	if val, ok := acct.storage[key]; ok || acct.deleted {
+		return val
+	}
+	val, err := r.eth.GetStorageAt(addr, key, r.block)
+	if err != nil {
+		r.setErr(err)
+	}
+	acct.storage[key] = val
+	return val
+}
+
+// SetState implements the State interface
+func (r *RemoteState) SetState(addr ethgo.Address, key, value ethgo.Hash) {
+	acct := r.account(addr)
+	prev := r.GetState(addr, key)
+	acct.storage[key] = value
+	r.journal = append(r.journal, func() {
+		acct.storage[key] = prev
+	})
+}
+
+// DeleteAccount implements the State interface
+func (r *RemoteState) DeleteAccount(addr ethgo.Address) {
+	prev := r.account(addr)
+	r.accounts[addr] = &remoteAccount{
+		balance: new(big.Int),
+		storage: map[ethgo.Hash]ethgo.Hash{},
+		deleted: true,
+	}
+	r.journal = append(r.journal, func() {
+		r.accounts[addr] = prev
+	})
+}
+
+// Snapshot implements the State interface
+func (r *RemoteState) Snapshot() int {
+	return len(r.journal)
+}
+
+// RevertToSnapshot implements the State interface
+func (r *RemoteState) RevertToSnapshot(id int) {
+	for i := len(r.journal) - 1; i >= id; i-- {
+		r.journal[i]()
+	}
+	r.journal = r.journal[:id]
+}
+
+// NewRemoteBlockContext returns the context of the block from the node.
+// The hashes of the previous blocks are fetched on demand.
+func NewRemoteBlockContext(eth *jsonrpc.Eth, num ethgo.BlockNumber) (*BlockContext, error) {
+	block, err := eth.GetBlockByNumber(num, false)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", num.String())
+	}
+	chainID, err := eth.ChainID()
+	if err != nil {
+		return nil, err
+	}
+	ctx := &BlockContext{
+		Coinbase:   block.Miner,
+		GasLimit:   block.GasLimit,
+		Number:     block.Number,
+		Timestamp:  block.Timestamp,
+		Difficulty: block.Difficulty,
+		ChainID:    chainID,
+		GetHash: func(num uint64) ethgo.Hash {
+			b, err := eth.GetBlockByNumber(ethgo.BlockNumber(num), false)
+			if err != nil || b == nil {
+				return ethgo.Hash{}
+			}
+			return b.Hash
+		},
+	}
+	return ctx, nil
+}
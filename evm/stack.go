@@ -0,0 +1,109 @@
+package evm
+
+import (
+	"math/big"
+)
+
+const stackLimit = 1024
+
+var (
+	tt255   = new(big.Int).Lsh(big.NewInt(1), 255)
+	tt256   = new(big.Int).Lsh(big.NewInt(1), 256)
+	tt256m1 = new(big.Int).Sub(tt256, big.NewInt(1))
+)
+
+// u256 wraps the value to 256 bits
+func u256(x *big.Int) *big.Int {
+	return x.And(x, tt256m1)
+}
+
+// s256 interprets the 256 bits value as a signed integer
+func s256(x *big.Int) *big.Int {
+	if x.Cmp(tt255) < 0 {
+		return x
+	}
+	return new(big.Int).Sub(x, tt256)
+}
+
+// stack is the stack of 256 bits words of a call
+type stack struct {
+	data []*big.Int
+}
+
+func newStack() *stack {
+	return &stack{data: make([]*big.Int, 0, 16)}
+}
+
+func (s *stack) len() int {
+	return len(s.data)
+}
+
+func (s *stack) push(v *big.Int) {
+	s.data = append(s.data, v)
+}
+
+func (s *stack) pop() *big.Int {
+	v := s.data[len(s.data)-1]
+	s.data = s.data[:len(s.data)-1]
+	return v
+}
+
+// peek returns the n-th element from the top of the stack
+func (s *stack) peek(n int) *big.Int {
+	return s.data[len(s.data)-1-n]
+}
+
+func (s *stack) dup(n int) {
+	s.push(new(big.Int).Set(s.data[len(s.data)-n]))
+}
+
+func (s *stack) swap(n int) {
+	top := len(s.data) - 1
+	s.data[top], s.data[top-n] = s.data[top-n], s.data[top]
+}
+
+// memory is the memory of a call
+type memory struct {
+	data []byte
+
+	// lastGasCost is the gas paid for the current size of the memory
+	lastGasCost uint64
+}
+
+func (m *memory) len() int {
+	return len(m.data)
+}
+
+func (m *memory) resize(size uint64) {
+	if uint64(len(m.data)) < size {
+		m.data = append(m.data, make([]byte, size-uint64(len(m.data)))...)
+	}
+}
+
+// set copies the value to the memory. The memory must be resized before.
+func (m *memory) set(offset, size uint64, value []byte) {
+	if size == 0 {
+		return
+	}
+	copy(m.data[offset:offset+size], value)
+}
+
+// set32 writes the value as a 32 bytes word
+func (m *memory) set32(offset uint64, val *big.Int) {
+	word := m.data[offset : offset+32]
+	for i := range word {
+		word[i] = 0
+	}
+	b := val.Bytes()
+	copy(word[32-len(b):], b)
+}
+
+// get returns a copy of the memory region
+func (m *memory) get(offset, size int64) []byte {
+	if size == 0 {
+		return nil
+	}
+	res := make([]byte, size)
+	copy(res, m.data[offset:offset+size])
+	return res
+}
@@ -0,0 +1,101 @@
+package evm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc"
+)
+
+// Tracer is called during the execution of a message
+type Tracer interface {
+	// CaptureStart is called before the execution of the message
+	CaptureStart(evm *EVM, from, to ethgo.Address, create bool, input []byte, gas uint64, value *big.Int)
+
+	// CaptureState is called before the execution of each instruction
+	CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int)
+
+	// CaptureEnd is called after the execution of the message
+	CaptureEnd(output []byte, gasUsed uint64, err error)
+}
+
+// StructLogger is a tracer that records the instructions executed with
+// the same format as the default tracer of debug_traceTransaction
+type StructLogger struct {
+	evm     *EVM
+	trace   *jsonrpc.TransactionTrace
+	storage map[ethgo.Address]map[string]string
+}
+
+// NewStructLogger creates a new struct logger
+func NewStructLogger() *StructLogger {
+	return &StructLogger{
+		trace:   &jsonrpc.TransactionTrace{StructLogs: []*jsonrpc.StructLogs{}},
+		storage: map[ethgo.Address]map[string]string{},
+	}
+}
+
+// Trace returns the trace of the execution
+func (l *StructLogger) Trace() *jsonrpc.TransactionTrace {
+	return l.trace
+}
+
+// CaptureStart implements the Tracer interface
+func (l *StructLogger) CaptureStart(evm *EVM, from, to ethgo.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.evm = evm
+}
+
+// CaptureState implements the Tracer interface
+func (l *StructLogger) CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int) {
+	stack := scope.Stack()
+
+	log := &jsonrpc.StructLogs{
+		Depth:   depth,
+		Gas:     int(gas),
+		GasCost: int(cost),
+		Op:      op.String(),
+		Pc:      int(pc),
+		Memory:  []string{},
+		Stack:   make([]string, 0, len(stack)),
+	}
+	for _, v := range stack {
+		log.Stack = append(log.Stack, fmt.Sprintf("0x%x", v))
+	}
+	mem := scope.Memory()
+	for i := 0; i+32 <= len(mem); i += 32 {
+		log.Memory = append(log.Memory, hex.EncodeToString(mem[i:i+32]))
+	}
+
+	if op == SLOAD || op == SSTORE {
+		addr := scope.Contract.Address
+		storage, ok := l.storage[addr]
+		if !ok {
+			storage = map[string]string{}
+			l.storage[addr] = storage
+		}
+
+		slot := bigToHash(stack[len(stack)-1])
+		var value ethgo.Hash
+		if op == SLOAD {
+			value = l.evm.State.GetState(addr, slot)
+		} else {
+			value = bigToHash(stack[len(stack)-2])
+		}
+		storage[hex.EncodeToString(slot[:])] = hex.EncodeToString(value[:])
+
+		log.Storage = make(map[string]string, len(storage))
+		for k, v := range storage {
+			log.Storage[k] = v
+		}
+	}
+	l.trace.StructLogs = append(l.trace.StructLogs, log)
+}
+
+// CaptureEnd implements the Tracer interface
+func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	l.trace.Gas = gasUsed
+	l.trace.Failed = err != nil
+	l.trace.ReturnValue = hex.EncodeToString(output)
+}
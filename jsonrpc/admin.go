@@ -0,0 +1,115 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Admin is the admin namespace (geth)
+type Admin struct {
+	c   *Client
+	ctx context.Context
+}
+
+// Admin returns the reference to the admin namespace
+func (c *Client) Admin() *Admin {
+	return c.endpoints.a
+}
+
+// WithContext returns a copy of the admin namespace whose calls are bound to ctx
+func (a *Admin) WithContext(ctx context.Context) *Admin {
+	a2 := *a
+	a2.ctx = ctx
+	return &a2
+}
+
+// PeerNetwork is the connection of a peer
+type PeerNetwork struct {
+	LocalAddress  string `json:"localAddress"`
+	RemoteAddress string `json:"remoteAddress"`
+	Inbound       bool   `json:"inbound"`
+	Trusted       bool   `json:"trusted"`
+	Static        bool   `json:"static"`
+}
+
+// PeerInfo is a peer connected to the node
+type PeerInfo struct {
+	ENR     string       `json:"enr"`
+	Enode   string       `json:"enode"`
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Caps    []string     `json:"caps"`
+	Network *PeerNetwork `json:"network"`
+
+	// Protocols is the state of the peer for each protocol (i.e. eth, snap)
+	Protocols map[string]json.RawMessage `json:"protocols"`
+}
+
+// NodePorts are the ports of the node
+type NodePorts struct {
+	Discovery int `json:"discovery"`
+	Listener  int `json:"listener"`
+}
+
+// NodeInfo is the information of the node
+type NodeInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Enode      string     `json:"enode"`
+	ENR        string     `json:"enr"`
+	IP         string     `json:"ip"`
+	Ports      *NodePorts `json:"ports"`
+	ListenAddr string     `json:"listenAddr"`
+
+	// Protocols is the state of the node for each protocol (i.e. the eth genesis and head)
+	Protocols map[string]json.RawMessage `json:"protocols"`
+}
+
+// Peers returns the peers connected to the node
+func (a *Admin) Peers() ([]*PeerInfo, error) {
+	var out []*PeerInfo
+	err := a.c.CallContext(a.ctx, "admin_peers", &out)
+	return out, err
+}
+
+// NodeInfo returns the information of the node
+func (a *Admin) NodeInfo() (*NodeInfo, error) {
+	var out *NodeInfo
+	err := a.c.CallContext(a.ctx, "admin_nodeInfo", &out)
+	return out, err
+}
+
+// AddPeer connects to the node with the enode url and keeps the connection
+func (a *Admin) AddPeer(url string) (bool, error) {
+	var out bool
+	err := a.c.CallContext(a.ctx, "admin_addPeer", &out, url)
+	return out, err
+}
+
+// RemovePeer disconnects from the node with the enode url
+func (a *Admin) RemovePeer(url string) (bool, error) {
+	var out bool
+	err := a.c.CallContext(a.ctx, "admin_removePeer", &out, url)
+	return out, err
+}
+
+// AddTrustedPeer allows the node with the enode url to connect even if the peer slots are full
+func (a *Admin) AddTrustedPeer(url string) (bool, error) {
+	var out bool
+	err := a.c.CallContext(a.ctx, "admin_addTrustedPeer", &out, url)
+	return out, err
+}
+
+// RemoveTrustedPeer removes the node with the enode url from the trusted peers
+func (a *Admin) RemoveTrustedPeer(url string) (bool, error) {
+	var out bool
+	err := a.c.CallContext(a.ctx, "admin_removeTrustedPeer", &out, url)
+	return out, err
+}
+
+// Datadir returns the data directory of the node
+func (a *Admin) Datadir() (string, error) {
+	var out string
+	err := a.c.CallContext(a.ctx, "admin_datadir", &out)
+	return out, err
+}
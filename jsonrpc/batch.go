@@ -0,0 +1,107 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// BatchElem is a single call in a batch request
+type BatchElem = transport.BatchElem
+
+// BatchCall sends all the calls in a single round trip. The result and the
+// error of each call are set on its element, the error returned is only
+// set if the whole batch failed.
+func (c *Client) BatchCall(b []BatchElem) error {
+	return c.BatchCallContext(context.Background(), b)
+}
+
+// BatchCallContext sends a batch request that is aborted if the context
+// is cancelled or its deadline expires
+func (c *Client) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	if len(b) == 0 {
+		return nil
+	}
+	var err error
+	if len(c.middlewares) != 0 {
+		_, err = c.intercept(func(ctx context.Context, req *Request) (json.RawMessage, error) {
+			return nil, c.batchCall(ctx, req.Batch)
+		})(ctx, &Request{Batch: b})
+	} else {
+		err = c.batchCall(ctx, b)
+	}
+	for i := range b {
+		b[i].Error = wrapError(b[i].Error)
+	}
+	return err
+}
+
+func (c *Client) batchCall(ctx context.Context, b []BatchElem) error {
+	batch, ok := c.transport.(transport.BatchTransport)
+	if !ok {
+		// the transport does not support batches, send the calls one by one
+		for i := range b {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			b[i].Error = c.transport.CallContext(ctx, b[i].Method, b[i].Result, b[i].Params...)
+		}
+		return nil
+	}
+	return batch.BatchCallContext(ctx, b)
+}
+
+// batchErr returns the first error of the batch elements
+func batchErr(b []BatchElem) error {
+	for i, elem := range b {
+		if elem.Error != nil {
+			return fmt.Errorf("batch call %d (%s) failed: %w", i, elem.Method, elem.Error)
+		}
+	}
+	return nil
+}
+
+// GetTransactionReceipts returns the receipts of the transactions in a single batch request.
+// A receipt is nil if the transaction is not found.
+func (e *Eth) GetTransactionReceipts(hashes []ethgo.Hash) ([]*ethgo.Receipt, error) {
+	receipts := make([]*ethgo.Receipt, len(hashes))
+	b := make([]BatchElem, len(hashes))
+	for i, hash := range hashes {
+		b[i] = BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Params: []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+	if err := e.c.BatchCallContext(e.ctx, b); err != nil {
+		return nil, err
+	}
+	if err := batchErr(b); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// GetBlocksByNumber returns the blocks by block number in a single batch request.
+// A block is nil if it is not found.
+func (e *Eth) GetBlocksByNumber(nums []ethgo.BlockNumber, full bool) ([]*ethgo.Block, error) {
+	blocks := make([]*ethgo.Block, len(nums))
+	b := make([]BatchElem, len(nums))
+	for i, num := range nums {
+		b[i] = BatchElem{
+			Method: "eth_getBlockByNumber",
+			Params: []interface{}{num.String(), full},
+			Result: &blocks[i],
+		}
+	}
+	if err := e.c.BatchCallContext(e.ctx, b); err != nil {
+		return nil, err
+	}
+	if err := batchErr(b); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
@@ -0,0 +1,70 @@
+package jsonrpc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+	"github.com/umbracle/ethgo/testutil"
+)
+
+func TestBatchCall(t *testing.T) {
+	testutil.MultiAddr(t, nil, func(s *testutil.TestServer, addr string) {
+		c, _ := NewClient(addr)
+		defer c.Close()
+
+		var num string
+		var block *ethgo.Block
+		b := []BatchElem{
+			{Method: "eth_blockNumber", Result: &num},
+			{Method: "eth_getBlockByNumber", Params: []interface{}{"0x0", false}, Result: &block},
+			{Method: "eth_unknownMethod"},
+		}
+		assert.NoError(t, c.BatchCall(b))
+
+		assert.NoError(t, b[0].Error)
+		assert.NotEmpty(t, num)
+		assert.NoError(t, b[1].Error)
+		assert.Equal(t, uint64(0), block.Number)
+		assert.Error(t, b[2].Error)
+	})
+}
+
+func TestBatchErr(t *testing.T) {
+	b := []BatchElem{
+		{Method: "eth_blockNumber"},
+		{Method: "eth_getBlockByNumber", Error: &codec.ErrorObject{Code: -32000, Message: "not found"}},
+	}
+	err := batchErr(b)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	var obj *codec.ErrorObject
+	assert.True(t, errors.As(err, &obj))
+	assert.Equal(t, -32000, obj.Code)
+
+	assert.NoError(t, batchErr(b[:1]))
+}
+
+func TestEthGetTransactionReceipts(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	r0 := s.Transfer(ethgo.Address{0x1}, big.NewInt(10))
+	r1 := s.Transfer(ethgo.Address{0x2}, big.NewInt(10))
+
+	receipts, err := c.Eth().GetTransactionReceipts([]ethgo.Hash{r0.TransactionHash, {0x1}, r1.TransactionHash})
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 3)
+	assert.Equal(t, r0.TransactionHash, receipts[0].TransactionHash)
+	assert.Nil(t, receipts[1])
+	assert.Equal(t, r1.TransactionHash, receipts[2].TransactionHash)
+
+	blocks, err := c.Eth().GetBlocksByNumber([]ethgo.BlockNumber{0, ethgo.BlockNumber(r1.BlockNumber)}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, r1.BlockHash, blocks[1].Hash)
+}
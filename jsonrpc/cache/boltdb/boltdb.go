@@ -0,0 +1,60 @@
+package cacheboltdb
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/umbracle/ethgo/jsonrpc/cache"
+)
+
+var _ cache.Storage = (*BoltStorage)(nil)
+
+var dbCache = []byte("cache")
+
+// BoltStorage is a cache storage implementation on top of boltdb
+type BoltStorage struct {
+	conn *bolt.DB
+}
+
+// New creates a new boltdb storage
+func New(path string) (*BoltStorage, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(txn *bolt.Tx) error {
+		_, err := txn.CreateBucketIfNotExists(dbCache)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStorage{conn: db}, nil
+}
+
+// Get implements the storage interface
+func (b *BoltStorage) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	err := b.conn.View(func(txn *bolt.Tx) error {
+		if val := txn.Bucket(dbCache).Get([]byte(key)); val != nil {
+			// the value is only valid during the transaction
+			value = append([]byte{}, val...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return value, value != nil, nil
+}
+
+// Set implements the storage interface
+func (b *BoltStorage) Set(key string, value []byte) error {
+	return b.conn.Update(func(txn *bolt.Tx) error {
+		return txn.Bucket(dbCache).Put([]byte(key), value)
+	})
+}
+
+// Close implements the storage interface
+func (b *BoltStorage) Close() error {
+	return b.conn.Close()
+}
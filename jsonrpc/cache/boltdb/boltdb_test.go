@@ -0,0 +1,93 @@
+package cacheboltdb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc"
+	"github.com/umbracle/ethgo/jsonrpc/cache"
+)
+
+func TestBoltStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ethgo-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cache.db")
+
+	b, err := New(path)
+	assert.NoError(t, err)
+
+	_, ok, err := b.Get("a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, b.Set("a", []byte("b")))
+	assert.NoError(t, b.Close())
+
+	// the values persist
+	b, err = New(path)
+	assert.NoError(t, err)
+	defer b.Close()
+
+	val, ok, err := b.Get("a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("b"), val)
+}
+
+// versionTransport returns its version for net_version
+type versionTransport struct {
+	version string
+	calls   int
+}
+
+func (v *versionTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return v.CallContext(context.Background(), method, out, params...)
+}
+
+func (v *versionTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	v.calls++
+	return json.Unmarshal([]byte(`"`+v.version+`"`), out)
+}
+
+func (v *versionTransport) SetMaxConnsPerHost(count int) {}
+
+func (v *versionTransport) Close() error { return nil }
+
+func (v *versionTransport) IsClosed() bool { return false }
+
+func TestBoltStorage_Namespace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ethgo-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cache.db")
+
+	// version opens the file with a cache in the namespace
+	version := func(namespace string, tt *versionTransport) string {
+		b, err := New(path)
+		assert.NoError(t, err)
+
+		c := jsonrpc.NewClientWithTransport(cache.NewTransport(tt, &cache.Config{Storage: b, Namespace: namespace}))
+		defer c.Close()
+
+		var version string
+		assert.NoError(t, c.Call("net_version", &version))
+		return version
+	}
+
+	assert.Equal(t, "1", version("mainnet", &versionTransport{version: "1"}))
+	assert.Equal(t, "5", version("goerli", &versionTransport{version: "5"}))
+
+	// both responses persist in their own namespace
+	tt := &versionTransport{}
+	assert.Equal(t, "1", version("mainnet", tt))
+	assert.Equal(t, "5", version("goerli", tt))
+	assert.Equal(t, 0, tt.calls)
+}
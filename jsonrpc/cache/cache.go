@@ -0,0 +1,408 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/blocktracker"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// Config is the configuration of the cache
+type Config struct {
+	// Storage stores the immutable responses. If nil, an in-memory LRU is used.
+	Storage Storage
+
+	// Confirmations is the number of blocks on top of a block before its
+	// data is considered final and cached forever
+	Confirmations uint64
+
+	// HeadSize is the number of head-relative responses (i.e. 'latest')
+	// cached until the next block
+	HeadSize int
+
+	// Namespace prefixes the keys of the responses so that a storage shared
+	// between nodes of different chains does not mix them. If empty, it is
+	// derived from the chain id and the genesis hash on the first use.
+	Namespace string
+}
+
+// DefaultConfig returns the default configuration of the cache
+func DefaultConfig() *Config {
+	return &Config{
+		Confirmations: 64,
+		HeadSize:      1024,
+	}
+}
+
+// Transport is a transport that caches the responses of the wrapped transport.
+// The immutable responses (i.e. blocks by hash or calls at a final block) are
+// cached forever. The responses relative to the head (i.e. calls at 'latest') are
+// cached until the next block, which requires the head to be tracked with
+// Track or HandleBlockEvent. Until then, those responses are not cached.
+type Transport struct {
+	transport transport.Transport
+
+	config  *Config
+	storage Storage
+	head    *LRU
+
+	// namespace is the prefix of the keys, it is derived on the first
+	// cacheable request if it is not set in the config
+	namespace     string
+	namespaceLock sync.Mutex
+	// resolving is true while a request derives the namespace
+	resolving bool
+	// namespaceRetry is the time after which a failed namespace is derived again
+	namespaceRetry time.Time
+
+	lock sync.Mutex
+	// chain is the head of the chain
+	chain chainHead
+	// generation changes with every head so that the responses of
+	// the requests that started before are not cached
+	generation uint64
+}
+
+// NewTransport wraps the transport with a cache
+func NewTransport(t transport.Transport, config *Config) *Transport {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	storage := config.Storage
+	if storage == nil {
+		storage = NewLRU(4096)
+	}
+	headSize := config.HeadSize
+	if headSize == 0 {
+		headSize = DefaultConfig().HeadSize
+	}
+	return &Transport{
+		transport: t,
+		config:    config,
+		storage:   storage,
+		head:      NewLRU(headSize),
+		namespace: config.Namespace,
+		chain:     chainHead{confirmations: config.Confirmations},
+	}
+}
+
+// Close implements the transport interface
+func (t *Transport) Close() error {
+	if err := t.storage.Close(); err != nil {
+		t.transport.Close()
+		return err
+	}
+	return t.transport.Close()
+}
+
+// IsClosed implements the transport interface
+func (t *Transport) IsClosed() bool {
+	return t.transport.IsClosed()
+}
+
+// SetMaxConnsPerHost implements the transport interface
+func (t *Transport) SetMaxConnsPerHost(count int) {
+	t.transport.SetMaxConnsPerHost(count)
+}
+
+// Track invalidates the cache with the blocks of the tracker.
+// It returns a function to stop tracking.
+func (t *Transport) Track(tracker *blocktracker.BlockTracker) func() {
+	ch := tracker.Subscribe()
+	closeCh := make(chan struct{})
+	var closeOnce sync.Once
+
+	go func() {
+		for {
+			select {
+			case ev := <-ch:
+				t.HandleBlockEvent(ev)
+			case <-closeCh:
+				return
+			}
+		}
+	}()
+	return func() {
+		closeOnce.Do(func() {
+			close(closeCh)
+		})
+	}
+}
+
+// HandleBlockEvent moves the head of the cache to the last added block and
+// drops the head-relative responses. The removed blocks of a reorg drop them too.
+func (t *Transport) HandleBlockEvent(ev *blocktracker.BlockEvent) {
+	if len(ev.Added) == 0 && len(ev.Removed) == 0 {
+		return
+	}
+
+	t.lock.Lock()
+	if len(ev.Added) != 0 {
+		t.chain.number = ev.Added[len(ev.Added)-1].Number
+		t.chain.known = true
+	}
+	t.generation++
+	t.head.Purge()
+	t.lock.Unlock()
+}
+
+// state returns the head of the chain and its generation
+func (t *Transport) state() (chainHead, uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.chain, t.generation
+}
+
+// namespaceBackoff is the time to wait to derive the namespace again after it fails
+const namespaceBackoff = 5 * time.Second
+
+// getNamespace returns the namespace of the keys. If it is not configured, it
+// is derived from the chain id and the genesis hash of the wrapped transport.
+// The requests are not cached while the namespace is being derived or if it failed.
+func (t *Transport) getNamespace(ctx context.Context) (string, bool) {
+	t.namespaceLock.Lock()
+	if t.namespace != "" {
+		namespace := t.namespace
+		t.namespaceLock.Unlock()
+		return namespace, true
+	}
+	if t.resolving || time.Now().Before(t.namespaceRetry) {
+		t.namespaceLock.Unlock()
+		return "", false
+	}
+	t.resolving = true
+	t.namespaceLock.Unlock()
+
+	namespace, chainID, err := t.resolveNamespace(ctx)
+
+	t.namespaceLock.Lock()
+	t.resolving = false
+	if err != nil {
+		if ctx.Err() == nil {
+			t.namespaceRetry = time.Now().Add(namespaceBackoff)
+		}
+		t.namespaceLock.Unlock()
+		return "", false
+	}
+	t.namespace = namespace
+	t.namespaceLock.Unlock()
+
+	// the chain id is immutable, keep the response
+	if req := t.newRequestWithNamespace(namespace, "eth_chainId", nil); req != nil {
+		t.set(req, chainID)
+	}
+	return namespace, true
+}
+
+// resolveNamespace derives the namespace from the chain id and the genesis hash
+func (t *Transport) resolveNamespace(ctx context.Context) (string, json.RawMessage, error) {
+	var chainID json.RawMessage
+	if err := t.transport.CallContext(ctx, "eth_chainId", &chainID); err != nil {
+		return "", nil, err
+	}
+	var id string
+	if err := json.Unmarshal(chainID, &id); err != nil {
+		return "", nil, err
+	}
+	if id == "" {
+		return "", nil, fmt.Errorf("empty chain id")
+	}
+	var genesis *struct {
+		Hash ethgo.Hash `json:"hash"`
+	}
+	if err := t.transport.CallContext(ctx, "eth_getBlockByNumber", &genesis, "0x0", false); err != nil {
+		return "", nil, err
+	}
+	if genesis == nil {
+		return "", nil, fmt.Errorf("genesis block not found")
+	}
+	return id + ":" + genesis.Hash.String(), chainID, nil
+}
+
+// request is a cacheable request
+type request struct {
+	key        string
+	lifetime   lifetime
+	chain      chainHead
+	generation uint64
+}
+
+// newRequest classifies the request. It returns nil if it cannot be cached.
+func (t *Transport) newRequest(ctx context.Context, method string, params []interface{}) *request {
+	if _, ok := rules[method]; !ok {
+		return nil
+	}
+	namespace, ok := t.getNamespace(ctx)
+	if !ok {
+		return nil
+	}
+	return t.newRequestWithNamespace(namespace, method, params)
+}
+
+func (t *Transport) newRequestWithNamespace(namespace string, method string, params []interface{}) *request {
+	rule, ok := rules[method]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	chain, generation := t.state()
+	l := rule(raw, chain)
+	if l == noCache {
+		return nil
+	}
+	return &request{
+		key:        namespace + ":" + method + ":" + string(data),
+		lifetime:   l,
+		chain:      chain,
+		generation: generation,
+	}
+}
+
+// get returns the cached response of the request
+func (t *Transport) get(req *request) (json.RawMessage, bool) {
+	if val, ok, err := t.storage.Get(req.key); err == nil && ok {
+		return val, true
+	}
+	if req.lifetime == forever {
+		return nil, false
+	}
+	if val, ok, _ := t.head.Get(req.key); ok {
+		return val, true
+	}
+	return nil, false
+}
+
+// set caches the response of the request
+func (t *Transport) set(req *request, raw json.RawMessage) {
+	l := req.lifetime
+	if l == byResult {
+		l = resultLifetime(raw, req.chain)
+	}
+	if l == forever && string(raw) == "null" {
+		// the resource may exist later on
+		l = req.chain.headRelative()
+	}
+
+	switch l {
+	case forever:
+		t.storage.Set(req.key, raw)
+
+	case untilNextBlock:
+		t.lock.Lock()
+		if t.generation == req.generation {
+			t.head.Set(req.key, raw)
+		}
+		t.lock.Unlock()
+	}
+}
+
+// Call implements the transport interface
+func (t *Transport) Call(method string, out interface{}, params ...interface{}) error {
+	return t.call(context.Background(), method, out, params, func(out interface{}) error {
+		return t.transport.Call(method, out, params...)
+	})
+}
+
+// CallContext implements the transport interface
+func (t *Transport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	return t.call(ctx, method, out, params, func(out interface{}) error {
+		return t.transport.CallContext(ctx, method, out, params...)
+	})
+}
+
+func (t *Transport) call(ctx context.Context, method string, out interface{}, params []interface{}, call func(out interface{}) error) error {
+	req := t.newRequest(ctx, method, params)
+	if req == nil {
+		return call(out)
+	}
+	if raw, ok := t.get(req); ok {
+		return decode(raw, out)
+	}
+
+	var raw json.RawMessage
+	if err := call(&raw); err != nil {
+		return err
+	}
+	t.set(req, raw)
+	return decode(raw, out)
+}
+
+func decode(raw json.RawMessage, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// BatchCallContext implements the BatchTransport interface. Only the
+// elements that are not cached are sent to the wrapped transport.
+func (t *Transport) BatchCallContext(ctx context.Context, b []transport.BatchElem) error {
+	reqs := make([]*request, len(b))
+	raws := make([]json.RawMessage, len(b))
+
+	pending := []transport.BatchElem{}
+	indexes := []int{}
+	for i, elem := range b {
+		reqs[i] = t.newRequest(ctx, elem.Method, elem.Params)
+		if reqs[i] != nil {
+			if raw, ok := t.get(reqs[i]); ok {
+				b[i].Error = decode(raw, elem.Result)
+				continue
+			}
+		}
+		pending = append(pending, transport.BatchElem{
+			Method: elem.Method,
+			Params: elem.Params,
+			Result: &raws[i],
+		})
+		indexes = append(indexes, i)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if batch, ok := t.transport.(transport.BatchTransport); ok {
+		if err := batch.BatchCallContext(ctx, pending); err != nil {
+			return err
+		}
+	} else {
+		for i := range pending {
+			pending[i].Error = t.transport.CallContext(ctx, pending[i].Method, pending[i].Result, pending[i].Params...)
+		}
+	}
+
+	for j, i := range indexes {
+		if pending[j].Error != nil {
+			b[i].Error = pending[j].Error
+			continue
+		}
+		if reqs[i] != nil {
+			t.set(reqs[i], raws[i])
+		}
+		b[i].Error = decode(raws[i], b[i].Result)
+	}
+	return nil
+}
+
+// Subscribe implements the PubSubTransport interface
+func (t *Transport) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	pub, ok := t.transport.(transport.PubSubTransport)
+	if !ok {
+		return nil, fmt.Errorf("transport does not support the subscribe method")
+	}
+	return pub.Subscribe(method, params, callback)
+}
@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/blocktracker"
+	"github.com/umbracle/ethgo/jsonrpc"
+)
+
+// countTransport returns the number of calls of a method as its result
+type countTransport struct {
+	calls   map[string]int
+	results map[string]string
+}
+
+func newCountTransport() *countTransport {
+	results := map[string]string{
+		// the genesis block derives the namespace of the cache
+		"eth_getBlockByNumber": `{"number": "0x0", "hash": "0x0100000000000000000000000000000000000000000000000000000000000000"}`,
+	}
+	return &countTransport{calls: map[string]int{}, results: results}
+}
+
+func (c *countTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return c.CallContext(context.Background(), method, out, params...)
+}
+
+func (c *countTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	c.calls[method]++
+
+	result, ok := c.results[method]
+	if !ok {
+		result = fmt.Sprintf(`"0x%x"`, c.calls[method])
+	}
+	return json.Unmarshal([]byte(result), out)
+}
+
+func (c *countTransport) SetMaxConnsPerHost(count int) {}
+
+func (c *countTransport) Close() error { return nil }
+
+func (c *countTransport) IsClosed() bool { return false }
+
+func newBlockEvent(num uint64) *blocktracker.BlockEvent {
+	return &blocktracker.BlockEvent{
+		Added: []*ethgo.Block{{Number: num, Hash: ethgo.Hash{byte(num)}}},
+	}
+}
+
+func TestCache_Immutable(t *testing.T) {
+	tt := newCountTransport()
+	c := jsonrpc.NewClientWithTransport(NewTransport(tt, nil))
+
+	for i := 0; i < 3; i++ {
+		chainID, err := c.Eth().ChainID()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), chainID.Uint64())
+	}
+	assert.Equal(t, 1, tt.calls["eth_chainId"])
+
+	// the head is not known, the head-relative calls are not cached
+	for i := 0; i < 3; i++ {
+		_, err := c.Eth().BlockNumber()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, tt.calls["eth_blockNumber"])
+
+	// pending is never cached
+	for i := 0; i < 3; i++ {
+		_, err := c.Eth().GetBalance(ethgo.Address{}, ethgo.Pending)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, tt.calls["eth_getBalance"])
+}
+
+func TestCache_Head(t *testing.T) {
+	tt := newCountTransport()
+	cache := NewTransport(tt, &Config{Confirmations: 10})
+	c := jsonrpc.NewClientWithTransport(cache)
+
+	cache.HandleBlockEvent(newBlockEvent(100))
+
+	num, err := c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), num)
+
+	num, err = c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), num)
+
+	// the balance at the final block is cached forever and
+	// the one at a recent block only until the next block
+	_, err = c.Eth().GetBalance(ethgo.Address{}, ethgo.BlockNumber(90))
+	assert.NoError(t, err)
+	_, err = c.Eth().GetBalance(ethgo.Address{}, ethgo.BlockNumber(95))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tt.calls["eth_getBalance"])
+
+	// a new head drops the head-relative responses
+	cache.HandleBlockEvent(newBlockEvent(101))
+
+	num, err = c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), num)
+
+	_, err = c.Eth().GetBalance(ethgo.Address{}, ethgo.BlockNumber(90))
+	assert.NoError(t, err)
+	_, err = c.Eth().GetBalance(ethgo.Address{}, ethgo.BlockNumber(95))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tt.calls["eth_getBalance"])
+
+	// a reorg drops them too
+	cache.HandleBlockEvent(&blocktracker.BlockEvent{
+		Removed: []*ethgo.Block{{Number: 101}},
+		Added:   []*ethgo.Block{{Number: 101}},
+	})
+
+	num, err = c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), num)
+}
+
+func TestCache_Receipt(t *testing.T) {
+	tt := newCountTransport()
+	cache := NewTransport(tt, &Config{Confirmations: 10})
+	c := jsonrpc.NewClientWithTransport(cache)
+
+	cache.HandleBlockEvent(newBlockEvent(100))
+
+	// the receipt of a pending transaction is not cached
+	tt.results["eth_getTransactionReceipt"] = "null"
+
+	for i := 0; i < 2; i++ {
+		receipt, err := c.Eth().GetTransactionReceipt(ethgo.Hash{})
+		assert.NoError(t, err)
+		assert.Nil(t, receipt)
+	}
+	assert.Equal(t, 2, tt.calls["eth_getTransactionReceipt"])
+
+	tt.results["eth_getTransactionReceipt"] = `{"blockNumber": "0x5a", "logs": []}`
+
+	for i := 0; i < 2; i++ {
+		var receipt map[string]interface{}
+		assert.NoError(t, c.Call("eth_getTransactionReceipt", &receipt, ethgo.Hash{}))
+		assert.Equal(t, "0x5a", receipt["blockNumber"])
+	}
+	assert.Equal(t, 3, tt.calls["eth_getTransactionReceipt"])
+}
+
+func TestCache_Batch(t *testing.T) {
+	tt := newCountTransport()
+	c := jsonrpc.NewClientWithTransport(NewTransport(tt, nil))
+
+	var chainID, version string
+	assert.NoError(t, c.Call("eth_chainId", &chainID))
+
+	b := []jsonrpc.BatchElem{
+		{Method: "eth_chainId", Result: &chainID},
+		{Method: "web3_clientVersion", Result: &version},
+	}
+	assert.NoError(t, c.BatchCall(b))
+	assert.NoError(t, b[0].Error)
+	assert.NoError(t, b[1].Error)
+	assert.Equal(t, "0x1", chainID)
+	assert.Equal(t, "0x1", version)
+
+	assert.Equal(t, 1, tt.calls["eth_chainId"])
+	assert.Equal(t, 1, tt.calls["web3_clientVersion"])
+}
+
+func TestCache_Namespace(t *testing.T) {
+	storage := NewLRU(100)
+
+	version := func(tt *countTransport, config *Config) string {
+		config.Storage = storage
+		c := jsonrpc.NewClientWithTransport(NewTransport(tt, config))
+
+		var version string
+		assert.NoError(t, c.Call("net_version", &version))
+		return version
+	}
+
+	// the namespace is derived from the chain id and the genesis hash
+	tt := newCountTransport()
+	tt.results["net_version"] = `"1"`
+	assert.Equal(t, "1", version(tt, &Config{}))
+	assert.Equal(t, 1, tt.calls["eth_chainId"])
+	assert.Equal(t, 1, tt.calls["eth_getBlockByNumber"])
+
+	tt = newCountTransport()
+	assert.Equal(t, "1", version(tt, &Config{}))
+	assert.Equal(t, 0, tt.calls["net_version"])
+
+	// another chain does not read the responses of the first one
+	tt = newCountTransport()
+	tt.results["eth_chainId"] = `"0x5"`
+	tt.results["net_version"] = `"5"`
+	assert.Equal(t, "5", version(tt, &Config{}))
+
+	tt = newCountTransport()
+	tt.results["eth_getBlockByNumber"] = `{"number": "0x0", "hash": "0x0200000000000000000000000000000000000000000000000000000000000000"}`
+	tt.results["net_version"] = `"2"`
+	assert.Equal(t, "2", version(tt, &Config{}))
+
+	// the configured namespace is used as it is
+	tt = newCountTransport()
+	tt.results["net_version"] = `"3"`
+	assert.Equal(t, "3", version(tt, &Config{Namespace: "a"}))
+	assert.Equal(t, 0, tt.calls["eth_chainId"])
+	assert.Equal(t, "3", version(newCountTransport(), &Config{Namespace: "a"}))
+}
+
+func TestCache_NamespaceError(t *testing.T) {
+	// the responses are not cached if the namespace cannot be derived
+	tt := newCountTransport()
+	tt.results["eth_getBlockByNumber"] = "null"
+	tr := NewTransport(tt, nil)
+	c := jsonrpc.NewClientWithTransport(tr)
+
+	var chainID string
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, c.Call("eth_chainId", &chainID))
+	}
+	// the namespace is not derived again until the backoff expires
+	assert.Equal(t, 3, tt.calls["eth_chainId"])
+	assert.Equal(t, 1, tt.calls["eth_getBlockByNumber"])
+
+	tr.namespaceLock.Lock()
+	tr.namespaceRetry = time.Time{}
+	tr.namespaceLock.Unlock()
+
+	// the namespace is derived once the genesis block is available
+	tt.results["eth_getBlockByNumber"] = newCountTransport().results["eth_getBlockByNumber"]
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, c.Call("eth_chainId", &chainID))
+	}
+	assert.Equal(t, 4, tt.calls["eth_chainId"])
+	assert.Equal(t, 2, tt.calls["eth_getBlockByNumber"])
+}
+
+func TestLRU(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("a", []byte("1"))
+	l.Set("b", []byte("2"))
+
+	// a is the most recently used
+	_, ok, _ := l.Get("a")
+	assert.True(t, ok)
+
+	l.Set("c", []byte("3"))
+	assert.Equal(t, 2, l.Len())
+
+	_, ok, _ = l.Get("b")
+	assert.False(t, ok)
+	_, ok, _ = l.Get("a")
+	assert.True(t, ok)
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// lifetime is how long a response can be cached
+type lifetime int
+
+const (
+	// noCache responses are never cached
+	noCache lifetime = iota
+	// untilNextBlock responses are cached until the head of the chain changes
+	untilNextBlock
+	// forever responses are immutable
+	forever
+	// byResult responses are classified by the block of the result
+	byResult
+)
+
+// chainHead is the head of the chain known by the cache
+type chainHead struct {
+	number        uint64
+	known         bool
+	confirmations uint64
+}
+
+// isFinal returns true if the block is deep enough to not be reorged
+func (h chainHead) isFinal(num uint64) bool {
+	return h.known && num+h.confirmations <= h.number
+}
+
+// headRelative returns untilNextBlock if the cache can be invalidated
+func (h chainHead) headRelative() lifetime {
+	if h.known {
+		return untilNextBlock
+	}
+	return noCache
+}
+
+// rule classifies a request by its params
+type rule func(params []json.RawMessage, h chainHead) lifetime
+
+func always(l lifetime) rule {
+	return func(params []json.RawMessage, h chainHead) lifetime {
+		if l == untilNextBlock {
+			return h.headRelative()
+		}
+		return l
+	}
+}
+
+// blockParam classifies a request by its block parameter at indx
+func blockParam(indx int) rule {
+	return func(params []json.RawMessage, h chainHead) lifetime {
+		if indx >= len(params) {
+			// the block defaults to latest
+			return h.headRelative()
+		}
+		return blockLifetime(params[indx], h)
+	}
+}
+
+// rules are the cacheable methods
+var rules = map[string]rule{
+	"eth_chainId": always(forever),
+	"net_version": always(forever),
+
+	"eth_blockNumber":          always(untilNextBlock),
+	"eth_gasPrice":             always(untilNextBlock),
+	"eth_maxPriorityFeePerGas": always(untilNextBlock),
+
+	"eth_getBlockByHash":                    always(forever),
+	"eth_getBlockTransactionCountByHash":    always(forever),
+	"eth_getUncleByBlockHashAndIndex":       always(forever),
+	"eth_getTransactionByBlockHashAndIndex": always(forever),
+
+	"eth_getTransactionByHash":  always(byResult),
+	"eth_getTransactionReceipt": always(byResult),
+
+	"eth_getBlockByNumber":                    blockParam(0),
+	"eth_getBlockTransactionCountByNumber":    blockParam(0),
+	"eth_getUncleByBlockNumberAndIndex":       blockParam(0),
+	"eth_getTransactionByBlockNumberAndIndex": blockParam(0),
+	"eth_getBlockReceipts":                    blockParam(0),
+	"eth_getBalance":                          blockParam(1),
+	"eth_getCode":                             blockParam(1),
+	"eth_getTransactionCount":                 blockParam(1),
+	"eth_call":                                blockParam(1),
+	"eth_getStorageAt":                        blockParam(2),
+	"eth_getProof":                            blockParam(2),
+
+	"eth_getLogs": logsLifetime,
+}
+
+// blockLifetime classifies a block tag, number, hash or EIP-1898 object
+func blockLifetime(raw json.RawMessage, h chainHead) lifetime {
+	var obj struct {
+		BlockHash   *string `json:"blockHash"`
+		BlockNumber *string `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		if obj.BlockHash != nil {
+			return forever
+		}
+		if obj.BlockNumber != nil {
+			return blockTagLifetime(*obj.BlockNumber, h)
+		}
+		return noCache
+	}
+
+	var tag string
+	if err := json.Unmarshal(raw, &tag); err != nil {
+		return noCache
+	}
+	return blockTagLifetime(tag, h)
+}
+
+func blockTagLifetime(tag string, h chainHead) lifetime {
+	switch tag {
+	case "pending":
+		return noCache
+	case "earliest":
+		return forever
+	case "latest", "safe", "finalized":
+		return h.headRelative()
+	}
+	if len(tag) == 66 {
+		// block hash
+		return forever
+	}
+	num, err := strconv.ParseUint(strings.TrimPrefix(tag, "0x"), 16, 64)
+	if err != nil {
+		return noCache
+	}
+	if h.isFinal(num) {
+		return forever
+	}
+	return h.headRelative()
+}
+
+// logsLifetime classifies eth_getLogs by its block range
+func logsLifetime(params []json.RawMessage, h chainHead) lifetime {
+	if len(params) != 1 {
+		return noCache
+	}
+	var filter struct {
+		BlockHash *string `json:"blockHash"`
+		FromBlock *string `json:"fromBlock"`
+		ToBlock   *string `json:"toBlock"`
+	}
+	if err := json.Unmarshal(params[0], &filter); err != nil {
+		return noCache
+	}
+	if filter.BlockHash != nil {
+		return forever
+	}
+
+	res := forever
+	for _, tag := range []*string{filter.FromBlock, filter.ToBlock} {
+		l := h.headRelative()
+		if tag != nil {
+			l = blockTagLifetime(*tag, h)
+		}
+		if l < res {
+			res = l
+		}
+	}
+	return res
+}
+
+// resultLifetime classifies a transaction or receipt by the block it is included in
+func resultLifetime(raw json.RawMessage, h chainHead) lifetime {
+	var obj *struct {
+		BlockNumber *string `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil || obj.BlockNumber == nil {
+		// not found or pending
+		return noCache
+	}
+	return blockTagLifetime(*obj.BlockNumber, h)
+}
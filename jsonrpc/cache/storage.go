@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Storage is the storage of the cached responses
+type Storage interface {
+	// Get returns the value of the key and whether it exists
+	Get(key string) ([]byte, bool, error)
+
+	// Set sets the value of the key
+	Set(key string, value []byte) error
+
+	// Close closes the storage
+	Close() error
+}
+
+var _ Storage = (*LRU)(nil)
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// LRU is an in-memory storage that evicts the least recently used entries
+type LRU struct {
+	lock  sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+// NewLRU creates a new LRU storage with space for size entries
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+// Get implements the Storage interface
+func (l *LRU) Get(key string) ([]byte, bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true, nil
+}
+
+// Set implements the Storage interface
+func (l *LRU) Set(key string, value []byte) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		l.order.MoveToFront(elem)
+		return nil
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value})
+
+	for l.order.Len() > l.size {
+		last := l.order.Back()
+		l.order.Remove(last)
+		delete(l.items, last.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries
+func (l *LRU) Len() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.order.Len()
+}
+
+// Purge removes all the entries
+func (l *LRU) Purge() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.items = map[string]*list.Element{}
+	l.order.Init()
+}
+
+// Close implements the Storage interface
+func (l *LRU) Close() error {
+	return nil
+}
@@ -1,12 +1,16 @@
 package jsonrpc
 
 import (
+	"context"
+
 	"github.com/umbracle/ethgo/jsonrpc/transport"
+	"github.com/umbracle/ethgo/metrics"
 )
 
 // Client is the jsonrpc client
 type Client struct {
-	transport transport.Transport
+	transport   transport.Transport
+	middlewares []Middleware
 
 	endpoints endpoints
 }
@@ -16,10 +20,21 @@ type endpoints struct {
 	e *Eth
 	n *Net
 	d *Debug
+	t *Trace
+	a *Admin
+	p *TxPool
+	s *Personal
 }
 
 type Config struct {
-	headers map[string]string
+	headers     map[string]string
+	reconnect   *transport.ReconnectConfig
+	middlewares []Middleware
+	retry       *RetryPolicy
+	rateLimit   *RateLimit
+	netHTTP     *transport.NetHTTPConfig
+	metrics     metrics.Recorder
+	tracer      metrics.Tracer
 }
 
 type ConfigOption func(*Config)
@@ -32,24 +47,99 @@ func WithHeaders(headers map[string]string) ConfigOption {
 	}
 }
 
+// WithReconnect reconnects the websocket and ipc transports when the
+// connection drops and starts again the active subscriptions
+func WithReconnect(config *transport.ReconnectConfig) ConfigOption {
+	return func(c *Config) {
+		c.reconnect = config
+	}
+}
+
+// WithNetHTTP uses the net/http transport for the http endpoints instead of
+// fasthttp. It allows custom http clients, TLS, proxies and token auth.
+func WithNetHTTP(config *transport.NetHTTPConfig) ConfigOption {
+	return func(c *Config) {
+		if config == nil {
+			config = &transport.NetHTTPConfig{}
+		}
+		c.netHTTP = config
+	}
+}
+
 func NewClient(addr string, opts ...ConfigOption) (*Client, error) {
+	config := newConfig(opts...)
+
+	t, err := transport.NewTransportWithConfig(addr, config.transportConfig())
+	if err != nil {
+		return nil, err
+	}
+	return newClient(t, config), nil
+}
+
+// NewMultiClient creates a client that spreads the calls among several endpoints
+// and fails over to the healthy ones. See transport.Multi.
+func NewMultiClient(addrs []string, multiConfig *transport.MultiConfig, opts ...ConfigOption) (*Client, error) {
+	config := newConfig(opts...)
+
+	t, err := transport.NewMultiTransport(addrs, config.transportConfig(), multiConfig)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(t, config), nil
+}
+
+// NewClientWithTransport creates a client on top of an existing transport.
+// The options to configure the transport (headers, reconnect) are ignored.
+func NewClientWithTransport(t transport.Transport, opts ...ConfigOption) *Client {
+	return newClient(t, newConfig(opts...))
+}
+
+func newConfig(opts ...ConfigOption) *Config {
 	config := &Config{headers: map[string]string{}}
 	for _, opt := range opts {
 		opt(config)
 	}
+	return config
+}
 
-	c := &Client{}
-	c.endpoints.w = &Web3{c}
-	c.endpoints.e = &Eth{c: c}
-	c.endpoints.n = &Net{c}
-	c.endpoints.d = &Debug{c}
+func (c *Config) transportConfig() *transport.Config {
+	return &transport.Config{
+		Headers:   c.headers,
+		Reconnect: c.reconnect,
+		NetHTTP:   c.netHTTP,
+	}
+}
 
-	t, err := transport.NewTransport(addr, config.headers)
-	if err != nil {
-		return nil, err
+func newClient(t transport.Transport, config *Config) *Client {
+	c := &Client{
+		transport:   t,
+		middlewares: config.middlewares,
+	}
+	// the metrics and the spans are the outermost middlewares to
+	// measure the requests as seen by the caller
+	if config.metrics != nil {
+		c.middlewares = append([]Middleware{metricsMiddleware(config.metrics)}, c.middlewares...)
+		trackReconnects(t, config.metrics)
+	}
+	if config.tracer != nil {
+		c.middlewares = append([]Middleware{tracerMiddleware(config.tracer)}, c.middlewares...)
+	}
+	if config.retry != nil {
+		c.middlewares = append(append([]Middleware{}, c.middlewares...), retryMiddleware(config.retry))
+	}
+	if config.rateLimit != nil {
+		// every retry attempt waits for the limits
+		c.middlewares = append(append([]Middleware{}, c.middlewares...), rateLimitMiddleware(config.rateLimit))
 	}
-	c.transport = t
-	return c, nil
+	c.endpoints.w = &Web3{c: c, ctx: context.Background()}
+	c.endpoints.e = &Eth{c: c, ctx: context.Background()}
+	c.endpoints.n = &Net{c: c, ctx: context.Background()}
+	c.endpoints.d = &Debug{c: c, ctx: context.Background()}
+	c.endpoints.t = &Trace{c: c, ctx: context.Background()}
+	c.endpoints.a = &Admin{c: c, ctx: context.Background()}
+	c.endpoints.p = &TxPool{c: c, ctx: context.Background()}
+	c.endpoints.s = &Personal{c: c, ctx: context.Background()}
+	return c
 }
 
 // Close closes the transport
@@ -65,10 +155,23 @@ func (c *Client) IsClosed() bool {
 
 // Call makes a jsonrpc call
 func (c *Client) Call(method string, out interface{}, params ...interface{}) error {
-	return c.transport.Call(method, out, params...)
+	if len(c.middlewares) != 0 {
+		return wrapError(c.invoke(context.Background(), false, method, out, params))
+	}
+	return wrapError(c.transport.Call(method, out, params...))
+}
+
+// CallContext makes a jsonrpc call that is aborted if the context
+// is cancelled or its deadline expires
+func (c *Client) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if len(c.middlewares) != 0 {
+		return wrapError(c.invoke(ctx, true, method, out, params))
+	}
+	return wrapError(c.transport.CallContext(ctx, method, out, params...))
 }
 
-// SetMaxConnsLimit sets the maximum number of connections that can be established with a host
+// SetMaxConnsLimit sets the maximum number of connections that can be established with a host.
+// On the websocket and ipc transports it is the maximum number of in-flight requests.
 func (c *Client) SetMaxConnsLimit(count int) {
 	c.transport.SetMaxConnsPerHost(count)
 }
@@ -15,9 +15,10 @@ type Request struct {
 
 // Response is a jsonrpc response
 type Response struct {
-	ID     uint64          `json:"id"`
-	Result json.RawMessage `json:"result"`
-	Error  *ErrorObject    `json:"error,omitempty"`
+	JsonRPC string          `json:"jsonrpc,omitempty"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ErrorObject    `json:"error,omitempty"`
 }
 
 // ErrorObject is a jsonrpc error
@@ -33,11 +34,18 @@ type Subscription struct {
 	Result json.RawMessage `json:"result"`
 }
 
-// Error implements error interface
+// Error implements error interface. It includes the code and the data of the error.
 func (e *ErrorObject) Error() string {
-	data, err := json.Marshal(e)
+	msg := e.Message
+	if msg == "" {
+		msg = "jsonrpc error"
+	}
+	if e.Data == nil {
+		return fmt.Sprintf("%s (code %d)", msg, e.Code)
+	}
+	data, err := json.Marshal(e.Data)
 	if err != nil {
-		return fmt.Sprintf("jsonrpc.internal marshal error: %v", err)
+		return fmt.Sprintf("%s (code %d, data %v)", msg, e.Code, e.Data)
 	}
-	return string(data)
+	return fmt.Sprintf("%s (code %d, data %s)", msg, e.Code, data)
 }
@@ -0,0 +1,18 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorObject_Error(t *testing.T) {
+	err := &ErrorObject{Code: -32000, Message: "nonce too low"}
+	assert.Equal(t, "nonce too low (code -32000)", err.Error())
+
+	err = &ErrorObject{Code: 3, Message: "execution reverted", Data: "0x01"}
+	assert.Equal(t, `execution reverted (code 3, data "0x01")`, err.Error())
+
+	err = &ErrorObject{Code: -32601}
+	assert.Equal(t, "jsonrpc error (code -32601)", err.Error())
+}
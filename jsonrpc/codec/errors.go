@@ -0,0 +1,66 @@
+package codec
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrRateLimited happens when the endpoint throttles the requests
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrLimitExceeded happens when the query returns too many results
+	ErrLimitExceeded = errors.New("limit exceeded")
+
+	// ErrNotFound happens when the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrHeaderNotFound happens when the endpoint does not have the requested block
+	ErrHeaderNotFound = errors.New("header not found")
+
+	// ErrExecutionReverted happens when the execution of a call reverts
+	ErrExecutionReverted = errors.New("execution reverted")
+
+	// ErrNonceTooLow happens when the nonce of a transaction is already used
+	ErrNonceTooLow = errors.New("nonce too low")
+
+	// ErrUnderpriced happens when the gas price of a transaction is too low
+	ErrUnderpriced = errors.New("transaction underpriced")
+)
+
+// jsonrpc error codes of EIP-1474 and the execution errors of geth
+const (
+	codeExecutionError   = 3
+	codeResourceNotFound = -32001
+	codeLimitExceeded    = -32005
+	codeTooManyRequests  = 429
+)
+
+// Is classifies the error object so that it can be matched
+// with errors.Is against the errors of this package
+func (e *ErrorObject) Is(target error) bool {
+	return e.kind() == target
+}
+
+func (e *ErrorObject) kind() error {
+	msg := strings.ToLower(e.Message)
+
+	switch {
+	case e.Code == codeExecutionError || strings.HasPrefix(msg, "execution reverted"):
+		return ErrExecutionReverted
+	case strings.Contains(msg, "nonce too low"):
+		return ErrNonceTooLow
+	case strings.Contains(msg, "underpriced"):
+		return ErrUnderpriced
+	case strings.Contains(msg, "header not found") || strings.Contains(msg, "unknown block"):
+		return ErrHeaderNotFound
+	case strings.Contains(msg, "query returned more than") || strings.Contains(msg, "response size exceeded"):
+		return ErrLimitExceeded
+	case e.Code == codeLimitExceeded || e.Code == codeTooManyRequests ||
+		strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests"):
+		return ErrRateLimited
+	case e.Code == codeResourceNotFound || msg == "not found" || strings.Contains(msg, "resource not found"):
+		return ErrNotFound
+	}
+	return nil
+}
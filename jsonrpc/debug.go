@@ -1,18 +1,99 @@
 package jsonrpc
 
-import "github.com/umbracle/ethgo"
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
 
+	"github.com/umbracle/ethgo"
+)
+
+// Debug is the debug namespace
 type Debug struct {
-	c *Client
+	c   *Client
+	ctx context.Context
 }
 
-// Eth returns the reference to the eth namespace
+// Debug returns the reference to the debug namespace
 func (c *Client) Debug() *Debug {
 	return c.endpoints.d
 }
 
+// WithContext returns a copy of the debug namespace whose calls are bound to ctx
+func (d *Debug) WithContext(ctx context.Context) *Debug {
+	d2 := *d
+	d2.ctx = ctx
+	return &d2
+}
+
+const (
+	// CallTracer is the name of the tracer that returns the tree of calls
+	CallTracer = "callTracer"
+	// PrestateTracer is the name of the tracer that returns the state touched by the execution
+	PrestateTracer = "prestateTracer"
+)
+
+// TraceConfig is the configuration of the debug traces
+type TraceConfig struct {
+	// Tracer is the name of a built-in tracer or the javascript code of a custom
+	// tracer. If empty, the struct logger is used.
+	Tracer string
+
+	// TracerConfig is the configuration of the tracer (i.e. CallTracerConfig)
+	TracerConfig interface{}
+
+	// Timeout is the maximum duration of the trace
+	Timeout time.Duration
+
+	// struct logger options
+	DisableStack     bool
+	DisableStorage   bool
+	DisableMemory    bool
+	EnableMemory     bool
+	EnableReturnData bool
+}
+
+// MarshalJSON implements the json marshal interface
+func (t *TraceConfig) MarshalJSON() ([]byte, error) {
+	obj := struct {
+		Tracer           string      `json:"tracer,omitempty"`
+		TracerConfig     interface{} `json:"tracerConfig,omitempty"`
+		Timeout          string      `json:"timeout,omitempty"`
+		DisableStack     bool        `json:"disableStack,omitempty"`
+		DisableStorage   bool        `json:"disableStorage,omitempty"`
+		DisableMemory    bool        `json:"disableMemory,omitempty"`
+		EnableMemory     bool        `json:"enableMemory,omitempty"`
+		EnableReturnData bool        `json:"enableReturnData,omitempty"`
+	}{
+		Tracer:           t.Tracer,
+		TracerConfig:     t.TracerConfig,
+		DisableStack:     t.DisableStack,
+		DisableStorage:   t.DisableStorage,
+		DisableMemory:    t.DisableMemory,
+		EnableMemory:     t.EnableMemory,
+		EnableReturnData: t.EnableReturnData,
+	}
+	if t.Timeout != 0 {
+		obj.Timeout = t.Timeout.String()
+	}
+	return json.Marshal(obj)
+}
+
+// CallTracerConfig is the configuration of the call tracer
+type CallTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+	WithLog     bool `json:"withLog,omitempty"`
+}
+
+// PrestateTracerConfig is the configuration of the prestate tracer
+type PrestateTracerConfig struct {
+	DiffMode bool `json:"diffMode,omitempty"`
+}
+
 type TransactionTrace struct {
 	Gas         uint64
+	Failed      bool
 	ReturnValue string
 	StructLogs  []*StructLogs
 }
@@ -28,8 +109,232 @@ type StructLogs struct {
 	Storage map[string]string
 }
 
+// TraceResult is the output of a tracer
+type TraceResult struct {
+	raw json.RawMessage
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (t *TraceResult) UnmarshalJSON(buf []byte) error {
+	t.raw = append(t.raw[:0], buf...)
+	return nil
+}
+
+// Raw returns the json output of the tracer
+func (t *TraceResult) Raw() json.RawMessage {
+	return t.raw
+}
+
+// Decode decodes the output of the tracer into out. It is used
+// for the output of custom tracers.
+func (t *TraceResult) Decode(out interface{}) error {
+	return json.Unmarshal(t.raw, out)
+}
+
+// StructLogs decodes the output of the default struct logger
+func (t *TraceResult) StructLogs() (*TransactionTrace, error) {
+	var res *TransactionTrace
+	if err := t.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CallFrame decodes the output of the call tracer
+func (t *TraceResult) CallFrame() (*CallFrame, error) {
+	var res *CallFrame
+	if err := t.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Prestate decodes the output of the prestate tracer
+func (t *TraceResult) Prestate() (map[ethgo.Address]*PrestateAccount, error) {
+	var res map[ethgo.Address]*PrestateAccount
+	if err := t.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PrestateDiff decodes the output of the prestate tracer in diff mode
+func (t *TraceResult) PrestateDiff() (*PrestateDiff, error) {
+	var res *PrestateDiff
+	if err := t.Decode(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// BlockTraceResult is the trace of a transaction in a block
+type BlockTraceResult struct {
+	TxHash ethgo.Hash   `json:"txHash"`
+	Result *TraceResult `json:"result"`
+	Error  string       `json:"error"`
+}
+
+// CallLog is a log emitted during a call of the call tracer
+type CallLog struct {
+	Address ethgo.Address
+	Topics  []ethgo.Hash
+	Data    []byte
+}
+
+// CallFrame is a call in the tree of calls of the call tracer
+type CallFrame struct {
+	Type         string
+	From         ethgo.Address
+	To           ethgo.Address
+	Value        *big.Int
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Error        string
+	RevertReason string
+	Calls        []*CallFrame
+	Logs         []*CallLog
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (c *CallFrame) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Type         string        `json:"type"`
+		From         ethgo.Address `json:"from"`
+		To           ethgo.Address `json:"to"`
+		Value        string        `json:"value"`
+		Gas          string        `json:"gas"`
+		GasUsed      string        `json:"gasUsed"`
+		Input        string        `json:"input"`
+		Output       string        `json:"output"`
+		Error        string        `json:"error"`
+		RevertReason string        `json:"revertReason"`
+		Calls        []*CallFrame  `json:"calls"`
+		Logs         []struct {
+			Address ethgo.Address `json:"address"`
+			Topics  []ethgo.Hash  `json:"topics"`
+			Data    string        `json:"data"`
+		} `json:"logs"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	c.Type = obj.Type
+	c.From = obj.From
+	c.To = obj.To
+	c.Error = obj.Error
+	c.RevertReason = obj.RevertReason
+	c.Calls = obj.Calls
+	if obj.Value != "" {
+		c.Value = parseBigInt(obj.Value)
+	}
+	if c.Gas, err = parseOptionalUint64(obj.Gas); err != nil {
+		return err
+	}
+	if c.GasUsed, err = parseOptionalUint64(obj.GasUsed); err != nil {
+		return err
+	}
+	if c.Input, err = parseOptionalHexBytes(obj.Input); err != nil {
+		return err
+	}
+	if c.Output, err = parseOptionalHexBytes(obj.Output); err != nil {
+		return err
+	}
+	c.Logs = c.Logs[:0]
+	for _, log := range obj.Logs {
+		data, err := parseOptionalHexBytes(log.Data)
+		if err != nil {
+			return err
+		}
+		c.Logs = append(c.Logs, &CallLog{
+			Address: log.Address,
+			Topics:  log.Topics,
+			Data:    data,
+		})
+	}
+	return nil
+}
+
+// PrestateAccount is the state of an account in the prestate tracer
+type PrestateAccount struct {
+	Balance *big.Int
+	Nonce   uint64
+	Code    []byte
+	Storage map[ethgo.Hash]ethgo.Hash
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (p *PrestateAccount) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Balance string                    `json:"balance"`
+		Nonce   uint64                    `json:"nonce"`
+		Code    string                    `json:"code"`
+		Storage map[ethgo.Hash]ethgo.Hash `json:"storage"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	if obj.Balance != "" {
+		p.Balance = parseBigInt(obj.Balance)
+	}
+	if p.Code, err = parseOptionalHexBytes(obj.Code); err != nil {
+		return err
+	}
+	p.Nonce = obj.Nonce
+	p.Storage = obj.Storage
+	return nil
+}
+
+// PrestateDiff is the output of the prestate tracer in diff mode
+type PrestateDiff struct {
+	Pre  map[ethgo.Address]*PrestateAccount `json:"pre"`
+	Post map[ethgo.Address]*PrestateAccount `json:"post"`
+}
+
+// TraceTransaction returns the trace of the execution of a transaction with the default struct logger
 func (d *Debug) TraceTransaction(hash ethgo.Hash) (*TransactionTrace, error) {
 	var res *TransactionTrace
-	err := d.c.Call("debug_traceTransaction", &res, hash)
+	err := d.c.CallContext(d.ctx, "debug_traceTransaction", &res, hash)
+	return res, err
+}
+
+// TraceTransactionWithConfig returns the trace of the execution of a transaction
+// with the tracer of the config. The config is optional.
+func (d *Debug) TraceTransactionWithConfig(hash ethgo.Hash, config *TraceConfig) (*TraceResult, error) {
+	var res *TraceResult
+	err := d.c.CallContext(d.ctx, "debug_traceTransaction", &res, traceParams(config, hash)...)
 	return res, err
 }
+
+// TraceCall returns the trace of the execution of a call on top of the state of the block
+func (d *Debug) TraceCall(msg *ethgo.CallMsg, block ethgo.BlockNumberOrHash, config *TraceConfig) (*TraceResult, error) {
+	var res *TraceResult
+	err := d.c.CallContext(d.ctx, "debug_traceCall", &res, traceParams(config, msg, block.Location())...)
+	return res, err
+}
+
+// TraceBlockByNumber returns the traces of all the transactions in a block by block number
+func (d *Debug) TraceBlockByNumber(i ethgo.BlockNumber, config *TraceConfig) ([]*BlockTraceResult, error) {
+	var res []*BlockTraceResult
+	err := d.c.CallContext(d.ctx, "debug_traceBlockByNumber", &res, traceParams(config, i.String())...)
+	return res, err
+}
+
+// TraceBlockByHash returns the traces of all the transactions in a block by hash
+func (d *Debug) TraceBlockByHash(hash ethgo.Hash, config *TraceConfig) ([]*BlockTraceResult, error) {
+	var res []*BlockTraceResult
+	err := d.c.CallContext(d.ctx, "debug_traceBlockByHash", &res, traceParams(config, hash)...)
+	return res, err
+}
+
+func traceParams(config *TraceConfig, params ...interface{}) []interface{} {
+	if config != nil {
+		params = append(params, config)
+	}
+	return params
+}
@@ -1,9 +1,13 @@
 package jsonrpc
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
 	"github.com/umbracle/ethgo/testutil"
 )
 
@@ -25,3 +29,137 @@ func TestDebug_TraceTransaction(t *testing.T) {
 	assert.Greater(t, trace.Gas, uint64(20000))
 	assert.NotEmpty(t, trace.StructLogs)
 }
+
+func TestDebug_TraceTransactionCallTracer(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	cc := &testutil.Contract{}
+	cc.AddEvent(testutil.NewEvent("A").Add("address", true))
+	cc.EmitEvent("setA", "A", addr0.String())
+
+	_, addr := s.DeployContract(cc)
+	r := s.TxnTo(addr, "setA2")
+
+	res, err := c.Debug().TraceTransactionWithConfig(r.TransactionHash, &TraceConfig{
+		Tracer:       CallTracer,
+		TracerConfig: &CallTracerConfig{WithLog: true},
+	})
+	assert.NoError(t, err)
+
+	frame, err := res.CallFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, "CALL", frame.Type)
+	assert.Equal(t, addr, frame.To)
+	assert.NotZero(t, frame.GasUsed)
+	assert.Len(t, frame.Logs, 1)
+}
+
+func TestTraceConfig_MarshalJSON(t *testing.T) {
+	config := &TraceConfig{
+		Tracer:       PrestateTracer,
+		TracerConfig: &PrestateTracerConfig{DiffMode: true},
+		Timeout:      10 * time.Second,
+	}
+	data, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"tracer":"prestateTracer","tracerConfig":{"diffMode":true},"timeout":"10s"}`, string(data))
+
+	data, err = json.Marshal(&TraceConfig{DisableStack: true})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"disableStack":true}`, string(data))
+}
+
+func TestTraceResult_CallFrame(t *testing.T) {
+	raw := `{
+		"type": "CALL",
+		"from": "0x0000000000000000000000000000000000000001",
+		"to": "0x0000000000000000000000000000000000000002",
+		"value": "0x10",
+		"gas": "0x5208",
+		"gasUsed": "0x100",
+		"input": "0x01",
+		"output": "0x",
+		"calls": [
+			{
+				"type": "DELEGATECALL",
+				"from": "0x0000000000000000000000000000000000000002",
+				"to": "0x0000000000000000000000000000000000000003",
+				"gas": "0x10",
+				"gasUsed": "0x1",
+				"input": "0x02",
+				"error": "execution reverted",
+				"revertReason": "failed"
+			}
+		],
+		"logs": [
+			{
+				"address": "0x0000000000000000000000000000000000000002",
+				"topics": ["0x0000000000000000000000000000000000000000000000000000000000000001"],
+				"data": "0x03"
+			}
+		]
+	}`
+
+	var res *TraceResult
+	assert.NoError(t, json.Unmarshal([]byte(raw), &res))
+
+	frame, err := res.CallFrame()
+	assert.NoError(t, err)
+	assert.Equal(t, "CALL", frame.Type)
+	assert.Equal(t, ethgo.HexToAddress("0x0000000000000000000000000000000000000002"), frame.To)
+	assert.Equal(t, big.NewInt(16), frame.Value)
+	assert.Equal(t, uint64(21000), frame.Gas)
+	assert.Equal(t, uint64(256), frame.GasUsed)
+	assert.Equal(t, []byte{0x1}, frame.Input)
+	assert.Empty(t, frame.Output)
+
+	assert.Len(t, frame.Calls, 1)
+	assert.Equal(t, "DELEGATECALL", frame.Calls[0].Type)
+	assert.Equal(t, "execution reverted", frame.Calls[0].Error)
+	assert.Equal(t, "failed", frame.Calls[0].RevertReason)
+	assert.Nil(t, frame.Calls[0].Value)
+
+	assert.Len(t, frame.Logs, 1)
+	assert.Equal(t, []byte{0x3}, frame.Logs[0].Data)
+	assert.Equal(t, ethgo.Hash{31: 0x1}, frame.Logs[0].Topics[0])
+}
+
+func TestTraceResult_PrestateDiff(t *testing.T) {
+	raw := `{
+		"pre": {
+			"0x0000000000000000000000000000000000000001": {
+				"balance": "0x64",
+				"nonce": 1,
+				"code": "0x6000",
+				"storage": {
+					"0x0000000000000000000000000000000000000000000000000000000000000000": "0x0000000000000000000000000000000000000000000000000000000000000001"
+				}
+			}
+		},
+		"post": {
+			"0x0000000000000000000000000000000000000001": {
+				"balance": "0x32",
+				"nonce": 2
+			}
+		}
+	}`
+
+	var res *TraceResult
+	assert.NoError(t, json.Unmarshal([]byte(raw), &res))
+
+	diff, err := res.PrestateDiff()
+	assert.NoError(t, err)
+
+	pre := diff.Pre[ethgo.HexToAddress("0x0000000000000000000000000000000000000001")]
+	assert.Equal(t, big.NewInt(100), pre.Balance)
+	assert.Equal(t, uint64(1), pre.Nonce)
+	assert.Equal(t, []byte{0x60, 0x0}, pre.Code)
+	assert.Equal(t, ethgo.Hash{31: 0x1}, pre.Storage[ethgo.Hash{}])
+
+	post := diff.Post[ethgo.HexToAddress("0x0000000000000000000000000000000000000001")]
+	assert.Equal(t, big.NewInt(50), post.Balance)
+	assert.Equal(t, uint64(2), post.Nonce)
+}
@@ -0,0 +1,98 @@
+package jsonrpc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// Errors returned by the endpoints. The jsonrpc errors are classified
+// by code and message and can be matched with errors.Is.
+var (
+	// ErrRateLimited happens when the endpoint throttles the requests
+	ErrRateLimited = codec.ErrRateLimited
+
+	// ErrLimitExceeded happens when the query returns too many results
+	ErrLimitExceeded = codec.ErrLimitExceeded
+
+	// ErrNotFound happens when the requested resource does not exist
+	ErrNotFound = codec.ErrNotFound
+
+	// ErrHeaderNotFound happens when the endpoint does not have the requested block
+	ErrHeaderNotFound = codec.ErrHeaderNotFound
+
+	// ErrExecutionReverted happens when the execution of a call reverts.
+	// The error is a *RevertError.
+	ErrExecutionReverted = codec.ErrExecutionReverted
+
+	// ErrNonceTooLow happens when the nonce of a transaction is already used
+	ErrNonceTooLow = codec.ErrNonceTooLow
+
+	// ErrUnderpriced happens when the gas price of a transaction is too low
+	ErrUnderpriced = codec.ErrUnderpriced
+)
+
+// RevertError is the error of a call whose execution reverted
+type RevertError struct {
+	// Reason is the reason of the revert if it is encoded as Error(string)
+	// or the description of the code if it is a Panic(uint256)
+	Reason string
+
+	// Data is the raw revert data
+	Data []byte
+
+	obj *codec.ErrorObject
+}
+
+// Error implements the error interface
+func (r *RevertError) Error() string {
+	if r.Reason != "" {
+		return fmt.Sprintf("execution reverted: %s", r.Reason)
+	}
+	if len(r.Data) != 0 {
+		return fmt.Sprintf("execution reverted: 0x%s", hex.EncodeToString(r.Data))
+	}
+	return "execution reverted"
+}
+
+// Decode decodes the revert data with the custom errors of the abi
+func (r *RevertError) Decode(a *abi.ABI) (*abi.DecodedError, error) {
+	return a.DecodeError(r.Data)
+}
+
+// Unwrap returns the jsonrpc error object
+func (r *RevertError) Unwrap() error {
+	return r.obj
+}
+
+// wrapError converts the jsonrpc errors of reverted calls into a RevertError
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var obj *codec.ErrorObject
+	if !errors.As(err, &obj) || !obj.Is(ErrExecutionReverted) {
+		return err
+	}
+	revertErr := &RevertError{obj: obj}
+	if str, ok := obj.Data.(string); ok {
+		// some clients prefix the data (i.e. 'Reverted 0x...')
+		if indx := strings.Index(str, "0x"); indx != -1 {
+			if data, err := hex.DecodeString(str[indx+2:]); err == nil {
+				revertErr.Data = data
+			}
+		}
+	}
+	if len(revertErr.Data) != 0 {
+		// decode only the revert reasons and the panics
+		var noAbi *abi.ABI
+		if decoded, err := noAbi.DecodeError(revertErr.Data); err == nil {
+			revertErr.Reason = decoded.Error()
+		}
+	}
+	return revertErr
+}
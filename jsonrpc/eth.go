@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 // Eth is the eth namespace
 type Eth struct {
 	c       *Client
+	ctx     context.Context
 	chainId *big.Int
 }
 
@@ -20,16 +22,30 @@ func (c *Client) Eth() *Eth {
 	return c.endpoints.e
 }
 
+// ContextProvider is implemented by the providers that can bind their
+// requests to a context (i.e. Eth). The trackers use it to propagate the
+// spans of their queries to the client.
+type ContextProvider interface {
+	WithContext(ctx context.Context) *Eth
+}
+
+// WithContext returns a copy of the eth namespace whose calls are bound to ctx
+func (e *Eth) WithContext(ctx context.Context) *Eth {
+	e2 := *e
+	e2.ctx = ctx
+	return &e2
+}
+
 func (e *Eth) GetNodeInfo() (string, error) {
 	var res string
-	err := e.c.Call("web3_clientVersion", &res)
+	err := e.c.CallContext(e.ctx, "web3_clientVersion", &res)
 	return res, err
 }
 
 // GetCode returns the code of a contract
 func (e *Eth) GetCode(addr ethgo.Address, block ethgo.BlockNumberOrHash) (string, error) {
 	var res string
-	if err := e.c.Call("eth_getCode", &res, addr, block.Location()); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_getCode", &res, addr, block.Location()); err != nil {
 		return "", err
 	}
 	return res, nil
@@ -38,7 +54,7 @@ func (e *Eth) GetCode(addr ethgo.Address, block ethgo.BlockNumberOrHash) (string
 // Accounts returns a list of addresses owned by client.
 func (e *Eth) Accounts() ([]ethgo.Address, error) {
 	var out []ethgo.Address
-	if err := e.c.Call("eth_accounts", &out); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_accounts", &out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -47,14 +63,14 @@ func (e *Eth) Accounts() ([]ethgo.Address, error) {
 // GetStorageAt returns the value from a storage position at a given address.
 func (e *Eth) GetStorageAt(addr ethgo.Address, slot ethgo.Hash, block ethgo.BlockNumberOrHash) (ethgo.Hash, error) {
 	var hash ethgo.Hash
-	err := e.c.Call("eth_getStorageAt", &hash, addr, slot, block.Location())
+	err := e.c.CallContext(e.ctx, "eth_getStorageAt", &hash, addr, slot, block.Location())
 	return hash, err
 }
 
 // BlockNumber returns the number of most recent block.
 func (e *Eth) BlockNumber() (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_blockNumber", &out); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_blockNumber", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -63,7 +79,7 @@ func (e *Eth) BlockNumber() (uint64, error) {
 // GetBlockByNumber returns information about a block by block number.
 func (e *Eth) GetBlockByNumber(i ethgo.BlockNumber, full bool) (*ethgo.Block, error) {
 	var b *ethgo.Block
-	if err := e.c.Call("eth_getBlockByNumber", &b, i.String(), full); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_getBlockByNumber", &b, i.String(), full); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -72,7 +88,7 @@ func (e *Eth) GetBlockByNumber(i ethgo.BlockNumber, full bool) (*ethgo.Block, er
 // GetBlockByHash returns information about a block by hash.
 func (e *Eth) GetBlockByHash(hash ethgo.Hash, full bool) (*ethgo.Block, error) {
 	var b *ethgo.Block
-	if err := e.c.Call("eth_getBlockByHash", &b, hash, full); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_getBlockByHash", &b, hash, full); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -81,7 +97,7 @@ func (e *Eth) GetBlockByHash(hash ethgo.Hash, full bool) (*ethgo.Block, error) {
 // GetFilterChanges returns the filter changes for log filters
 func (e *Eth) GetFilterChanges(id string) ([]*ethgo.Log, error) {
 	var raw string
-	err := e.c.Call("eth_getFilterChanges", &raw, id)
+	err := e.c.CallContext(e.ctx, "eth_getFilterChanges", &raw, id)
 	if err != nil {
 		return nil, err
 	}
@@ -95,14 +111,14 @@ func (e *Eth) GetFilterChanges(id string) ([]*ethgo.Log, error) {
 // GetTransactionByHash returns a transaction by his hash
 func (e *Eth) GetTransactionByHash(hash ethgo.Hash) (*ethgo.Transaction, error) {
 	var txn *ethgo.Transaction
-	err := e.c.Call("eth_getTransactionByHash", &txn, hash)
+	err := e.c.CallContext(e.ctx, "eth_getTransactionByHash", &txn, hash)
 	return txn, err
 }
 
 // GetFilterChangesBlock returns the filter changes for block filters
 func (e *Eth) GetFilterChangesBlock(id string) ([]ethgo.Hash, error) {
 	var raw string
-	err := e.c.Call("eth_getFilterChanges", &raw, id)
+	err := e.c.CallContext(e.ctx, "eth_getFilterChanges", &raw, id)
 	if err != nil {
 		return nil, err
 	}
@@ -116,21 +132,21 @@ func (e *Eth) GetFilterChangesBlock(id string) ([]ethgo.Hash, error) {
 // NewFilter creates a new log filter
 func (e *Eth) NewFilter(filter *ethgo.LogFilter) (string, error) {
 	var id string
-	err := e.c.Call("eth_newFilter", &id, filter)
+	err := e.c.CallContext(e.ctx, "eth_newFilter", &id, filter)
 	return id, err
 }
 
 // NewBlockFilter creates a new block filter
 func (e *Eth) NewBlockFilter() (string, error) {
 	var id string
-	err := e.c.Call("eth_newBlockFilter", &id, nil)
+	err := e.c.CallContext(e.ctx, "eth_newBlockFilter", &id, nil)
 	return id, err
 }
 
 // UninstallFilter uninstalls a filter
 func (e *Eth) UninstallFilter(id string) (bool, error) {
 	var res bool
-	err := e.c.Call("eth_uninstallFilter", &res, id)
+	err := e.c.CallContext(e.ctx, "eth_uninstallFilter", &res, id)
 	return res, err
 }
 
@@ -138,28 +154,28 @@ func (e *Eth) UninstallFilter(id string) (bool, error) {
 func (e *Eth) SendRawTransaction(data []byte) (ethgo.Hash, error) {
 	var hash ethgo.Hash
 	hexData := "0x" + hex.EncodeToString(data)
-	err := e.c.Call("eth_sendRawTransaction", &hash, hexData)
+	err := e.c.CallContext(e.ctx, "eth_sendRawTransaction", &hash, hexData)
 	return hash, err
 }
 
 // SendTransaction creates new message call transaction or a contract creation.
 func (e *Eth) SendTransaction(txn *ethgo.Transaction) (ethgo.Hash, error) {
 	var hash ethgo.Hash
-	err := e.c.Call("eth_sendTransaction", &hash, txn)
+	err := e.c.CallContext(e.ctx, "eth_sendTransaction", &hash, txn)
 	return hash, err
 }
 
 // GetTransactionReceipt returns the receipt of a transaction by transaction hash.
 func (e *Eth) GetTransactionReceipt(hash ethgo.Hash) (*ethgo.Receipt, error) {
 	var receipt *ethgo.Receipt
-	err := e.c.Call("eth_getTransactionReceipt", &receipt, hash)
+	err := e.c.CallContext(e.ctx, "eth_getTransactionReceipt", &receipt, hash)
 	return receipt, err
 }
 
 // GetNonce returns the nonce of the account
 func (e *Eth) GetNonce(addr ethgo.Address, blockNumber ethgo.BlockNumberOrHash) (uint64, error) {
 	var nonce string
-	if err := e.c.Call("eth_getTransactionCount", &nonce, addr, blockNumber.Location()); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_getTransactionCount", &nonce, addr, blockNumber.Location()); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(nonce)
@@ -168,7 +184,7 @@ func (e *Eth) GetNonce(addr ethgo.Address, blockNumber ethgo.BlockNumberOrHash)
 // GetBalance returns the balance of the account of given address.
 func (e *Eth) GetBalance(addr ethgo.Address, blockNumber ethgo.BlockNumberOrHash) (*big.Int, error) {
 	var out string
-	if err := e.c.Call("eth_getBalance", &out, addr, blockNumber.Location()); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_getBalance", &out, addr, blockNumber.Location()); err != nil {
 		return nil, err
 	}
 	b, ok := new(big.Int).SetString(out[2:], 16)
@@ -181,7 +197,7 @@ func (e *Eth) GetBalance(addr ethgo.Address, blockNumber ethgo.BlockNumberOrHash
 // GasPrice returns the current price per gas in wei.
 func (e *Eth) GasPrice() (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_gasPrice", &out); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_gasPrice", &out); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -190,7 +206,28 @@ func (e *Eth) GasPrice() (uint64, error) {
 // Call executes a new message call immediately without creating a transaction on the block chain.
 func (e *Eth) Call(msg *ethgo.CallMsg, block ethgo.BlockNumber) (string, error) {
 	var out string
-	if err := e.c.Call("eth_call", &out, msg, block.String()); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_call", &out, msg, block.String()); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// CallWithOverrides executes a new message call on top of the state of the block
+// with the given state and block overrides. Both overrides are optional.
+func (e *Eth) CallWithOverrides(msg *ethgo.CallMsg, block ethgo.BlockNumber, state ethgo.StateOverride, blockOverrides *ethgo.BlockOverrides) (string, error) {
+	params := []interface{}{msg, block.String()}
+	if state != nil || blockOverrides != nil {
+		if state == nil {
+			state = ethgo.StateOverride{}
+		}
+		params = append(params, state)
+	}
+	if blockOverrides != nil {
+		params = append(params, blockOverrides)
+	}
+
+	var out string
+	if err := e.c.CallContext(e.ctx, "eth_call", &out, params...); err != nil {
 		return "", err
 	}
 	return out, nil
@@ -202,7 +239,7 @@ func (e *Eth) EstimateGasContract(bin []byte) (uint64, error) {
 	msg := map[string]interface{}{
 		"data": "0x" + hex.EncodeToString(bin),
 	}
-	if err := e.c.Call("eth_estimateGas", &out, msg); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_estimateGas", &out, msg); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -211,7 +248,7 @@ func (e *Eth) EstimateGasContract(bin []byte) (uint64, error) {
 // EstimateGas generates and returns an estimate of how much gas is necessary to allow the transaction to complete.
 func (e *Eth) EstimateGas(msg *ethgo.CallMsg) (uint64, error) {
 	var out string
-	if err := e.c.Call("eth_estimateGas", &out, msg); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_estimateGas", &out, msg); err != nil {
 		return 0, err
 	}
 	return parseUint64orHex(out)
@@ -220,7 +257,7 @@ func (e *Eth) EstimateGas(msg *ethgo.CallMsg) (uint64, error) {
 // GetLogs returns an array of all logs matching a given filter object
 func (e *Eth) GetLogs(filter *ethgo.LogFilter) ([]*ethgo.Log, error) {
 	var out []*ethgo.Log
-	if err := e.c.Call("eth_getLogs", &out, filter); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_getLogs", &out, filter); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -232,7 +269,7 @@ func (e *Eth) ChainID() (*big.Int, error) {
 		return e.chainId, nil
 	}
 	var out string
-	if err := e.c.Call("eth_chainId", &out); err != nil {
+	if err := e.c.CallContext(e.ctx, "eth_chainId", &out); err != nil {
 		return nil, err
 	}
 	chainId := parseBigInt(out)
@@ -240,219 +277,297 @@ func (e *Eth) ChainID() (*big.Int, error) {
 	return chainId, nil
 }
 
-/**
- var methods = [
-        new Method({
-            name: 'getNodeInfo',
-            call: 'web3_clientVersion'
-        }),
-        new Method({
-            name: 'getProtocolVersion',
-            call: 'eth_protocolVersion',
-            params: 0
-        }),
-        new Method({
-            name: 'getCoinbase',
-            call: 'eth_coinbase',
-            params: 0
-        }),
-        new Method({
-            name: 'isMining',
-            call: 'eth_mining',
-            params: 0
-        }),
-        new Method({
-            name: 'getHashrate',
-            call: 'eth_hashrate',
-            params: 0,
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'isSyncing',
-            call: 'eth_syncing',
-            params: 0,
-            outputFormatter: formatter.outputSyncingFormatter
-        }),
-        new Method({
-            name: 'getGasPrice',
-            call: 'eth_gasPrice',
-            params: 0,
-            outputFormatter: formatter.outputBigNumberFormatter
-        }),
-        new Method({
-            name: 'getAccounts',
-            call: 'eth_accounts',
-            params: 0,
-            outputFormatter: utils.toChecksumAddress
-        }),
-        new Method({
-            name: 'getBlockNumber',
-            call: 'eth_blockNumber',
-            params: 0,
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'getBalance',
-            call: 'eth_getBalance',
-            params: 2,
-            inputFormatter: [formatter.inputAddressFormatter, formatter.inputDefaultBlockNumberFormatter],
-            outputFormatter: formatter.outputBigNumberFormatter
-        }),
-        new Method({
-            name: 'getStorageAt',
-            call: 'eth_getStorageAt',
-            params: 3,
-            inputFormatter: [formatter.inputAddressFormatter, utils.numberToHex, formatter.inputDefaultBlockNumberFormatter]
-        }),
-        new Method({
-            name: 'getCode',
-            call: 'eth_getCode',
-            params: 2,
-            inputFormatter: [formatter.inputAddressFormatter, formatter.inputDefaultBlockNumberFormatter]
-        }),
-        new Method({
-            name: 'getBlock',
-            call: blockCall,
-            params: 2,
-            inputFormatter: [formatter.inputBlockNumberFormatter, function (val) { return !!val; }],
-            outputFormatter: formatter.outputBlockFormatter
-        }),
-        new Method({
-            name: 'getUncle',
-            call: uncleCall,
-            params: 2,
-            inputFormatter: [formatter.inputBlockNumberFormatter, utils.numberToHex],
-            outputFormatter: formatter.outputBlockFormatter,
-        }),
-        new Method({
-            name: 'getBlockTransactionCount',
-            call: getBlockTransactionCountCall,
-            params: 1,
-            inputFormatter: [formatter.inputBlockNumberFormatter],
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'getBlockUncleCount',
-            call: uncleCountCall,
-            params: 1,
-            inputFormatter: [formatter.inputBlockNumberFormatter],
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'getTransaction',
-            call: 'eth_getTransactionByHash',
-            params: 1,
-            inputFormatter: [null],
-            outputFormatter: formatter.outputTransactionFormatter
-        }),
-        new Method({
-            name: 'getTransactionFromBlock',
-            call: transactionFromBlockCall,
-            params: 2,
-            inputFormatter: [formatter.inputBlockNumberFormatter, utils.numberToHex],
-            outputFormatter: formatter.outputTransactionFormatter
-        }),
-        new Method({
-            name: 'getTransactionReceipt',
-            call: 'eth_getTransactionReceipt',
-            params: 1,
-            inputFormatter: [null],
-            outputFormatter: formatter.outputTransactionReceiptFormatter
-        }),
-        new Method({
-            name: 'getTransactionCount',
-            call: 'eth_getTransactionCount',
-            params: 2,
-            inputFormatter: [formatter.inputAddressFormatter, formatter.inputDefaultBlockNumberFormatter],
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'sendSignedTransaction',
-            call: 'eth_sendRawTransaction',
-            params: 1,
-            inputFormatter: [null],
-            abiCoder: abi
-        }),
-
-        new Method({
-            name: 'signTransaction',
-            call: 'eth_signTransaction',
-            params: 1,
-            inputFormatter: [formatter.inputTransactionFormatter]
-        }),
-        new Method({
-            name: 'sendTransaction',
-            call: 'eth_sendTransaction',
-            params: 1,
-            inputFormatter: [formatter.inputTransactionFormatter],
-            abiCoder: abi
-        }),
-        new Method({
-            name: 'sign',
-            call: 'eth_sign',
-            params: 2,
-            inputFormatter: [formatter.inputSignFormatter, formatter.inputAddressFormatter],
-            transformPayload: function (payload) {
-                payload.params.reverse();
-                return payload;
-            }
-        }),
-        new Method({
-            name: 'call',
-            call: 'eth_call',
-            params: 2,
-            inputFormatter: [formatter.inputCallFormatter, formatter.inputDefaultBlockNumberFormatter],
-            abiCoder: abi
-        }),
-        new Method({
-            name: 'estimateGas',
-            call: 'eth_estimateGas',
-            params: 1,
-            inputFormatter: [formatter.inputCallFormatter],
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'submitWork',
-            call: 'eth_submitWork',
-            params: 3
-        }),
-        new Method({
-            name: 'getWork',
-            call: 'eth_getWork',
-            params: 0
-        }),
-        new Method({
-            name: 'getPastLogs',
-            call: 'eth_getLogs',
-            params: 1,
-            inputFormatter: [formatter.inputLogFormatter],
-            outputFormatter: formatter.outputLogFormatter
-        }),
-        new Method({
-            name: 'getChainId',
-            call: 'eth_chainId',
-            params: 0,
-            outputFormatter: utils.hexToNumber
-        }),
-        new Method({
-            name: 'requestAccounts',
-            call: 'eth_requestAccounts',
-            params: 0,
-            outputFormatter: utils.toChecksumAddress
-        }),
-        new Method({
-            name: 'getProof',
-            call: 'eth_getProof',
-            params: 3,
-            inputFormatter: [formatter.inputAddressFormatter, formatter.inputStorageKeysFormatter, formatter.inputDefaultBlockNumberFormatter],
-            outputFormatter: formatter.outputProofFormatter
-        }),
-        new Method({
-            name: 'getPendingTransactions',
-            call: 'eth_pendingTransactions',
-            params: 0,
-            outputFormatter: formatter.outputTransactionFormatter
-        }),
-
-
-**/
+// ProtocolVersion returns the current ethereum protocol version
+func (e *Eth) ProtocolVersion() (uint64, error) {
+	var out string
+	if err := e.c.CallContext(e.ctx, "eth_protocolVersion", &out); err != nil {
+		return 0, err
+	}
+	return parseUint64orHex(out)
+}
+
+// SyncProgress is the progress of a node that is syncing
+type SyncProgress struct {
+	StartingBlock uint64
+	CurrentBlock  uint64
+	HighestBlock  uint64
+}
+
+// Syncing returns the sync progress of the node or nil if the node is not syncing
+func (e *Eth) Syncing() (*SyncProgress, error) {
+	var raw json.RawMessage
+	if err := e.c.CallContext(e.ctx, "eth_syncing", &raw); err != nil {
+		return nil, err
+	}
+	var syncing bool
+	if err := json.Unmarshal(raw, &syncing); err == nil {
+		// the node returns false if it is not syncing
+		return nil, nil
+	}
+
+	var out struct {
+		StartingBlock string `json:"startingBlock"`
+		CurrentBlock  string `json:"currentBlock"`
+		HighestBlock  string `json:"highestBlock"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+
+	var err error
+	progress := &SyncProgress{}
+	if progress.StartingBlock, err = parseUint64orHex(out.StartingBlock); err != nil {
+		return nil, err
+	}
+	if progress.CurrentBlock, err = parseUint64orHex(out.CurrentBlock); err != nil {
+		return nil, err
+	}
+	if progress.HighestBlock, err = parseUint64orHex(out.HighestBlock); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// MaxPriorityFeePerGas returns a fee per gas that is an estimate of how much
+// can be paid as a priority fee, or 'tip', to get a transaction included in the current block.
+func (e *Eth) MaxPriorityFeePerGas() (*big.Int, error) {
+	var out string
+	if err := e.c.CallContext(e.ctx, "eth_maxPriorityFeePerGas", &out); err != nil {
+		return nil, err
+	}
+	return parseBigInt(out), nil
+}
+
+// FeeHistory is the fee market history of a range of blocks
+type FeeHistory struct {
+	OldestBlock   uint64
+	BaseFeePerGas []*big.Int
+	GasUsedRatio  []float64
+	Reward        [][]*big.Int
+}
+
+// FeeHistory returns the base fee per gas and the effective priority fee per gas at the
+// requested percentiles for the blockCount blocks up to the newest block.
+func (e *Eth) FeeHistory(blockCount uint64, newest ethgo.BlockNumber, rewardPercentiles []float64) (*FeeHistory, error) {
+	var out struct {
+		OldestBlock   string     `json:"oldestBlock"`
+		BaseFeePerGas []string   `json:"baseFeePerGas"`
+		GasUsedRatio  []float64  `json:"gasUsedRatio"`
+		Reward        [][]string `json:"reward"`
+	}
+	if rewardPercentiles == nil {
+		rewardPercentiles = []float64{}
+	}
+	if err := e.c.CallContext(e.ctx, "eth_feeHistory", &out, encodeUintToHex(blockCount), newest.String(), rewardPercentiles); err != nil {
+		return nil, err
+	}
+
+	oldest, err := parseUint64orHex(out.OldestBlock)
+	if err != nil {
+		return nil, err
+	}
+	res := &FeeHistory{
+		OldestBlock:  oldest,
+		GasUsedRatio: out.GasUsedRatio,
+	}
+	for _, fee := range out.BaseFeePerGas {
+		res.BaseFeePerGas = append(res.BaseFeePerGas, parseBigInt(fee))
+	}
+	for _, rewards := range out.Reward {
+		blockRewards := make([]*big.Int, len(rewards))
+		for indx, reward := range rewards {
+			blockRewards[indx] = parseBigInt(reward)
+		}
+		res.Reward = append(res.Reward, blockRewards)
+	}
+	return res, nil
+}
+
+// StorageProof is the merkle proof of a storage slot
+type StorageProof struct {
+	Key   ethgo.Hash
+	Value *big.Int
+	Proof [][]byte
+}
+
+// AccountProof is the merkle proof of an account and some of its storage slots
+type AccountProof struct {
+	Address      ethgo.Address
+	AccountProof [][]byte
+	Balance      *big.Int
+	CodeHash     ethgo.Hash
+	Nonce        uint64
+	StorageHash  ethgo.Hash
+	StorageProof []*StorageProof
+}
+
+// GetProof returns the merkle proof of the account and the storage slots
+func (e *Eth) GetProof(addr ethgo.Address, storageKeys []ethgo.Hash, block ethgo.BlockNumberOrHash) (*AccountProof, error) {
+	var out struct {
+		Address      ethgo.Address `json:"address"`
+		AccountProof []string      `json:"accountProof"`
+		Balance      string        `json:"balance"`
+		CodeHash     ethgo.Hash    `json:"codeHash"`
+		Nonce        string        `json:"nonce"`
+		StorageHash  ethgo.Hash    `json:"storageHash"`
+		StorageProof []struct {
+			Key   string   `json:"key"`
+			Value string   `json:"value"`
+			Proof []string `json:"proof"`
+		} `json:"storageProof"`
+	}
+	if storageKeys == nil {
+		storageKeys = []ethgo.Hash{}
+	}
+	if err := e.c.CallContext(e.ctx, "eth_getProof", &out, addr, storageKeys, block.Location()); err != nil {
+		return nil, err
+	}
+
+	nonce, err := parseUint64orHex(out.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	res := &AccountProof{
+		Address:     out.Address,
+		Balance:     parseBigInt(out.Balance),
+		CodeHash:    out.CodeHash,
+		Nonce:       nonce,
+		StorageHash: out.StorageHash,
+	}
+	if res.AccountProof, err = parseHexBytesList(out.AccountProof); err != nil {
+		return nil, err
+	}
+	for _, proof := range out.StorageProof {
+		storageProof := &StorageProof{
+			Key:   ethgo.HexToHash(proof.Key),
+			Value: parseBigInt(proof.Value),
+		}
+		if storageProof.Proof, err = parseHexBytesList(proof.Proof); err != nil {
+			return nil, err
+		}
+		res.StorageProof = append(res.StorageProof, storageProof)
+	}
+	return res, nil
+}
+
+// GetBlockTransactionCountByNumber returns the number of transactions in a block by block number
+func (e *Eth) GetBlockTransactionCountByNumber(i ethgo.BlockNumber) (uint64, error) {
+	var out string
+	if err := e.c.CallContext(e.ctx, "eth_getBlockTransactionCountByNumber", &out, i.String()); err != nil {
+		return 0, err
+	}
+	return parseUint64orHex(out)
+}
+
+// GetBlockTransactionCountByHash returns the number of transactions in a block by hash
+func (e *Eth) GetBlockTransactionCountByHash(hash ethgo.Hash) (uint64, error) {
+	var out string
+	if err := e.c.CallContext(e.ctx, "eth_getBlockTransactionCountByHash", &out, hash); err != nil {
+		return 0, err
+	}
+	return parseUint64orHex(out)
+}
+
+// GetUncleByBlockNumberAndIndex returns information about an uncle of a block by block number and uncle index
+func (e *Eth) GetUncleByBlockNumberAndIndex(i ethgo.BlockNumber, index uint64) (*ethgo.Block, error) {
+	var b *ethgo.Block
+	if err := e.c.CallContext(e.ctx, "eth_getUncleByBlockNumberAndIndex", &b, i.String(), encodeUintToHex(index)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetUncleByBlockHashAndIndex returns information about an uncle of a block by hash and uncle index
+func (e *Eth) GetUncleByBlockHashAndIndex(hash ethgo.Hash, index uint64) (*ethgo.Block, error) {
+	var b *ethgo.Block
+	if err := e.c.CallContext(e.ctx, "eth_getUncleByBlockHashAndIndex", &b, hash, encodeUintToHex(index)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GetTransactionByBlockNumberAndIndex returns a transaction by block number and transaction index
+func (e *Eth) GetTransactionByBlockNumberAndIndex(i ethgo.BlockNumber, index uint64) (*ethgo.Transaction, error) {
+	var txn *ethgo.Transaction
+	err := e.c.CallContext(e.ctx, "eth_getTransactionByBlockNumberAndIndex", &txn, i.String(), encodeUintToHex(index))
+	return txn, err
+}
+
+// GetTransactionByBlockHashAndIndex returns a transaction by block hash and transaction index
+func (e *Eth) GetTransactionByBlockHashAndIndex(hash ethgo.Hash, index uint64) (*ethgo.Transaction, error) {
+	var txn *ethgo.Transaction
+	err := e.c.CallContext(e.ctx, "eth_getTransactionByBlockHashAndIndex", &txn, hash, encodeUintToHex(index))
+	return txn, err
+}
+
+// GetBlockReceipts returns the receipts of all the transactions in a block
+func (e *Eth) GetBlockReceipts(block ethgo.BlockNumberOrHash) ([]*ethgo.Receipt, error) {
+	var out []*ethgo.Receipt
+	if err := e.c.CallContext(e.ctx, "eth_getBlockReceipts", &out, block.Location()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Sign signs the data with the key of an account unlocked in the node
+func (e *Eth) Sign(addr ethgo.Address, data []byte) ([]byte, error) {
+	var out string
+	if err := e.c.CallContext(e.ctx, "eth_sign", &out, addr, encodeToHex(data)); err != nil {
+		return nil, err
+	}
+	return parseHexBytes(out)
+}
+
+// SignedTransaction is a transaction signed by the node
+type SignedTransaction struct {
+	// Raw is the rlp encoded signed transaction
+	Raw []byte
+	Tx  *ethgo.Transaction
+}
+
+// SignTransaction signs a transaction with the key of an account unlocked in the node.
+// The transaction is not sent to the network.
+func (e *Eth) SignTransaction(txn *ethgo.Transaction) (*SignedTransaction, error) {
+	var out struct {
+		Raw string             `json:"raw"`
+		Tx  *ethgo.Transaction `json:"tx"`
+	}
+	if err := e.c.CallContext(e.ctx, "eth_signTransaction", &out, txn); err != nil {
+		return nil, err
+	}
+	raw, err := parseHexBytes(out.Raw)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTransaction{Raw: raw, Tx: out.Tx}, nil
+}
+
+// AccessListResult is the access list generated for a call
+type AccessListResult struct {
+	AccessList ethgo.AccessList
+	GasUsed    uint64
+	// Error is the error of the call, if any, while the list was generated
+	Error string
+}
+
+// CreateAccessList generates the access list of a call
+func (e *Eth) CreateAccessList(msg *ethgo.CallMsg, block ethgo.BlockNumberOrHash) (*AccessListResult, error) {
+	var out struct {
+		AccessList ethgo.AccessList `json:"accessList"`
+		GasUsed    string           `json:"gasUsed"`
+		Error      string           `json:"error"`
+	}
+	if err := e.c.CallContext(e.ctx, "eth_createAccessList", &out, msg, block.Location()); err != nil {
+		return nil, err
+	}
+	gasUsed, err := parseUint64orHex(out.GasUsed)
+	if err != nil {
+		return nil, err
+	}
+	res := &AccessListResult{
+		AccessList: out.AccessList,
+		GasUsed:    gasUsed,
+		Error:      out.Error,
+	}
+	return res, nil
+}
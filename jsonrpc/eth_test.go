@@ -363,3 +363,77 @@ func TestEthGetStorageAt(t *testing.T) {
 		assert.True(t, strings.HasSuffix(res.String(), "a"))
 	}
 }
+
+func TestEthTransactionByBlockAndIndex(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	receipt, err := s.ProcessBlockWithReceipt()
+	assert.NoError(t, err)
+
+	num, err := c.Eth().GetBlockTransactionCountByNumber(ethgo.BlockNumber(receipt.BlockNumber))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), num)
+
+	num, err = c.Eth().GetBlockTransactionCountByHash(receipt.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), num)
+
+	txn, err := c.Eth().GetTransactionByBlockNumberAndIndex(ethgo.BlockNumber(receipt.BlockNumber), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.TransactionHash, txn.Hash)
+
+	txn, err = c.Eth().GetTransactionByBlockHashAndIndex(receipt.BlockHash, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.TransactionHash, txn.Hash)
+
+	uncle, err := c.Eth().GetUncleByBlockNumberAndIndex(ethgo.BlockNumber(receipt.BlockNumber), 0)
+	assert.NoError(t, err)
+	assert.Nil(t, uncle)
+}
+
+func TestEthGetProof(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	balance, err := c.Eth().GetBalance(s.Account(0), ethgo.Latest)
+	assert.NoError(t, err)
+
+	proof, err := c.Eth().GetProof(s.Account(0), []ethgo.Hash{{0x1}}, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, s.Account(0), proof.Address)
+	assert.Equal(t, balance, proof.Balance)
+	assert.NotEmpty(t, proof.AccountProof)
+	assert.Len(t, proof.StorageProof, 1)
+	assert.Equal(t, ethgo.Hash{0x1}, proof.StorageProof[0].Key)
+}
+
+func TestEthSyncingAndProtocol(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	progress, err := c.Eth().Syncing()
+	assert.NoError(t, err)
+	assert.Nil(t, progress)
+
+	version, err := c.Eth().ProtocolVersion()
+	assert.NoError(t, err)
+	assert.NotZero(t, version)
+}
+
+func TestEthSign(t *testing.T) {
+	s := testutil.NewTestServer(t, nil)
+	defer s.Close()
+
+	c, _ := NewClient(s.HTTPAddr())
+
+	signature, err := c.Eth().Sign(s.Account(0), []byte("hello"))
+	assert.NoError(t, err)
+	assert.Len(t, signature, 65)
+}
@@ -0,0 +1,87 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+	"github.com/umbracle/ethgo/metrics"
+)
+
+// WithMetrics records the latency, the errors and the in-flight requests of the
+// client and the reconnections of the websocket and ipc transports.
+// Batch requests are recorded with the 'batch' method.
+func WithMetrics(recorder metrics.Recorder) ConfigOption {
+	return func(c *Config) {
+		c.metrics = recorder
+	}
+}
+
+// WithTracer starts a span for every request of the client. The span is
+// a child of the span in the context of the request (see Eth.WithContext).
+func WithTracer(tracer metrics.Tracer) ConfigOption {
+	return func(c *Config) {
+		c.tracer = tracer
+	}
+}
+
+func requestMethod(req *Request) string {
+	if req.Batch != nil {
+		return "batch"
+	}
+	return req.Method
+}
+
+func metricsMiddleware(recorder metrics.Recorder) Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(ctx context.Context, req *Request) (json.RawMessage, error) {
+			method := requestMethod(req)
+
+			recorder.RequestStarted(method)
+			now := time.Now()
+
+			raw, err := next(ctx, req)
+			recorder.RequestFinished(method, time.Since(now), err)
+
+			return raw, err
+		}
+	}
+}
+
+func tracerMiddleware(tracer metrics.Tracer) Middleware {
+	return func(next CallFunc) CallFunc {
+		return func(ctx context.Context, req *Request) (json.RawMessage, error) {
+			method := requestMethod(req)
+
+			ctx, span := tracer.Start(ctx, "jsonrpc "+method,
+				metrics.Attr("rpc.system", "jsonrpc"),
+				metrics.Attr("rpc.method", method),
+			)
+			if req.Batch != nil {
+				span.SetAttributes(metrics.Attr("rpc.batch.size", len(req.Batch)))
+			}
+
+			raw, err := next(ctx, req)
+			if code := metrics.ErrorCode(err); code != "" {
+				span.SetAttributes(metrics.Attr("rpc.jsonrpc.error_code", code))
+			}
+			metrics.EndSpan(span, err)
+
+			return raw, err
+		}
+	}
+}
+
+// trackReconnects records the reconnections of the transport, if it notifies them
+func trackReconnects(t transport.Transport, recorder metrics.Recorder) {
+	connState, ok := t.(transport.ConnStateTransport)
+	if !ok {
+		return
+	}
+	connState.OnConnState(func(state transport.ConnState) {
+		if state == transport.ConnStateConnected {
+			recorder.Reconnected()
+		}
+	})
+}
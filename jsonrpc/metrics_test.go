@@ -0,0 +1,132 @@
+package jsonrpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+	"github.com/umbracle/ethgo/metrics"
+)
+
+type testRecorder struct {
+	lock       sync.Mutex
+	started    []string
+	finished   []string
+	reconnects int
+}
+
+func (r *testRecorder) RequestStarted(method string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.started = append(r.started, method)
+}
+
+func (r *testRecorder) RequestFinished(method string, duration time.Duration, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.finished = append(r.finished, method+" "+metrics.ErrorCode(err))
+}
+
+func (r *testRecorder) Reconnected() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.reconnects++
+}
+
+type testSpan struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...metrics.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+type testSpanKey struct{}
+
+func (t *testTracer) Start(ctx context.Context, name string, attrs ...metrics.Attribute) (context.Context, metrics.Span) {
+	s := &testSpan{name: name, attrs: map[string]interface{}{}}
+	if parent, ok := ctx.Value(testSpanKey{}).(*testSpan); ok {
+		s.parent = parent.name
+	}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, s), s
+}
+
+type connStateTransport struct {
+	echoTransport
+	hook func(transport.ConnState)
+}
+
+func (c *connStateTransport) OnConnState(hook func(state transport.ConnState)) func() {
+	c.hook = hook
+	return func() {}
+}
+
+func TestClient_Metrics(t *testing.T) {
+	tr := &connStateTransport{}
+	r := &testRecorder{}
+	c := NewClientWithTransport(tr, WithMetrics(r))
+
+	var out string
+	assert.NoError(t, c.Call("eth_chainId", &out))
+	assert.Error(t, c.Call("eth_fail", &out))
+	assert.NoError(t, c.BatchCall([]BatchElem{{Method: "eth_chainId", Result: &out}}))
+
+	assert.Equal(t, []string{"eth_chainId", "eth_fail", "batch"}, r.started)
+	assert.Equal(t, []string{"eth_chainId ", "eth_fail transport", "batch "}, r.finished)
+
+	tr.hook(transport.ConnStateDisconnected)
+	tr.hook(transport.ConnStateConnected)
+	assert.Equal(t, 1, r.reconnects)
+}
+
+func TestClient_Tracer(t *testing.T) {
+	tracer := &testTracer{}
+	c := NewClientWithTransport(&echoTransport{}, WithTracer(tracer))
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, err := c.Eth().WithContext(ctx).GetNodeInfo()
+	assert.NoError(t, err)
+
+	var out string
+	assert.Error(t, c.CallContext(ctx, "eth_fail", &out))
+	parent.End()
+
+	assert.Len(t, tracer.spans, 3)
+	span := tracer.spans[1]
+	assert.Equal(t, "jsonrpc web3_clientVersion", span.name)
+	assert.Equal(t, "parent", span.parent)
+	assert.Equal(t, "web3_clientVersion", span.attrs["rpc.method"])
+	assert.True(t, span.ended)
+	assert.NoError(t, span.err)
+
+	span = tracer.spans[2]
+	assert.Equal(t, "parent", span.parent)
+	assert.Equal(t, "transport", span.attrs["rpc.jsonrpc.error_code"])
+	assert.Error(t, span.err)
+}
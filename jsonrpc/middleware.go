@@ -0,0 +1,71 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Request is a request intercepted by the middlewares of the client
+type Request struct {
+	// Method is the jsonrpc method. It is eth_subscribe for the subscriptions
+	// and empty for batch requests.
+	Method string
+
+	// Params are the parameters of the method. For the subscriptions it is
+	// the name of the subscription followed by its parameters.
+	Params []interface{}
+
+	// Batch are the calls of a batch request. The results and errors of
+	// each call are set on its element once next returns.
+	Batch []BatchElem
+}
+
+// CallFunc sends a request and returns the raw result. The raw result
+// is empty for the subscriptions and the batch requests.
+type CallFunc func(ctx context.Context, req *Request) (json.RawMessage, error)
+
+// Middleware wraps the CallFunc of the client to intercept the requests.
+// The context can be used to add headers to the request with
+// transport.WithRequestHeaders.
+type Middleware func(next CallFunc) CallFunc
+
+// WithMiddleware adds middlewares to the client. The first middleware
+// is the outermost one and it is the first to see the requests.
+func WithMiddleware(middlewares ...Middleware) ConfigOption {
+	return func(c *Config) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// intercept wraps the call with the middlewares of the client
+func (c *Client) intercept(call CallFunc) CallFunc {
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		call = c.middlewares[i](call)
+	}
+	return call
+}
+
+// invoke runs a call through the middlewares and decodes the result into out.
+// The context is only honoured by the transport if withContext is set.
+func (c *Client) invoke(ctx context.Context, withContext bool, method string, out interface{}, params []interface{}) error {
+	raw, err := c.intercept(func(ctx context.Context, req *Request) (json.RawMessage, error) {
+		var raw json.RawMessage
+		var err error
+		if withContext {
+			err = c.transport.CallContext(ctx, req.Method, &raw, req.Params...)
+		} else {
+			err = c.transport.Call(req.Method, &raw, req.Params...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return raw, nil
+	})(ctx, &Request{Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
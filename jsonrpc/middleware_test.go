@@ -0,0 +1,92 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// echoTransport is an in-memory transport that returns the
+// method name as the result of the calls
+type echoTransport struct {
+	pubSubTransport
+}
+
+func (e *echoTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return e.CallContext(context.Background(), method, out, params...)
+}
+
+func (e *echoTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if method == "eth_fail" {
+		return fmt.Errorf("failed")
+	}
+	data, err := json.Marshal(method)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func TestMiddleware_Call(t *testing.T) {
+	var calls []string
+
+	record := func(name string) Middleware {
+		return func(next CallFunc) CallFunc {
+			return func(ctx context.Context, req *Request) (json.RawMessage, error) {
+				calls = append(calls, name+" "+req.Method)
+				raw, err := next(ctx, req)
+				if err != nil {
+					calls = append(calls, name+" error")
+				} else {
+					calls = append(calls, name+" "+string(raw))
+				}
+				return raw, err
+			}
+		}
+	}
+
+	c := NewClientWithTransport(&echoTransport{}, WithMiddleware(record("a"), record("b")))
+
+	var out string
+	assert.NoError(t, c.Call("eth_chainId", &out))
+	assert.Equal(t, "eth_chainId", out)
+	assert.Equal(t, []string{"a eth_chainId", "b eth_chainId", `b "eth_chainId"`, `a "eth_chainId"`}, calls)
+
+	calls = nil
+	assert.Error(t, c.CallContext(context.Background(), "eth_fail", &out))
+	assert.Equal(t, []string{"a eth_fail", "b eth_fail", "b error", "a error"}, calls)
+
+	calls = nil
+	b := []BatchElem{{Method: "eth_chainId", Result: &out}}
+	assert.NoError(t, c.BatchCall(b))
+	assert.NoError(t, b[0].Error)
+	assert.Equal(t, []string{"a ", "b ", "b ", "a "}, calls)
+
+	calls = nil
+	_, err := c.Subscribe("newHeads", nil, func(b []byte) {})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a eth_subscribe", "b eth_subscribe", "b ", "a "}, calls)
+}
+
+func TestMiddleware_Reject(t *testing.T) {
+	reject := func(next CallFunc) CallFunc {
+		return func(ctx context.Context, req *Request) (json.RawMessage, error) {
+			if req.Method == "eth_sendRawTransaction" {
+				return nil, fmt.Errorf("method not allowed")
+			}
+			// rewrite the request
+			req.Method = "eth_blockNumber"
+			return next(ctx, req)
+		}
+	}
+
+	c := NewClientWithTransport(&echoTransport{}, WithMiddleware(reject))
+
+	var out string
+	assert.Error(t, c.Call("eth_sendRawTransaction", &out))
+	assert.NoError(t, c.Call("eth_chainId", &out))
+	assert.Equal(t, "eth_blockNumber", out)
+}
@@ -0,0 +1,85 @@
+package jsonrpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/umbracle/ethgo"
+)
+
+// Personal is the personal namespace to manage the accounts of the node (geth)
+type Personal struct {
+	c   *Client
+	ctx context.Context
+}
+
+// Personal returns the reference to the personal namespace
+func (c *Client) Personal() *Personal {
+	return c.endpoints.s
+}
+
+// WithContext returns a copy of the personal namespace whose calls are bound to ctx
+func (p *Personal) WithContext(ctx context.Context) *Personal {
+	p2 := *p
+	p2.ctx = ctx
+	return &p2
+}
+
+// ListAccounts returns the accounts of the keystore of the node
+func (p *Personal) ListAccounts() ([]ethgo.Address, error) {
+	var out []ethgo.Address
+	err := p.c.CallContext(p.ctx, "personal_listAccounts", &out)
+	return out, err
+}
+
+// NewAccount creates a new account in the keystore of the node encrypted with the password
+func (p *Personal) NewAccount(password string) (ethgo.Address, error) {
+	var out ethgo.Address
+	err := p.c.CallContext(p.ctx, "personal_newAccount", &out, password)
+	return out, err
+}
+
+// UnlockAccount unlocks the account for the duration. A zero duration
+// uses the default duration of the node. The duration is rounded up to
+// seconds since the node unlocks the account indefinitely with zero seconds.
+func (p *Personal) UnlockAccount(addr ethgo.Address, password string, duration time.Duration) (bool, error) {
+	var out bool
+	var err error
+	if duration == 0 {
+		err = p.c.CallContext(p.ctx, "personal_unlockAccount", &out, addr, password, nil)
+	} else {
+		seconds := uint64((duration + time.Second - 1) / time.Second)
+		err = p.c.CallContext(p.ctx, "personal_unlockAccount", &out, addr, password, seconds)
+	}
+	return out, err
+}
+
+// LockAccount locks the account
+func (p *Personal) LockAccount(addr ethgo.Address) (bool, error) {
+	var out bool
+	err := p.c.CallContext(p.ctx, "personal_lockAccount", &out, addr)
+	return out, err
+}
+
+// Sign signs the data with the key of the account as an EIP-191 personal message
+func (p *Personal) Sign(data []byte, addr ethgo.Address, password string) ([]byte, error) {
+	var out string
+	if err := p.c.CallContext(p.ctx, "personal_sign", &out, encodeToHex(data), addr, password); err != nil {
+		return nil, err
+	}
+	return parseHexBytes(out)
+}
+
+// EcRecover returns the account that signed the data with personal_sign
+func (p *Personal) EcRecover(data []byte, signature []byte) (ethgo.Address, error) {
+	var out ethgo.Address
+	err := p.c.CallContext(p.ctx, "personal_ecRecover", &out, encodeToHex(data), encodeToHex(signature))
+	return out, err
+}
+
+// SendTransaction unlocks the account of the sender with the password and sends the transaction
+func (p *Personal) SendTransaction(txn *ethgo.Transaction, password string) (ethgo.Hash, error) {
+	var hash ethgo.Hash
+	err := p.c.CallContext(p.ctx, "personal_sendTransaction", &hash, txn, password)
+	return hash, err
+}
@@ -0,0 +1,79 @@
+package jsonrpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/transport/mock"
+)
+
+func TestPersonal_Calls(t *testing.T) {
+	m := mock.New()
+	c := NewClientWithTransport(m)
+
+	addr := ethgo.Address{0x1}
+	m.On("personal_unlockAccount", addr, "pass", 60).Return(true).Once()
+	m.On("personal_unlockAccount", addr, "pass", nil).Return(true).Once()
+	m.On("personal_unlockAccount", addr, "pass", 1).Return(true).Once()
+	m.On("personal_unlockAccount", addr, "pass", 2).Return(true).Once()
+	m.On("personal_sign", "0x0102", addr, "pass").Return("0x0304")
+
+	ok, err := c.Personal().UnlockAccount(addr, "pass", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.Personal().UnlockAccount(addr, "pass", 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// sub-second durations do not unlock the account indefinitely
+	ok, err = c.Personal().UnlockAccount(addr, "pass", time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.Personal().UnlockAccount(addr, "pass", 1500*time.Millisecond)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	sig, err := c.Personal().Sign([]byte{0x1, 0x2}, addr, "pass")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x3, 0x4}, sig)
+
+	assert.True(t, m.AssertExpectations(t))
+}
+
+func TestAdmin_Calls(t *testing.T) {
+	m := mock.New()
+	c := NewClientWithTransport(m)
+
+	m.On("admin_addPeer", "enode://abc@127.0.0.1:30303").Return(true)
+	m.On("admin_nodeInfo").Return(map[string]interface{}{
+		"id":    "abc",
+		"enode": "enode://abc@127.0.0.1:30303",
+		"ports": map[string]int{"discovery": 30303, "listener": 30303},
+		"protocols": map[string]interface{}{
+			"eth": map[string]interface{}{"network": 1},
+		},
+	})
+	m.On("admin_peers").Return([]map[string]interface{}{
+		{"id": "def", "caps": []string{"eth/68"}, "network": map[string]interface{}{"inbound": true}},
+	})
+
+	ok, err := c.Admin().AddPeer("enode://abc@127.0.0.1:30303")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	info, err := c.Admin().NodeInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", info.ID)
+	assert.Equal(t, 30303, info.Ports.Listener)
+	assert.JSONEq(t, `{"network":1}`, string(info.Protocols["eth"]))
+
+	peers, err := c.Admin().Peers()
+	assert.NoError(t, err)
+	assert.Len(t, peers, 1)
+	assert.Equal(t, []string{"eth/68"}, peers[0].Caps)
+	assert.True(t, peers[0].Network.Inbound)
+}
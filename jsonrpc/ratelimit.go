@@ -0,0 +1,331 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is the client-side limit of the requests sent to the endpoint. The
+// requests wait until there is capacity for them instead of being rejected.
+type RateLimit struct {
+	// Rate is the number of request units per second of the client.
+	// Zero means no limit for the client.
+	Rate float64
+
+	// Burst is the maximum number of units sent at once. It defaults to Rate.
+	Burst float64
+
+	// Methods are the limits of specific methods, they apply on top of the limit
+	// of the client. The methods ending in '*' match the methods with that prefix
+	// (i.e. 'debug_trace*').
+	Methods map[string]*MethodRateLimit
+
+	// Weights are the units consumed by the requests of a method. The methods are
+	// matched as in Methods. Any other method consumes one unit.
+	Weights map[string]float64
+
+	// MaxInFlight is the maximum number of concurrent requests. A batch counts
+	// as a single request. Zero means no limit.
+	MaxInFlight int
+
+	// MinRate is the lowest rate that the limits are reduced to after the endpoint
+	// rate limits the requests. It defaults to a tenth of the rate.
+	MinRate float64
+
+	// RecoveryTime is the time the limits take to recover the full rate after the
+	// endpoint stops rate limiting the requests. It defaults to 30 seconds.
+	RecoveryTime time.Duration
+}
+
+// MethodRateLimit is the limit of the requests of a method
+type MethodRateLimit struct {
+	Rate  float64
+	Burst float64
+}
+
+// DefaultWeights are the weights of the expensive methods
+func DefaultWeights() map[string]float64 {
+	return map[string]float64{
+		"eth_getLogs":  10,
+		"debug_trace*": 20,
+		"trace_*":      20,
+	}
+}
+
+// DefaultRateLimit returns a limit of rate units per second with the default weights
+func DefaultRateLimit(rate float64) *RateLimit {
+	return &RateLimit{
+		Rate:    rate,
+		Weights: DefaultWeights(),
+	}
+}
+
+// WithRateLimit limits the rate and the concurrency of the requests of the client.
+// The limits are reduced every time the endpoint rate limits a request (see ErrRateLimited)
+// and they recover over time. The retries of WithRetry are limited too.
+func WithRateLimit(limit *RateLimit) ConfigOption {
+	return func(c *Config) {
+		c.rateLimit = limit
+	}
+}
+
+// matchMethod returns the most specific pattern that matches the method.
+// The patterns ending in '*' match by prefix.
+func matchMethod(patterns []string, method string) (string, bool) {
+	match, found := "", false
+	for _, pattern := range patterns {
+		if pattern == method {
+			return pattern, true
+		}
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+			if !found || len(pattern) > len(match) {
+				match, found = pattern, true
+			}
+		}
+	}
+	return match, found
+}
+
+// bucket is a token bucket whose rate is reduced when the endpoint
+// rate limits the requests and recovers linearly over time
+type bucket struct {
+	lock sync.Mutex
+
+	baseRate float64
+	minRate  float64
+	rate     float64
+	recovery float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(rate, burst float64, limit *RateLimit) *bucket {
+	if burst <= 0 {
+		burst = math.Max(rate, 1)
+	}
+	minRate := limit.MinRate
+	if minRate <= 0 || minRate > rate {
+		minRate = rate / 10
+	}
+	recoveryTime := limit.RecoveryTime
+	if recoveryTime <= 0 {
+		recoveryTime = 30 * time.Second
+	}
+	return &bucket{
+		baseRate: rate,
+		minRate:  minRate,
+		rate:     rate,
+		recovery: (rate - minRate) / recoveryTime.Seconds(),
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// advance refills the tokens and recovers the rate until now
+func (b *bucket) advance(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.last = now
+
+	b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	if b.rate < b.baseRate {
+		b.rate = math.Min(b.baseRate, b.rate+elapsed*b.recovery)
+	}
+}
+
+// reserve takes the tokens and returns the wait until they are available
+func (b *bucket) reserve(n float64) time.Duration {
+	return b.reserveAt(n, time.Now())
+}
+
+func (b *bucket) reserveAt(n float64, now time.Time) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.advance(now)
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns the tokens of a reservation that was not used
+func (b *bucket) cancel(n float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+n)
+}
+
+// backoff halves the rate and drops the available tokens
+func (b *bucket) backoff() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.advance(time.Now())
+	b.rate = math.Max(b.minRate, b.rate/2)
+	if b.tokens > 0 {
+		b.tokens = 0
+	}
+}
+
+type reservation struct {
+	bucket *bucket
+	tokens float64
+}
+
+type rateLimiter struct {
+	client   *bucket
+	methods  map[string]*bucket
+	patterns []string
+
+	weights        map[string]float64
+	weightPatterns []string
+
+	inFlight chan struct{}
+}
+
+func newRateLimiter(limit *RateLimit) *rateLimiter {
+	r := &rateLimiter{
+		methods: map[string]*bucket{},
+		weights: limit.Weights,
+	}
+	if limit.Rate > 0 {
+		r.client = newBucket(limit.Rate, limit.Burst, limit)
+	}
+	for method, l := range limit.Methods {
+		if l != nil && l.Rate > 0 {
+			r.methods[method] = newBucket(l.Rate, l.Burst, limit)
+			r.patterns = append(r.patterns, method)
+		}
+	}
+	for method := range limit.Weights {
+		r.weightPatterns = append(r.weightPatterns, method)
+	}
+	// sort the patterns to match them deterministically
+	sort.Strings(r.patterns)
+	sort.Strings(r.weightPatterns)
+
+	if limit.MaxInFlight > 0 {
+		r.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return r
+}
+
+func (r *rateLimiter) weight(method string) float64 {
+	if pattern, ok := matchMethod(r.weightPatterns, method); ok {
+		return r.weights[pattern]
+	}
+	return 1
+}
+
+// reservations returns the tokens to take from each bucket for the request
+func (r *rateLimiter) reservations(req *Request) []*reservation {
+	methods := []string{req.Method}
+	if req.Batch != nil {
+		methods = methods[:0]
+		for _, elem := range req.Batch {
+			methods = append(methods, elem.Method)
+		}
+	}
+
+	var res []*reservation
+	var total float64
+	for _, method := range methods {
+		weight := r.weight(method)
+		total += weight
+
+		if pattern, ok := matchMethod(r.patterns, method); ok {
+			res = append(res, &reservation{bucket: r.methods[pattern], tokens: weight})
+		}
+	}
+	if r.client != nil {
+		res = append(res, &reservation{bucket: r.client, tokens: total})
+	}
+	return res
+}
+
+// wait blocks until the request can be sent. The returned function
+// releases the in-flight slot of the request.
+func (r *rateLimiter) wait(ctx context.Context, res []*reservation) (func(), error) {
+	var delay time.Duration
+	for _, item := range res {
+		if d := item.bucket.reserve(item.tokens); d > delay {
+			delay = d
+		}
+	}
+	cancel := func() {
+		for _, item := range res {
+			item.bucket.cancel(item.tokens)
+		}
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+
+	if r.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case r.inFlight <- struct{}{}:
+		return func() { <-r.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// isRateLimited returns true if the endpoint rate limited the request
+// or any of the calls of the batch
+func isRateLimited(req *Request, err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	for _, elem := range req.Batch {
+		if errors.Is(elem.Error, ErrRateLimited) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitMiddleware waits until the request fits in the limits before sending it
+func rateLimitMiddleware(limit *RateLimit) Middleware {
+	r := newRateLimiter(limit)
+
+	return func(next CallFunc) CallFunc {
+		return func(ctx context.Context, req *Request) (json.RawMessage, error) {
+			res := r.reservations(req)
+
+			release, err := r.wait(ctx, res)
+			if err != nil {
+				return nil, err
+			}
+			raw, err := next(ctx, req)
+			release()
+
+			if isRateLimited(req, err) {
+				for _, item := range res {
+					item.bucket.backoff()
+				}
+			}
+			return raw, err
+		}
+	}
+}
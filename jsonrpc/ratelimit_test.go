@@ -0,0 +1,177 @@
+package jsonrpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestRateLimit_MatchMethod(t *testing.T) {
+	patterns := []string{"debug_*", "debug_trace*", "eth_getLogs"}
+
+	cases := map[string]string{
+		"eth_getLogs":            "eth_getLogs",
+		"debug_traceTransaction": "debug_trace*",
+		"debug_getRawBlock":      "debug_*",
+		"eth_call":               "",
+	}
+	for method, expected := range cases {
+		pattern, ok := matchMethod(patterns, method)
+		assert.Equal(t, expected != "", ok)
+		assert.Equal(t, expected, pattern)
+	}
+}
+
+func TestRateLimit_Wait(t *testing.T) {
+	limit := &RateLimit{
+		Rate:    20,
+		Burst:   1,
+		Weights: map[string]float64{"eth_getLogs": 4},
+	}
+	c := NewClientWithTransport(&echoTransport{}, WithRateLimit(limit))
+
+	var out string
+	assert.NoError(t, c.Call("eth_chainId", &out))
+
+	// the weight of eth_getLogs takes 4 tokens (200ms)
+	now := time.Now()
+	assert.NoError(t, c.Call("eth_getLogs", &out))
+	assert.GreaterOrEqual(t, int64(time.Since(now)), int64(100*time.Millisecond))
+
+	// a cancelled request returns its tokens
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, c.CallContext(ctx, "eth_getLogs", &out))
+}
+
+func TestRateLimit_Reserve(t *testing.T) {
+	r := newRateLimiter(&RateLimit{
+		Rate:    20,
+		Burst:   1,
+		Weights: map[string]float64{"eth_getLogs": 4},
+		Methods: map[string]*MethodRateLimit{
+			"debug_trace*": {Rate: 5, Burst: 1},
+		},
+	})
+	now := r.client.last
+
+	reserve := func(method string, now time.Time) (delay time.Duration) {
+		for _, item := range r.reservations(&Request{Method: method}) {
+			if d := item.bucket.reserveAt(item.tokens, now); d > delay {
+				delay = d
+			}
+		}
+		return
+	}
+
+	assert.Equal(t, time.Duration(0), reserve("eth_chainId", now))
+
+	// the weight of eth_getLogs takes 4 tokens
+	assert.Equal(t, 200*time.Millisecond, reserve("eth_getLogs", now))
+
+	// the method limit is slower than the client limit
+	now = now.Add(250 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), reserve("debug_traceTransaction", now))
+	assert.Equal(t, 200*time.Millisecond, reserve("debug_traceCall", now))
+}
+
+func TestRateLimit_Backoff(t *testing.T) {
+	b := newBucket(100, 10, &RateLimit{MinRate: 10, RecoveryTime: time.Second})
+
+	b.backoff()
+	assert.Equal(t, float64(50), b.rate)
+	assert.Equal(t, float64(0), b.tokens)
+
+	for i := 0; i < 5; i++ {
+		b.backoff()
+	}
+	assert.Equal(t, float64(10), b.rate)
+
+	// the rate recovers linearly
+	b.lock.Lock()
+	b.advance(b.last.Add(500 * time.Millisecond))
+	assert.Equal(t, float64(55), b.rate)
+	b.advance(b.last.Add(time.Second))
+	assert.Equal(t, float64(100), b.rate)
+	b.lock.Unlock()
+}
+
+// rateLimitedTransport rate limits the first request
+type rateLimitedTransport struct {
+	echoTransport
+	calls uint64
+}
+
+func (r *rateLimitedTransport) Call(method string, out interface{}, params ...interface{}) error {
+	if atomic.AddUint64(&r.calls, 1) == 1 {
+		return &codec.ErrorObject{Code: 429, Message: "too many requests"}
+	}
+	return r.echoTransport.Call(method, out, params...)
+}
+
+func TestRateLimit_Adaptive(t *testing.T) {
+	tr := &rateLimitedTransport{}
+	c := NewClientWithTransport(tr, WithRetry(&RetryPolicy{MaxAttempts: 2}), WithRateLimit(&RateLimit{Rate: 100}))
+
+	var out string
+	now := time.Now()
+	assert.NoError(t, c.Call("eth_chainId", &out))
+	assert.Equal(t, uint64(2), tr.calls)
+
+	// the retry waits for the tokens after the backoff (1 token at 50 per second)
+	assert.GreaterOrEqual(t, int64(time.Since(now)), int64(15*time.Millisecond))
+}
+
+// blockingTransport blocks the calls until the channel is closed
+type blockingTransport struct {
+	echoTransport
+	blockCh chan struct{}
+
+	lock     sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (b *blockingTransport) Call(method string, out interface{}, params ...interface{}) error {
+	b.lock.Lock()
+	b.inFlight++
+	if b.inFlight > b.max {
+		b.max = b.inFlight
+	}
+	b.lock.Unlock()
+
+	<-b.blockCh
+
+	b.lock.Lock()
+	b.inFlight--
+	b.lock.Unlock()
+
+	return b.echoTransport.Call(method, out, params...)
+}
+
+func TestRateLimit_MaxInFlight(t *testing.T) {
+	tr := &blockingTransport{blockCh: make(chan struct{})}
+	c := NewClientWithTransport(tr, WithRateLimit(&RateLimit{MaxInFlight: 2}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var out string
+			assert.NoError(t, c.Call("eth_chainId", &out))
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(tr.blockCh)
+	wg.Wait()
+
+	assert.Equal(t, 2, tr.max)
+}
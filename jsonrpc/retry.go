@@ -0,0 +1,121 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// RetryPolicy is the policy to retry the failed requests of the client
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request,
+	// including the first one
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. It doubles
+	// after every attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Retryable returns true if the request has to be retried. If nil,
+	// IsRetryable is used.
+	Retryable func(method string, err error) bool
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// WithRetry retries the transient failures of the requests with exponential backoff.
+// The retries run after the middlewares of the client.
+func WithRetry(policy *RetryPolicy) ConfigOption {
+	return func(c *Config) {
+		c.retry = policy
+	}
+}
+
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := r.MinBackoff
+	for i := 0; i < attempt && backoff < r.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if r.MaxBackoff != 0 && backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+	return backoff
+}
+
+// IsRetryable returns true if the error is a transient failure: the endpoint is rate
+// limiting the requests, it is behind the requested block or the connection failed.
+// The methods that send transactions are only retried when rate limited since on
+// any other failure the transaction could have been received.
+func IsRetryable(method string, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrHeaderNotFound) {
+		return true
+	}
+	if transport.IsSendMethod(method) {
+		return false
+	}
+
+	var httpErr *transport.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, transport.ErrTimeout) ||
+		errors.Is(err, transport.ErrConnectionLost)
+}
+
+// retryMiddleware retries the requests that fail with a retryable error
+func retryMiddleware(policy *RetryPolicy) Middleware {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+
+	return func(next CallFunc) CallFunc {
+		return func(ctx context.Context, req *Request) (json.RawMessage, error) {
+			method := req.Method
+			for _, elem := range req.Batch {
+				// a batch is retried as a whole, it is classified by the
+				// method that sends transactions if there is any
+				method = elem.Method
+				if transport.IsSendMethod(method) {
+					break
+				}
+			}
+
+			for attempt := 0; ; attempt++ {
+				raw, err := next(ctx, req)
+				if err == nil || attempt+1 >= policy.MaxAttempts || !retryable(method, err) {
+					return raw, err
+				}
+
+				select {
+				case <-time.After(policy.backoff(attempt)):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+		}
+	}
+}
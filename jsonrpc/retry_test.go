@@ -0,0 +1,164 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// failingTransport fails the first calls with the given error
+type failingTransport struct {
+	echoTransport
+
+	err   error
+	fails int
+	calls int
+}
+
+func (f *failingTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return f.CallContext(context.Background(), method, out, params...)
+}
+
+func (f *failingTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	f.calls++
+	if f.calls <= f.fails {
+		return f.err
+	}
+	return f.echoTransport.CallContext(ctx, method, out, params...)
+}
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+func TestRetry_Transient(t *testing.T) {
+	tt := &failingTransport{
+		err:   &codec.ErrorObject{Code: -32005, Message: "daily request count exceeded, request rate limited"},
+		fails: 2,
+	}
+	c := NewClientWithTransport(tt, WithRetry(testRetryPolicy()))
+
+	var out string
+	assert.NoError(t, c.Call("eth_chainId", &out))
+	assert.Equal(t, "eth_chainId", out)
+	assert.Equal(t, 3, tt.calls)
+
+	// the attempts are exhausted
+	tt.calls, tt.fails = 0, 5
+	err := c.Call("eth_chainId", &out)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, 3, tt.calls)
+}
+
+func TestRetry_NotRetryable(t *testing.T) {
+	tt := &failingTransport{
+		err:   &codec.ErrorObject{Code: -32000, Message: "nonce too low"},
+		fails: 1,
+	}
+	c := NewClientWithTransport(tt, WithRetry(testRetryPolicy()))
+
+	var out string
+	err := c.Call("eth_sendRawTransaction", &out)
+	assert.True(t, errors.Is(err, ErrNonceTooLow))
+	assert.Equal(t, 1, tt.calls)
+
+	// connection errors are not retried for the methods that send transactions
+	tt.calls, tt.err = 0, transport.ErrConnectionLost
+	assert.Error(t, c.Call("eth_sendRawTransaction", &out))
+	assert.Equal(t, 1, tt.calls)
+
+	tt.calls = 0
+	assert.NoError(t, c.Call("eth_chainId", &out))
+	assert.Equal(t, 2, tt.calls)
+}
+
+func TestRetry_IsRetryable(t *testing.T) {
+	cases := []struct {
+		method    string
+		err       error
+		retryable bool
+	}{
+		{"eth_chainId", &transport.HTTPError{StatusCode: 502}, true},
+		{"eth_chainId", &transport.HTTPError{StatusCode: 400}, false},
+		{"eth_chainId", transport.ErrTimeout, true},
+		{"eth_sendRawTransaction", &transport.HTTPError{StatusCode: 429}, true},
+		{"eth_sendRawTransaction", &transport.HTTPError{StatusCode: 502}, false},
+		{"eth_sendRawTransaction", &transport.HTTPError{StatusCode: 504}, false},
+		{"eth_sendTransaction", transport.ErrTimeout, false},
+		{"eth_sendTransaction", &codec.ErrorObject{Code: 429}, true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.retryable, IsRetryable(c.method, c.err), fmt.Sprintf("%s %v", c.method, c.err))
+	}
+}
+
+func TestRetry_Context(t *testing.T) {
+	tt := &failingTransport{
+		err:   &codec.ErrorObject{Message: "header not found"},
+		fails: 10,
+	}
+	policy := testRetryPolicy()
+	policy.MaxAttempts = 10
+	policy.MinBackoff = time.Second
+	policy.MaxBackoff = time.Second
+
+	c := NewClientWithTransport(tt, WithRetry(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var out string
+	assert.Equal(t, context.DeadlineExceeded, c.CallContext(ctx, "eth_getBlockByNumber", &out))
+	assert.Equal(t, 1, tt.calls)
+}
+
+func TestErrors_Classify(t *testing.T) {
+	cases := []struct {
+		obj *codec.ErrorObject
+		err error
+	}{
+		{&codec.ErrorObject{Code: 429, Message: "Too Many Requests"}, ErrRateLimited},
+		{&codec.ErrorObject{Code: -32005, Message: "query returned more than 10000 results"}, ErrLimitExceeded},
+		{&codec.ErrorObject{Code: -32000, Message: "not found"}, ErrNotFound},
+		{&codec.ErrorObject{Code: -32000, Message: "header not found"}, ErrHeaderNotFound},
+		{&codec.ErrorObject{Code: -32000, Message: "replacement transaction underpriced"}, ErrUnderpriced},
+		{&codec.ErrorObject{Code: -32000, Message: "nonce too low: address 0x1, tx: 1 state: 2"}, ErrNonceTooLow},
+		{&codec.ErrorObject{Code: 3, Message: "execution reverted"}, ErrExecutionReverted},
+	}
+	for _, c := range cases {
+		assert.True(t, errors.Is(c.obj, c.err), c.obj.Message)
+		assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", c.obj), c.err), c.obj.Message)
+	}
+	assert.False(t, errors.Is(&codec.ErrorObject{Code: -32000, Message: "unknown"}, ErrNotFound))
+}
+
+func TestErrors_Revert(t *testing.T) {
+	tt := &failingTransport{
+		err: &codec.ErrorObject{
+			Code:    3,
+			Message: "execution reverted: revert reason",
+			Data:    "0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d72657665727420726561736f6e00000000000000000000000000000000000000",
+		},
+		fails: 1,
+	}
+	c := NewClientWithTransport(tt)
+
+	var out string
+	err := c.Call("eth_call", &out)
+	assert.True(t, errors.Is(err, ErrExecutionReverted))
+
+	var revertErr *RevertError
+	assert.True(t, errors.As(err, &revertErr))
+	assert.Equal(t, "revert reason", revertErr.Reason)
+	assert.Equal(t, "execution reverted: revert reason", revertErr.Error())
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// connCodec reads and writes the messages of a stream connection
+type connCodec interface {
+	Read() ([]byte, error)
+	Write(b []byte) error
+	Close() error
+}
+
+// conn is a websocket or ipc connection with its subscriptions
+type conn struct {
+	codec  connCodec
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeLock sync.Mutex
+
+	lock sync.Mutex
+	subs map[string]*Subscription
+}
+
+// serveConn serves the requests of a stream connection until it is closed
+func (s *Server) serveConn(codec connCodec) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &conn{
+		codec:  codec,
+		ctx:    ctx,
+		cancel: cancel,
+		subs:   map[string]*Subscription{},
+	}
+
+	s.connsLock.Lock()
+	s.conns[c] = struct{}{}
+	s.connsLock.Unlock()
+
+	defer func() {
+		s.connsLock.Lock()
+		delete(s.conns, c)
+		s.connsLock.Unlock()
+
+		c.close()
+	}()
+
+	for {
+		msg, err := codec.Read()
+		if err != nil {
+			return
+		}
+		go func() {
+			resp, done := s.handleMessage(ctx, c, msg)
+			if resp != nil {
+				if err := c.write(resp); err != nil {
+					c.close()
+					return
+				}
+			}
+			if done != nil {
+				done()
+			}
+		}()
+	}
+}
+
+func (c *conn) write(b []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	return c.codec.Write(b)
+}
+
+// close closes the connection and its subscriptions
+func (c *conn) close() {
+	c.cancel()
+	c.codec.Close()
+
+	c.lock.Lock()
+	subs := c.subs
+	c.subs = map[string]*Subscription{}
+	c.lock.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+}
+
+func (c *conn) newSubscription(namespace string) *Subscription {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	sub := &Subscription{
+		id:        "0x" + hex.EncodeToString(buf),
+		namespace: namespace,
+		c:         c,
+		closeCh:   make(chan struct{}),
+	}
+
+	c.lock.Lock()
+	c.subs[sub.id] = sub
+	c.lock.Unlock()
+	return sub
+}
+
+func (c *conn) removeSubscription(id string) bool {
+	c.lock.Lock()
+	sub, ok := c.subs[id]
+	delete(c.subs, id)
+	c.lock.Unlock()
+
+	if ok {
+		sub.close()
+	}
+	return ok
+}
+
+// ErrSubscriptionClosed happens when a notification is sent on a closed subscription
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+// Subscription is a subscription started by a client. The subscription methods
+// send notifications with Notify until the subscription is closed.
+type Subscription struct {
+	id        string
+	namespace string
+	c         *conn
+
+	// the notifications sent before the client receives the id of the
+	// subscription are held until then
+	lock    sync.Mutex
+	active  bool
+	pending [][]byte
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// ID returns the id of the subscription
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// Closed returns a channel that is closed once the client unsubscribes
+// or the connection is closed
+func (s *Subscription) Closed() <-chan struct{} {
+	return s.closeCh
+}
+
+type notification struct {
+	JsonRPC string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  notificationParams `json:"params"`
+}
+
+type notificationParams struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+// Notify sends a notification to the client. The notifications sent before
+// the client receives the id of the subscription (i.e. from the subscription
+// method itself) are held and sent in order right after the id.
+func (s *Subscription) Notify(result interface{}) error {
+	data, err := json.Marshal(&notification{
+		JsonRPC: "2.0",
+		Method:  s.namespace + "_subscription",
+		Params: notificationParams{
+			Subscription: s.id,
+			Result:       result,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	select {
+	case <-s.closeCh:
+		return ErrSubscriptionClosed
+	default:
+	}
+	if !s.active {
+		s.pending = append(s.pending, data)
+		return nil
+	}
+	return s.c.write(data)
+}
+
+// activate sends the notifications held until the client received the id
+func (s *Subscription) activate() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.active {
+		return
+	}
+	s.active = true
+
+	pending := s.pending
+	s.pending = nil
+	for _, data := range pending {
+		if err := s.c.write(data); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
+}
+
+// websocketCodec is the codec of a websocket connection
+type websocketCodec struct {
+	conn *websocket.Conn
+}
+
+func (w *websocketCodec) Read() ([]byte, error) {
+	_, data, err := w.conn.ReadMessage()
+	return data, err
+}
+
+func (w *websocketCodec) Write(b []byte) error {
+	return w.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+func (w *websocketCodec) Close() error {
+	return w.conn.Close()
+}
+
+// ipcCodec is the codec of an ipc connection
+type ipcCodec struct {
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+func (i *ipcCodec) Read() ([]byte, error) {
+	var msg json.RawMessage
+	if err := i.dec.Decode(&msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (i *ipcCodec) Write(b []byte) error {
+	_, err := i.conn.Write(b)
+	return err
+}
+
+func (i *ipcCodec) Close() error {
+	return i.conn.Close()
+}
+
+// ServeListener serves the ipc connections of the listener (i.e. an unix socket)
+// until the listener is closed
+func (s *Server) ServeListener(l net.Listener) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(&ipcCodec{conn: c, dec: json.NewDecoder(c)})
+	}
+}
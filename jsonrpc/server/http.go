@@ -0,0 +1,78 @@
+package server
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxRequestSize is the maximum size of the body of an http request
+const maxRequestSize = 5 * 1024 * 1024
+
+// checkOrigin accepts the websocket connections without an origin (i.e. not
+// from a browser), from the origin of the server and from the allowed origins.
+// It prevents any web page from opening a connection with the server.
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// ServeHTTP implements the http.Handler interface. The websocket upgrade
+// requests are served as websocket connections.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := s.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.serveConn(&websocketCodec{conn: conn})
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid gzip body: %v", err), http.StatusBadRequest)
+			return
+		}
+		body = io.LimitReader(zr, maxRequestSize)
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	resp, _ := s.handleMessage(r.Context(), nil, data)
+	if resp == nil {
+		// only notifications
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// DialInProc returns a transport connected to the server through an
+// in-process pipe. It supports subscriptions like the ipc transport.
+func (s *Server) DialInProc() (transport.Transport, error) {
+	return transport.NewConn(func() (net.Conn, error) {
+		client, conn := net.Pipe()
+		go s.serveConn(&ipcCodec{conn: conn, dec: json.NewDecoder(conn)})
+		return client, nil
+	})
+}
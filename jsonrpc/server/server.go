@@ -0,0 +1,368 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// jsonrpc error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+	codeServerError    = -32000
+)
+
+// Config is the configuration of the server
+type Config struct {
+	// AllowedOrigins are the origins (i.e. 'https://app.example.com') of the
+	// browsers that can open websocket connections besides the origin of the
+	// server itself. '*' allows any origin.
+	AllowedOrigins []string
+}
+
+// ServerOption is an option of the server
+type ServerOption func(*Config)
+
+// WithAllowedOrigins allows the websocket connections from the origins
+func WithAllowedOrigins(origins ...string) ServerOption {
+	return func(c *Config) {
+		c.AllowedOrigins = append(c.AllowedOrigins, origins...)
+	}
+}
+
+// Server is a jsonrpc server that serves the methods of the
+// registered receivers over http, websocket and ipc
+type Server struct {
+	config   *Config
+	upgrader *websocket.Upgrader
+
+	lock     sync.RWMutex
+	services map[string]*service
+
+	connsLock sync.Mutex
+	conns     map[*conn]struct{}
+}
+
+// NewServer creates a new jsonrpc server. By default, the websocket connections
+// are only accepted from the origin of the server (see WithAllowedOrigins).
+func NewServer(opts ...ServerOption) *Server {
+	config := &Config{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	s := &Server{
+		config:   config,
+		services: map[string]*service{},
+		conns:    map[*conn]struct{}{},
+	}
+	s.upgrader = &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     s.checkOrigin,
+	}
+	return s
+}
+
+// Register registers the exported methods of the receiver under the namespace.
+// The method BlockNumber of the namespace eth is served as eth_blockNumber.
+// The methods whose first argument (after an optional context) is a *Subscription
+// are started with <namespace>_subscribe. See newMethod for the valid signatures
+// and MethodNamer to set the names of the methods.
+func (s *Server) Register(namespace string, receiver interface{}) error {
+	if namespace == "" {
+		return fmt.Errorf("empty namespace")
+	}
+	srv, err := newService(receiver)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.services[namespace]; ok {
+		return fmt.Errorf("namespace %s already registered", namespace)
+	}
+	s.services[namespace] = srv
+	return nil
+}
+
+// Stop closes the websocket and ipc connections
+func (s *Server) Stop() {
+	s.connsLock.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.connsLock.Unlock()
+
+	for _, c := range conns {
+		c.close()
+	}
+}
+
+// ErrorCoder is implemented by the errors that set the jsonrpc error code
+type ErrorCoder interface {
+	ErrorCode() int
+}
+
+// ErrorDataer is implemented by the errors that include data in the jsonrpc error
+type ErrorDataer interface {
+	ErrorData() interface{}
+}
+
+func toErrorObject(err error) *codec.ErrorObject {
+	if obj, ok := err.(*codec.ErrorObject); ok {
+		return obj
+	}
+	obj := &codec.ErrorObject{
+		Code:    codeServerError,
+		Message: err.Error(),
+	}
+	if coder, ok := err.(ErrorCoder); ok {
+		obj.Code = coder.ErrorCode()
+	}
+	if dataer, ok := err.(ErrorDataer); ok {
+		obj.Data = dataer.ErrorData()
+	}
+	return obj
+}
+
+// request is a jsonrpc request received by the server. The id is kept
+// raw since it can be a number, a string or null. A request without an
+// id is a notification and it does not have a response.
+type request struct {
+	JsonRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+func (r *request) isNotification() bool {
+	return r.ID == nil
+}
+
+// validID returns true if the id is a number, a string or null
+func validID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
+// response is a jsonrpc response of the server with the id of the request
+type response struct {
+	JsonRPC string             `json:"jsonrpc"`
+	ID      json.RawMessage    `json:"id"`
+	Result  json.RawMessage    `json:"result,omitempty"`
+	Error   *codec.ErrorObject `json:"error,omitempty"`
+}
+
+func errorResponse(id json.RawMessage, code int, format string, args ...interface{}) *response {
+	return &response{
+		JsonRPC: "2.0",
+		ID:      id,
+		Error: &codec.ErrorObject{
+			Code:    code,
+			Message: fmt.Sprintf(format, args...),
+		},
+	}
+}
+
+// handleMessage handles a single request or a batch and returns the encoded response,
+// nil if all the requests are notifications. The returned function has to be called
+// once the response is written.
+func (s *Server) handleMessage(ctx context.Context, c *conn, raw []byte) ([]byte, func()) {
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) == 0 || raw[0] != '[' {
+		resp, done := s.handleRequest(ctx, c, raw)
+		if resp == nil {
+			return nil, done
+		}
+		return encodeResponse(resp), done
+	}
+
+	var msgs []json.RawMessage
+	if err := json.Unmarshal(raw, &msgs); err != nil {
+		return encodeResponse(errorResponse(nil, codeParseError, "parse error: %v", err)), nil
+	}
+	if len(msgs) == 0 {
+		return encodeResponse(errorResponse(nil, codeInvalidRequest, "empty batch")), nil
+	}
+
+	resps := make([]*response, 0, len(msgs))
+	dones := []func(){}
+	for _, msg := range msgs {
+		resp, done := s.handleRequest(ctx, c, msg)
+		if resp != nil {
+			resps = append(resps, resp)
+		}
+		if done != nil {
+			dones = append(dones, done)
+		}
+	}
+	doneAll := func() {
+		for _, done := range dones {
+			done()
+		}
+	}
+	if len(resps) == 0 {
+		return nil, doneAll
+	}
+	data, err := json.Marshal(resps)
+	if err != nil {
+		return encodeResponse(errorResponse(nil, codeInternalError, "failed to encode response: %v", err)), nil
+	}
+	return data, doneAll
+}
+
+func encodeResponse(resp *response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(resp.ID, codeInternalError, "failed to encode response: %v", err))
+	}
+	return data
+}
+
+// handleRequest handles a request and returns its response, nil for the notifications
+func (s *Server) handleRequest(ctx context.Context, c *conn, raw []byte) (*response, func()) {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, codeParseError, "parse error: %v", err), nil
+	}
+	if !validID(req.ID) {
+		return errorResponse(nil, codeInvalidRequest, "invalid id %s", string(req.ID)), nil
+	}
+
+	resp, done := s.handleCall(ctx, c, &req)
+	if req.isNotification() {
+		return nil, done
+	}
+	return resp, done
+}
+
+func (s *Server) handleCall(ctx context.Context, c *conn, req *request) (*response, func()) {
+	if req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "empty method"), nil
+	}
+
+	namespace, name, ok := splitMethod(req.Method)
+	if !ok {
+		return errorResponse(req.ID, codeMethodNotFound, "the method %s does not exist/is not available", req.Method), nil
+	}
+
+	s.lock.RLock()
+	srv, ok := s.services[namespace]
+	s.lock.RUnlock()
+	if !ok {
+		return errorResponse(req.ID, codeMethodNotFound, "the method %s does not exist/is not available", req.Method), nil
+	}
+
+	switch name {
+	case "subscribe":
+		return s.handleSubscribe(c, srv, namespace, req)
+	case "unsubscribe":
+		return s.handleUnsubscribe(c, req), nil
+	}
+
+	m, ok := srv.methods[name]
+	if !ok {
+		return errorResponse(req.ID, codeMethodNotFound, "the method %s does not exist/is not available", req.Method), nil
+	}
+	args, err := m.decodeParams(req.Params)
+	if err != nil {
+		return errorResponse(req.ID, codeInvalidParams, "%v", err), nil
+	}
+	return s.call(ctx, m, req, nil, args), nil
+}
+
+// call runs the method and builds the response
+func (s *Server) call(ctx context.Context, m *method, req *request, sub *Subscription, args []reflect.Value) (resp *response) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp = errorResponse(req.ID, codeInternalError, "method handler crashed: %v", r)
+		}
+	}()
+
+	result, err := m.call(ctx, sub, args)
+	if err != nil {
+		return &response{JsonRPC: "2.0", ID: req.ID, Error: toErrorObject(err)}
+	}
+	if sub != nil {
+		result = sub.id
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, codeInternalError, "failed to encode result: %v", err)
+	}
+	return &response{JsonRPC: "2.0", ID: req.ID, Result: data}
+}
+
+func (s *Server) handleSubscribe(c *conn, srv *service, namespace string, req *request) (*response, func()) {
+	if c == nil {
+		return errorResponse(req.ID, codeMethodNotFound, "notifications not supported"), nil
+	}
+
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 {
+		return errorResponse(req.ID, codeInvalidParams, "first argument must be the subscription name"), nil
+	}
+	var name string
+	if err := json.Unmarshal(params[0], &name); err != nil {
+		return errorResponse(req.ID, codeInvalidParams, "invalid subscription name: %v", err), nil
+	}
+	m, ok := srv.subscriptions[name]
+	if !ok {
+		return errorResponse(req.ID, codeMethodNotFound, "no %q subscription in %s namespace", name, namespace), nil
+	}
+
+	var rest json.RawMessage
+	if len(params) > 1 {
+		data, err := json.Marshal(params[1:])
+		if err != nil {
+			return errorResponse(req.ID, codeInvalidParams, "%v", err), nil
+		}
+		rest = data
+	}
+	args, err := m.decodeParams(rest)
+	if err != nil {
+		return errorResponse(req.ID, codeInvalidParams, "%v", err), nil
+	}
+
+	sub := c.newSubscription(namespace)
+	resp := s.call(c.ctx, m, req, sub, args)
+	if resp.Error != nil {
+		c.removeSubscription(sub.id)
+		return resp, nil
+	}
+	// the notifications are sent once the client knows the id of the subscription
+	return resp, sub.activate
+}
+
+func (s *Server) handleUnsubscribe(c *conn, req *request) *response {
+	if c == nil {
+		return errorResponse(req.ID, codeMethodNotFound, "notifications not supported")
+	}
+	var params []string
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+		return errorResponse(req.ID, codeInvalidParams, "expected the subscription id")
+	}
+	found := c.removeSubscription(params[0])
+	data, _ := json.Marshal(found)
+	return &response{JsonRPC: "2.0", ID: req.ID, Result: data}
+}
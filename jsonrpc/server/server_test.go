@@ -0,0 +1,369 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+type testService struct{}
+
+func (t *testService) BlockNumber() (string, error) {
+	return "0xa", nil
+}
+
+func (t *testService) GetBalance(ctx context.Context, addr ethgo.Address, block *string) (string, error) {
+	if block != nil && *block != "latest" {
+		return "", fmt.Errorf("block %s not found", *block)
+	}
+	return "0x" + fmt.Sprintf("%x", addr[0]), nil
+}
+
+type codedError struct{}
+
+func (c *codedError) Error() string  { return "execution reverted" }
+func (c *codedError) ErrorCode() int { return 3 }
+func (c *codedError) ErrorData() interface{} {
+	return "0x01"
+}
+
+func (t *testService) Call() (string, error) {
+	return "", &codedError{}
+}
+
+func (t *testService) Panic() error {
+	panic("bad")
+}
+
+func (t *testService) Counter(sub *Subscription, from uint64) error {
+	go func() {
+		for i := from; ; i++ {
+			if err := sub.Notify(fmt.Sprintf("0x%x", i)); err != nil {
+				return
+			}
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-sub.Closed():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Sync sends the first notifications from the subscription method itself
+func (t *testService) Sync(sub *Subscription, n uint64) error {
+	for i := uint64(0); i < n; i++ {
+		if err := sub.Notify(fmt.Sprintf("0x%x", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestServer(t *testing.T) *Server {
+	s := NewServer()
+	assert.NoError(t, s.Register("eth", &testService{}))
+	return s
+}
+
+func testClient(t *testing.T, c *jsonrpc.Client) {
+	num, err := c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), num)
+
+	balance, err := c.Eth().GetBalance(ethgo.Address{0x5}, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), balance.Uint64())
+
+	_, err = c.Eth().GetBalance(ethgo.Address{0x5}, ethgo.BlockNumber(1))
+	assert.Error(t, err)
+
+	// the trailing pointer arguments are optional
+	var out string
+	assert.NoError(t, c.Call("eth_getBalance", &out, ethgo.Address{0x1}))
+	assert.Equal(t, "0x1", out)
+
+	err = c.Call("eth_call", &out)
+	assert.True(t, errors.Is(err, jsonrpc.ErrExecutionReverted))
+
+	var obj *codec.ErrorObject
+	assert.True(t, errors.As(err, &obj))
+	assert.Equal(t, "0x01", obj.Data)
+
+	err = c.Call("eth_unknown", &out)
+	assert.True(t, errors.As(err, &obj))
+	assert.Equal(t, codeMethodNotFound, obj.Code)
+
+	err = c.Call("eth_blockNumber", &out, 1)
+	assert.True(t, errors.As(err, &obj))
+	assert.Equal(t, codeInvalidParams, obj.Code)
+
+	err = c.Call("eth_panic", &out)
+	assert.True(t, errors.As(err, &obj))
+	assert.Equal(t, codeInternalError, obj.Code)
+
+	var num1, num2 string
+	b := []jsonrpc.BatchElem{
+		{Method: "eth_blockNumber", Result: &num1},
+		{Method: "eth_unknown"},
+		{Method: "eth_getBalance", Params: []interface{}{ethgo.Address{0x2}}, Result: &num2},
+	}
+	assert.NoError(t, c.BatchCall(b))
+	assert.NoError(t, b[0].Error)
+	assert.Error(t, b[1].Error)
+	assert.NoError(t, b[2].Error)
+	assert.Equal(t, "0xa", num1)
+	assert.Equal(t, "0x2", num2)
+}
+
+func testSubscription(t *testing.T, c *jsonrpc.Client) {
+	ch := make(chan string, 10)
+	cancel, err := c.Subscribe("counter", uint64(5), func(b []byte) {
+		ch <- strings.Trim(string(b), `"`)
+	})
+	assert.NoError(t, err)
+
+	for i := 5; i < 8; i++ {
+		select {
+		case val := <-ch:
+			assert.Equal(t, fmt.Sprintf("0x%x", i), val)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	assert.NoError(t, cancel())
+}
+
+func TestServer_HTTP(t *testing.T) {
+	srv := httptest.NewServer(newTestServer(t))
+	defer srv.Close()
+
+	c, err := jsonrpc.NewClient(srv.URL)
+	assert.NoError(t, err)
+	testClient(t, c)
+
+	// the net/http client compresses the requests
+	c, err = jsonrpc.NewClient(srv.URL, jsonrpc.WithNetHTTP(&transport.NetHTTPConfig{GzipRequests: true}))
+	assert.NoError(t, err)
+	testClient(t, c)
+}
+
+func TestServer_Websocket(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Stop()
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c, err := jsonrpc.NewClient("ws://" + strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	defer c.Close()
+
+	testClient(t, c)
+	testSubscription(t, c)
+}
+
+func TestServer_IPC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ethgo-ipc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.ipc")
+	l, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer l.Close()
+
+	s := newTestServer(t)
+	defer s.Stop()
+
+	go s.ServeListener(l)
+
+	c, err := jsonrpc.NewClient(path)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	testClient(t, c)
+	testSubscription(t, c)
+}
+
+func TestServer_InProc(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Stop()
+
+	tr, err := s.DialInProc()
+	assert.NoError(t, err)
+
+	c := jsonrpc.NewClientWithTransport(tr)
+	defer c.Close()
+
+	testClient(t, c)
+	testSubscription(t, c)
+}
+
+func TestServer_Register(t *testing.T) {
+	s := NewServer()
+	assert.NoError(t, s.Register("eth", &testService{}))
+	assert.Error(t, s.Register("eth", &testService{}))
+	assert.Error(t, s.Register("", &testService{}))
+	assert.Error(t, s.Register("net", struct{}{}))
+}
+
+func TestServer_MethodName(t *testing.T) {
+	cases := map[string]string{
+		"BlockNumber": "blockNumber",
+		"ChainID":     "chainID",
+		"EVMCall":     "evmCall",
+		"ID":          "id",
+		"X":           "x",
+	}
+	for name, expected := range cases {
+		assert.Equal(t, expected, methodName(name))
+	}
+}
+
+type namedService struct{}
+
+func (n *namedService) ChainID() (string, error) {
+	return "0x1", nil
+}
+
+func (n *namedService) MethodNames() map[string]string {
+	return map[string]string{"ChainID": "chainId"}
+}
+
+func TestServer_MethodNamer(t *testing.T) {
+	srv, err := newService(&namedService{})
+	assert.NoError(t, err)
+
+	_, ok := srv.methods["chainId"]
+	assert.True(t, ok)
+	assert.Len(t, srv.methods, 1)
+}
+
+func TestServer_WebsocketOrigin(t *testing.T) {
+	dial := func(s *Server, origin string) error {
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+strings.TrimPrefix(srv.URL, "http://"), header)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	s := newTestServer(t)
+	defer s.Stop()
+
+	// non browser clients do not send an origin
+	assert.NoError(t, dial(s, ""))
+	assert.Error(t, dial(s, "https://evil.example.com"))
+
+	s2 := NewServer(WithAllowedOrigins("https://app.example.com"))
+	defer s2.Stop()
+
+	assert.NoError(t, dial(s2, "https://app.example.com"))
+	assert.Error(t, dial(s2, "https://evil.example.com"))
+
+	s3 := NewServer(WithAllowedOrigins("*"))
+	defer s3.Stop()
+
+	assert.NoError(t, dial(s3, "https://evil.example.com"))
+}
+
+func TestServer_CheckOrigin(t *testing.T) {
+	s := NewServer()
+
+	req := httptest.NewRequest("GET", "http://localhost:8545", nil)
+	req.Header.Set("Origin", "http://localhost:8545")
+	assert.True(t, s.checkOrigin(req))
+
+	req.Header.Set("Origin", "http://localhost:3000")
+	assert.False(t, s.checkOrigin(req))
+}
+
+func TestServer_RequestID(t *testing.T) {
+	s := newTestServer(t)
+
+	handle := func(msg string) string {
+		resp, _ := s.handleMessage(context.Background(), nil, []byte(msg))
+		return string(resp)
+	}
+
+	// the id is returned as it is
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"abc","result":"0xa"}`,
+		handle(`{"jsonrpc":"2.0","id":"abc","method":"eth_blockNumber"}`))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":null,"result":"0xa"}`,
+		handle(`{"jsonrpc":"2.0","id":null,"method":"eth_blockNumber"}`))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1.5,"result":"0xa"}`,
+		handle(`{"jsonrpc":"2.0","id":1.5,"method":"eth_blockNumber"}`))
+
+	// the notifications do not have a response
+	assert.Empty(t, handle(`{"jsonrpc":"2.0","method":"eth_blockNumber"}`))
+	assert.Empty(t, handle(`[{"jsonrpc":"2.0","method":"eth_blockNumber"}]`))
+	assert.JSONEq(t, `[{"jsonrpc":"2.0","id":2,"result":"0xa"}]`,
+		handle(`[{"jsonrpc":"2.0","method":"eth_blockNumber"},{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber"}]`))
+
+	// the id has to be a number, a string or null
+	resp := handle(`{"jsonrpc":"2.0","id":{},"method":"eth_blockNumber"}`)
+	assert.Contains(t, resp, `"code":-32600`)
+	assert.Contains(t, resp, `"id":null`)
+}
+
+func TestServer_HTTPNotification(t *testing.T) {
+	srv := httptest.NewServer(newTestServer(t))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"eth_blockNumber"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestServer_NotifyBeforeActivation(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Stop()
+
+	tr, err := s.DialInProc()
+	assert.NoError(t, err)
+
+	c := jsonrpc.NewClientWithTransport(tr)
+	defer c.Close()
+
+	ch := make(chan string, 10)
+	cancel, err := c.Subscribe("sync", uint64(3), func(b []byte) {
+		ch <- strings.Trim(string(b), `"`)
+	})
+	assert.NoError(t, err)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case val := <-ch:
+			assert.Equal(t, fmt.Sprintf("0x%x", i), val)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
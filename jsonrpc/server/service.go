@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var (
+	contextType      = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+	subscriptionType = reflect.TypeOf((*Subscription)(nil))
+)
+
+// method is a method of a registered receiver
+type method struct {
+	receiver reflect.Value
+	fn       reflect.Value
+
+	// hasCtx is true if the first argument is a context
+	hasCtx bool
+
+	// isSubscription is true if the method starts a subscription
+	isSubscription bool
+
+	// args are the types of the arguments decoded from the params
+	args []reflect.Type
+
+	// hasResult is true if the method returns a value besides the error
+	hasResult bool
+}
+
+// newMethod validates the signature of a method. The valid signatures are:
+//
+//	func (r *T) Method([ctx context.Context], args...) ([result], [error])
+//	func (r *T) Method([ctx context.Context], sub *Subscription, args...) error
+func newMethod(receiver reflect.Value, m reflect.Method) (*method, bool) {
+	typ := m.Type
+	res := &method{
+		receiver: receiver,
+		fn:       m.Func,
+	}
+
+	// the first argument is the receiver
+	indx := 1
+	if indx < typ.NumIn() && typ.In(indx) == contextType {
+		res.hasCtx = true
+		indx++
+	}
+	if indx < typ.NumIn() && typ.In(indx) == subscriptionType {
+		res.isSubscription = true
+		indx++
+	}
+	for ; indx < typ.NumIn(); indx++ {
+		res.args = append(res.args, typ.In(indx))
+	}
+
+	switch typ.NumOut() {
+	case 0:
+	case 1:
+		if typ.Out(0) != errorType {
+			res.hasResult = true
+		}
+	case 2:
+		if typ.Out(1) != errorType {
+			return nil, false
+		}
+		res.hasResult = true
+	default:
+		return nil, false
+	}
+	if res.isSubscription && res.hasResult {
+		return nil, false
+	}
+	return res, true
+}
+
+// decodeParams decodes the positional params into the arguments of the method.
+// The missing trailing arguments are set to their zero value if they are pointers.
+func (m *method) decodeParams(raw json.RawMessage) ([]reflect.Value, error) {
+	var params []json.RawMessage
+	if len(raw) != 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("non-array params")
+		}
+	}
+	if len(params) > len(m.args) {
+		return nil, fmt.Errorf("too many arguments, want at most %d", len(m.args))
+	}
+
+	args := make([]reflect.Value, len(m.args))
+	for i, typ := range m.args {
+		val := reflect.New(typ)
+		if i < len(params) {
+			if err := json.Unmarshal(params[i], val.Interface()); err != nil {
+				return nil, fmt.Errorf("invalid argument %d: %v", i, err)
+			}
+		} else if typ.Kind() != reflect.Ptr {
+			return nil, fmt.Errorf("missing value for required argument %d", i)
+		}
+		args[i] = val.Elem()
+	}
+	return args, nil
+}
+
+// call calls the method and returns its result
+func (m *method) call(ctx context.Context, sub *Subscription, args []reflect.Value) (interface{}, error) {
+	in := []reflect.Value{m.receiver}
+	if m.hasCtx {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	if m.isSubscription {
+		in = append(in, reflect.ValueOf(sub))
+	}
+	in = append(in, args...)
+
+	out := m.fn.Call(in)
+
+	var result interface{}
+	var err error
+	if m.hasResult {
+		result = out[0].Interface()
+	}
+	if len(out) != 0 {
+		if errVal := out[len(out)-1]; errVal.Type() == errorType && !errVal.IsNil() {
+			err = errVal.Interface().(error)
+		}
+	}
+	return result, err
+}
+
+// methodName returns the jsonrpc name of the method. The leading run of upper
+// case letters is lowercased except the one that starts the next word
+// (i.e. BlockNumber is blockNumber, EVMCall is evmCall and ID is id).
+func methodName(name string) string {
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i > 1 && i < len(runes) && unicode.IsLower(runes[i]) {
+		i--
+	}
+	for j := 0; j < i; j++ {
+		runes[j] = unicode.ToLower(runes[j])
+	}
+	return string(runes)
+}
+
+// MethodNamer is implemented by the receivers that set the jsonrpc name
+// of some of their methods (i.e. ChainID as chainId). The map goes from
+// the name of the Go method to the jsonrpc name.
+type MethodNamer interface {
+	MethodNames() map[string]string
+}
+
+// service is a namespace of methods
+type service struct {
+	methods       map[string]*method
+	subscriptions map[string]*method
+}
+
+func newService(receiver interface{}) (*service, error) {
+	val := reflect.ValueOf(receiver)
+	typ := val.Type()
+
+	s := &service{
+		methods:       map[string]*method{},
+		subscriptions: map[string]*method{},
+	}
+	var names map[string]string
+	if namer, ok := receiver.(MethodNamer); ok {
+		names = namer.MethodNames()
+	}
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if m.PkgPath != "" {
+			// not exported
+			continue
+		}
+		if names != nil && m.Name == "MethodNames" {
+			continue
+		}
+		res, ok := newMethod(val, m)
+		if !ok {
+			continue
+		}
+		name, ok := names[m.Name]
+		if !ok {
+			name = methodName(m.Name)
+		}
+		if res.isSubscription {
+			s.subscriptions[name] = res
+		} else {
+			s.methods[name] = res
+		}
+	}
+	if len(s.methods) == 0 && len(s.subscriptions) == 0 {
+		return nil, fmt.Errorf("type %s has no valid methods", typ)
+	}
+	return s, nil
+}
+
+// splitMethod splits the name of a jsonrpc method into namespace and method
+func splitMethod(name string) (string, string, bool) {
+	indx := strings.Index(name, "_")
+	if indx == -1 {
+		return "", "", false
+	}
+	return name[:indx], name[indx+1:], true
+}
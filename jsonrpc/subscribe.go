@@ -1,8 +1,12 @@
 package jsonrpc
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 
+	"github.com/umbracle/ethgo"
 	"github.com/umbracle/ethgo/jsonrpc/transport"
 )
 
@@ -18,6 +22,279 @@ func (c *Client) Subscribe(method string, parmas interface{}, callback func(b []
 	if !ok {
 		return nil, fmt.Errorf("Transport does not support the subscribe method")
 	}
-	close, err := pub.Subscribe(method, parmas, callback)
-	return close, err
+	if len(c.middlewares) == 0 {
+		return pub.Subscribe(method, parmas, callback)
+	}
+
+	reqParams := []interface{}{method}
+	if parmas != nil {
+		reqParams = append(reqParams, parmas)
+	}
+
+	var close func() error
+	_, err := c.intercept(func(ctx context.Context, req *Request) (json.RawMessage, error) {
+		var err error
+		close, err = pub.Subscribe(method, parmas, callback)
+		return nil, err
+	})(context.Background(), &Request{Method: "eth_subscribe", Params: reqParams})
+	if err != nil {
+		if close != nil {
+			close()
+		}
+		return nil, err
+	}
+	return close, nil
+}
+
+// OnConnState registers a hook that is called every time the connection state of
+// the transport changes. It returns a function to remove the hook.
+func (c *Client) OnConnState(hook func(state transport.ConnState)) (func(), error) {
+	t, ok := c.transport.(transport.ConnStateTransport)
+	if !ok {
+		return nil, fmt.Errorf("Transport does not support connection state hooks")
+	}
+	return t.OnConnState(hook), nil
+}
+
+// ErrSubscriptionOverflow happens when the buffer of a subscription
+// with the OverflowFail policy is full
+var ErrSubscriptionOverflow = fmt.Errorf("subscription buffer overflow")
+
+// OverflowPolicy is the action taken when the buffer of a subscription is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest buffered notification
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDropNewest discards the incoming notification
+	OverflowDropNewest
+	// OverflowFail ends the subscription with ErrSubscriptionOverflow
+	OverflowFail
+)
+
+const defaultSubscriptionBuffer = 128
+
+type subscriptionConfig struct {
+	buffer int
+	policy OverflowPolicy
+}
+
+// SubscriptionOption is an option to configure a typed subscription
+type SubscriptionOption func(*subscriptionConfig)
+
+// WithSubscriptionBuffer sets the number of notifications buffered
+// while the receiver is busy
+func WithSubscriptionBuffer(size int) SubscriptionOption {
+	return func(c *subscriptionConfig) {
+		c.buffer = size
+	}
+}
+
+// WithOverflowPolicy sets the action taken when the buffer is full
+func WithOverflowPolicy(policy OverflowPolicy) SubscriptionOption {
+	return func(c *subscriptionConfig) {
+		c.policy = policy
+	}
+}
+
+// Subscription is an active typed subscription
+type Subscription struct {
+	policy OverflowPolicy
+	buf    chan json.RawMessage
+	errCh  chan error
+	quitCh chan struct{}
+	once   sync.Once
+
+	lock       sync.Mutex
+	cancel     func() error
+	removeHook func()
+}
+
+// Err returns a channel that receives the error that ended the subscription.
+// The channel is closed when the subscription ends.
+func (s *Subscription) Err() <-chan error {
+	return s.errCh
+}
+
+// Unsubscribe ends the subscription
+func (s *Subscription) Unsubscribe() error {
+	if !s.terminate(nil) {
+		return nil
+	}
+	return s.cleanup()
+}
+
+func (s *Subscription) terminate(err error) bool {
+	done := false
+	s.once.Do(func() {
+		close(s.quitCh)
+		if err != nil {
+			s.errCh <- err
+		}
+		close(s.errCh)
+		done = true
+	})
+	return done
+}
+
+func (s *Subscription) fail(err error) {
+	if s.terminate(err) {
+		go s.cleanup()
+	}
+}
+
+func (s *Subscription) cleanup() error {
+	s.lock.Lock()
+	cancel, removeHook := s.cancel, s.removeHook
+	s.cancel, s.removeHook = nil, nil
+	s.lock.Unlock()
+
+	if removeHook != nil {
+		removeHook()
+	}
+	if cancel != nil {
+		return cancel()
+	}
+	return nil
+}
+
+// push buffers a notification according to the overflow policy
+func (s *Subscription) push(buf []byte) {
+	select {
+	case <-s.quitCh:
+		return
+	default:
+	}
+
+	msg := json.RawMessage(buf)
+	for {
+		select {
+		case s.buf <- msg:
+			return
+		default:
+		}
+
+		switch s.policy {
+		case OverflowDropNewest:
+			return
+		case OverflowFail:
+			s.fail(ErrSubscriptionOverflow)
+			return
+		default:
+			// make room for the new notification
+			select {
+			case <-s.buf:
+			default:
+			}
+		}
+	}
+}
+
+func (s *Subscription) run(decode func(buf json.RawMessage, quitCh <-chan struct{}) error) {
+	for {
+		select {
+		case buf := <-s.buf:
+			if err := decode(buf, s.quitCh); err != nil {
+				s.fail(err)
+				return
+			}
+		case <-s.quitCh:
+			return
+		}
+	}
+}
+
+// subscribe starts a subscription whose notifications are buffered and
+// handed to the decode function in order
+func (c *Client) subscribe(method string, params interface{}, decode func(buf json.RawMessage, quitCh <-chan struct{}) error, opts []SubscriptionOption) (*Subscription, error) {
+	config := &subscriptionConfig{
+		buffer: defaultSubscriptionBuffer,
+		policy: OverflowDropOldest,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.buffer < 1 {
+		return nil, fmt.Errorf("subscription buffer must be positive")
+	}
+
+	sub := &Subscription{
+		policy: config.policy,
+		buf:    make(chan json.RawMessage, config.buffer),
+		errCh:  make(chan error, 1),
+		quitCh: make(chan struct{}),
+	}
+
+	cancel, err := c.Subscribe(method, params, sub.push)
+	if err != nil {
+		return nil, err
+	}
+	removeHook, err := c.OnConnState(func(state transport.ConnState) {
+		if state == transport.ConnStateClosed {
+			sub.fail(transport.ErrClosed)
+		}
+	})
+	if err != nil {
+		// the transport does not notify its state
+		removeHook = nil
+	}
+
+	sub.lock.Lock()
+	sub.cancel, sub.removeHook = cancel, removeHook
+	sub.lock.Unlock()
+
+	select {
+	case <-sub.quitCh:
+		// failed before the cancel function was set
+		go sub.cleanup()
+	default:
+	}
+
+	go sub.run(decode)
+	return sub, nil
+}
+
+// SubscribeNewHeads subscribes to the headers of the new blocks included in the chain
+func (e *Eth) SubscribeNewHeads(ch chan<- *ethgo.Block, opts ...SubscriptionOption) (*Subscription, error) {
+	return e.c.subscribe("newHeads", nil, func(buf json.RawMessage, quitCh <-chan struct{}) error {
+		block := new(ethgo.Block)
+		if err := block.UnmarshalJSON(buf); err != nil {
+			return fmt.Errorf("failed to decode block: %v", err)
+		}
+		select {
+		case ch <- block:
+		case <-quitCh:
+		}
+		return nil
+	}, opts)
+}
+
+// SubscribeLogs subscribes to the logs included in new blocks that match the filter
+func (e *Eth) SubscribeLogs(filter *ethgo.LogFilter, ch chan<- *ethgo.Log, opts ...SubscriptionOption) (*Subscription, error) {
+	return e.c.subscribe("logs", filter, func(buf json.RawMessage, quitCh <-chan struct{}) error {
+		log := new(ethgo.Log)
+		if err := log.UnmarshalJSON(buf); err != nil {
+			return fmt.Errorf("failed to decode log: %v", err)
+		}
+		select {
+		case ch <- log:
+		case <-quitCh:
+		}
+		return nil
+	}, opts)
+}
+
+// SubscribeNewPendingTransactions subscribes to the hashes of the transactions added to the pending pool
+func (e *Eth) SubscribeNewPendingTransactions(ch chan<- ethgo.Hash, opts ...SubscriptionOption) (*Subscription, error) {
+	return e.c.subscribe("newPendingTransactions", nil, func(buf json.RawMessage, quitCh <-chan struct{}) error {
+		var hash ethgo.Hash
+		if err := json.Unmarshal(buf, &hash); err != nil {
+			return fmt.Errorf("failed to decode transaction hash: %v", err)
+		}
+		select {
+		case ch <- hash:
+		case <-quitCh:
+		}
+		return nil
+	}, opts)
 }
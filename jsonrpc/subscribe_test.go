@@ -1,8 +1,15 @@
 package jsonrpc
 
 import (
+	"context"
+	"fmt"
+	"math/big"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
 )
 
 func TestSubscribeNewHead(t *testing.T) {
@@ -27,3 +34,102 @@ func TestSubscribeNewHead(t *testing.T) {
 	}
 
 }
+
+// pubSubTransport is an in-memory transport that exposes
+// the callbacks of the subscriptions
+type pubSubTransport struct {
+	lock      sync.Mutex
+	callbacks map[string]func(b []byte)
+}
+
+func (p *pubSubTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (p *pubSubTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (p *pubSubTransport) SetMaxConnsPerHost(count int) {}
+
+func (p *pubSubTransport) Close() error { return nil }
+
+func (p *pubSubTransport) IsClosed() bool { return false }
+
+func (p *pubSubTransport) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.callbacks == nil {
+		p.callbacks = map[string]func(b []byte){}
+	}
+	p.callbacks[method] = callback
+	return func() error {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+
+		delete(p.callbacks, method)
+		return nil
+	}, nil
+}
+
+func (p *pubSubTransport) notify(method string, data string) {
+	p.lock.Lock()
+	callback := p.callbacks[method]
+	p.lock.Unlock()
+
+	callback([]byte(data))
+}
+
+func TestSubscribeNewHeads_Typed(t *testing.T) {
+	tt := &pubSubTransport{}
+	c := NewClientWithTransport(tt)
+
+	ch := make(chan *ethgo.Block)
+	sub, err := c.Eth().SubscribeNewHeads(ch)
+	assert.NoError(t, err)
+
+	header := &ethgo.Block{Number: 1, Hash: ethgo.Hash{0x1}, Difficulty: big.NewInt(1)}
+	data, err := header.MarshalJSON()
+	assert.NoError(t, err)
+
+	tt.notify("newHeads", string(data))
+
+	block := <-ch
+	assert.Equal(t, uint64(1), block.Number)
+
+	assert.NoError(t, sub.Unsubscribe())
+
+	_, ok := <-sub.Err()
+	assert.False(t, ok)
+	assert.Len(t, tt.callbacks, 0)
+}
+
+func TestSubscribe_Overflow(t *testing.T) {
+	tt := &pubSubTransport{}
+	c := NewClientWithTransport(tt)
+
+	// nobody reads from the channel
+	ch := make(chan ethgo.Hash)
+	sub, err := c.Eth().SubscribeNewPendingTransactions(ch, WithSubscriptionBuffer(1), WithOverflowPolicy(OverflowFail))
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		tt.notify("newPendingTransactions", `"0x0000000000000000000000000000000000000000000000000000000000000001"`)
+	}
+
+	assert.Equal(t, ErrSubscriptionOverflow, <-sub.Err())
+}
+
+func TestSubscribe_DecodeError(t *testing.T) {
+	tt := &pubSubTransport{}
+	c := NewClientWithTransport(tt)
+
+	ch := make(chan *ethgo.Log)
+	sub, err := c.Eth().SubscribeLogs(&ethgo.LogFilter{}, ch)
+	assert.NoError(t, err)
+
+	tt.notify("logs", `{"logIndex": "bad"}`)
+
+	assert.Error(t, <-sub.Err())
+}
@@ -0,0 +1,242 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+)
+
+// Trace is the trace namespace (OpenEthereum/Erigon style traces)
+type Trace struct {
+	c   *Client
+	ctx context.Context
+}
+
+// Trace returns the reference to the trace namespace
+func (c *Client) Trace() *Trace {
+	return c.endpoints.t
+}
+
+// WithContext returns a copy of the trace namespace whose calls are bound to ctx
+func (t *Trace) WithContext(ctx context.Context) *Trace {
+	t2 := *t
+	t2.ctx = ctx
+	return &t2
+}
+
+const (
+	// TraceTypeTrace returns the trace of the calls in trace_replayTransaction
+	TraceTypeTrace = "trace"
+	// TraceTypeVMTrace returns the full vm trace in trace_replayTransaction
+	TraceTypeVMTrace = "vmTrace"
+	// TraceTypeStateDiff returns the state changes in trace_replayTransaction
+	TraceTypeStateDiff = "stateDiff"
+)
+
+// TraceAction is the action of a trace
+type TraceAction struct {
+	CallType      string
+	From          ethgo.Address
+	To            ethgo.Address
+	Value         *big.Int
+	Gas           uint64
+	Input         []byte
+	Init          []byte
+	Address       ethgo.Address
+	RefundAddress ethgo.Address
+	Balance       *big.Int
+	Author        ethgo.Address
+	RewardType    string
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (a *TraceAction) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		CallType      string        `json:"callType"`
+		From          ethgo.Address `json:"from"`
+		To            ethgo.Address `json:"to"`
+		Value         string        `json:"value"`
+		Gas           string        `json:"gas"`
+		Input         string        `json:"input"`
+		Init          string        `json:"init"`
+		Address       ethgo.Address `json:"address"`
+		RefundAddress ethgo.Address `json:"refundAddress"`
+		Balance       string        `json:"balance"`
+		Author        ethgo.Address `json:"author"`
+		RewardType    string        `json:"rewardType"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	a.CallType = obj.CallType
+	a.From = obj.From
+	a.To = obj.To
+	a.Address = obj.Address
+	a.RefundAddress = obj.RefundAddress
+	a.Author = obj.Author
+	a.RewardType = obj.RewardType
+	if obj.Value != "" {
+		a.Value = parseBigInt(obj.Value)
+	}
+	if obj.Balance != "" {
+		a.Balance = parseBigInt(obj.Balance)
+	}
+	if a.Gas, err = parseOptionalUint64(obj.Gas); err != nil {
+		return err
+	}
+	if a.Input, err = parseOptionalHexBytes(obj.Input); err != nil {
+		return err
+	}
+	if a.Init, err = parseOptionalHexBytes(obj.Init); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TraceActionResult is the result of the action of a trace
+type TraceActionResult struct {
+	GasUsed uint64
+	Output  []byte
+	Address ethgo.Address
+	Code    []byte
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (r *TraceActionResult) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		GasUsed string        `json:"gasUsed"`
+		Output  string        `json:"output"`
+		Address ethgo.Address `json:"address"`
+		Code    string        `json:"code"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	r.Address = obj.Address
+	if r.GasUsed, err = parseOptionalUint64(obj.GasUsed); err != nil {
+		return err
+	}
+	if r.Output, err = parseOptionalHexBytes(obj.Output); err != nil {
+		return err
+	}
+	if r.Code, err = parseOptionalHexBytes(obj.Code); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TraceEntry is a single trace of the trace namespace
+type TraceEntry struct {
+	Type                string             `json:"type"`
+	Action              *TraceAction       `json:"action"`
+	Result              *TraceActionResult `json:"result"`
+	Error               string             `json:"error"`
+	Subtraces           uint64             `json:"subtraces"`
+	TraceAddress        []uint64           `json:"traceAddress"`
+	BlockHash           ethgo.Hash         `json:"blockHash"`
+	BlockNumber         uint64             `json:"blockNumber"`
+	TransactionHash     ethgo.Hash         `json:"transactionHash"`
+	TransactionPosition uint64             `json:"transactionPosition"`
+}
+
+// TraceReplay is the output of trace_replayTransaction
+type TraceReplay struct {
+	Output    []byte
+	Trace     []*TraceEntry
+	StateDiff json.RawMessage
+	VMTrace   json.RawMessage
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (r *TraceReplay) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Output    string          `json:"output"`
+		Trace     []*TraceEntry   `json:"trace"`
+		StateDiff json.RawMessage `json:"stateDiff"`
+		VMTrace   json.RawMessage `json:"vmTrace"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	if r.Output, err = parseOptionalHexBytes(obj.Output); err != nil {
+		return err
+	}
+	r.Trace = obj.Trace
+	r.StateDiff = obj.StateDiff
+	r.VMTrace = obj.VMTrace
+	return nil
+}
+
+// TraceFilter is the filter of trace_filter
+type TraceFilter struct {
+	FromBlock   *ethgo.BlockNumber
+	ToBlock     *ethgo.BlockNumber
+	FromAddress []ethgo.Address
+	ToAddress   []ethgo.Address
+	After       uint64
+	Count       uint64
+}
+
+// MarshalJSON implements the json marshal interface
+func (f *TraceFilter) MarshalJSON() ([]byte, error) {
+	obj := map[string]interface{}{}
+	if f.FromBlock != nil {
+		obj["fromBlock"] = f.FromBlock.String()
+	}
+	if f.ToBlock != nil {
+		obj["toBlock"] = f.ToBlock.String()
+	}
+	if len(f.FromAddress) != 0 {
+		obj["fromAddress"] = f.FromAddress
+	}
+	if len(f.ToAddress) != 0 {
+		obj["toAddress"] = f.ToAddress
+	}
+	if f.After != 0 {
+		obj["after"] = f.After
+	}
+	if f.Count != 0 {
+		obj["count"] = f.Count
+	}
+	return json.Marshal(obj)
+}
+
+// Block returns the traces of all the transactions in a block
+func (t *Trace) Block(i ethgo.BlockNumber) ([]*TraceEntry, error) {
+	var res []*TraceEntry
+	err := t.c.CallContext(t.ctx, "trace_block", &res, i.String())
+	return res, err
+}
+
+// Transaction returns the traces of a transaction
+func (t *Trace) Transaction(hash ethgo.Hash) ([]*TraceEntry, error) {
+	var res []*TraceEntry
+	err := t.c.CallContext(t.ctx, "trace_transaction", &res, hash)
+	return res, err
+}
+
+// Filter returns the traces that match the filter
+func (t *Trace) Filter(filter *TraceFilter) ([]*TraceEntry, error) {
+	var res []*TraceEntry
+	err := t.c.CallContext(t.ctx, "trace_filter", &res, filter)
+	return res, err
+}
+
+// ReplayTransaction replays a transaction and returns the given trace types
+// (TraceTypeTrace, TraceTypeVMTrace, TraceTypeStateDiff)
+func (t *Trace) ReplayTransaction(hash ethgo.Hash, traceTypes ...string) (*TraceReplay, error) {
+	if len(traceTypes) == 0 {
+		traceTypes = []string{TraceTypeTrace}
+	}
+	var res *TraceReplay
+	err := t.c.CallContext(t.ctx, "trace_replayTransaction", &res, hash, traceTypes)
+	return res, err
+}
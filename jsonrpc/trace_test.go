@@ -0,0 +1,63 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+)
+
+func TestTraceFilter_MarshalJSON(t *testing.T) {
+	from, latest := ethgo.BlockNumber(1), ethgo.Latest
+	filter := &TraceFilter{
+		FromBlock: &from,
+		ToBlock:   &latest,
+		ToAddress: []ethgo.Address{{0x1}},
+		Count:     10,
+	}
+	data, err := json.Marshal(filter)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"fromBlock":"0x1","toBlock":"latest","toAddress":["0x0100000000000000000000000000000000000000"],"count":10}`, string(data))
+}
+
+func TestTraceReplay_UnmarshalJSON(t *testing.T) {
+	raw := `{
+		"output": "0x01",
+		"stateDiff": null,
+		"vmTrace": null,
+		"trace": [
+			{
+				"action": {
+					"callType": "call",
+					"from": "0x0000000000000000000000000000000000000001",
+					"to": "0x0000000000000000000000000000000000000002",
+					"gas": "0x5208",
+					"input": "0x",
+					"value": "0xa"
+				},
+				"result": {
+					"gasUsed": "0x10",
+					"output": "0x01"
+				},
+				"subtraces": 0,
+				"traceAddress": [],
+				"type": "call"
+			}
+		]
+	}`
+
+	var res *TraceReplay
+	assert.NoError(t, json.Unmarshal([]byte(raw), &res))
+	assert.Equal(t, []byte{0x1}, res.Output)
+	assert.Len(t, res.Trace, 1)
+
+	entry := res.Trace[0]
+	assert.Equal(t, "call", entry.Type)
+	assert.Equal(t, "call", entry.Action.CallType)
+	assert.Equal(t, uint64(21000), entry.Action.Gas)
+	assert.Equal(t, big.NewInt(10), entry.Action.Value)
+	assert.Equal(t, uint64(16), entry.Result.GasUsed)
+	assert.Equal(t, []byte{0x1}, entry.Result.Output)
+}
@@ -1,9 +1,8 @@
 package transport
 
 import (
-	"encoding/json"
+	"context"
 
-	"github.com/umbracle/ethgo/jsonrpc/codec"
 	"github.com/valyala/fasthttp"
 )
 
@@ -33,28 +32,34 @@ func (h *HTTP) IsClosed() bool {
 
 // Call implements the transport interface
 func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error {
-	// Encode json-rpc request
-	request := codec.Request{
-		JsonRPC: "2.0",
-		Method:  method,
-	}
-	if len(params) > 0 {
-		data, err := json.Marshal(params)
-		if err != nil {
-			return err
-		}
-		request.Params = data
-	}
-	raw, err := json.Marshal(request)
-	if err != nil {
-		return err
+	return h.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (h *HTTP) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	return httpCall(ctx, h.do, method, out, params)
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (h *HTTP) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	return httpBatchCall(ctx, h.do, b)
+}
+
+// do sends the raw body to the endpoint and returns the body of the response.
+// fasthttp only understands deadlines, so the request runs on its own goroutine
+// and it is abandoned if the context is cancelled before it finishes.
+func (h *HTTP) do(ctx context.Context, raw []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	req := fasthttp.AcquireRequest()
 	res := fasthttp.AcquireResponse()
 
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(res)
+	release := func() {
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(res)
+	}
 
 	req.SetRequestURI(h.addr)
 	req.Header.SetMethod("POST")
@@ -62,25 +67,57 @@ func (h *HTTP) Call(method string, out interface{}, params ...interface{}) error
 	for k, v := range h.headers {
 		req.Header.Add(k, v)
 	}
+	for k, v := range requestHeaders(ctx) {
+		req.Header.Set(k, v)
+	}
 	req.SetBody(raw)
 
-	if err := h.client.Do(req, res); err != nil {
-		return err
+	doFn := func() error {
+		if deadline, ok := ctx.Deadline(); ok {
+			return h.client.DoDeadline(req, res, deadline)
+		}
+		return h.client.Do(req, res)
 	}
 
-	// Decode json-rpc response
-	var response codec.Response
-	if err := json.Unmarshal(res.Body(), &response); err != nil {
-		return err
-	}
-	if response.Error != nil {
-		return response.Error
+	if ctx.Done() == nil {
+		// the context can never be cancelled
+		defer release()
+		if err := doFn(); err != nil {
+			return nil, err
+		}
+		return readBody(res)
 	}
 
-	if err := json.Unmarshal(response.Result, out); err != nil {
-		return err
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- doFn()
+	}()
+
+	select {
+	case <-ctx.Done():
+		// the request is still in-flight, release the buffers once it is done
+		go func() {
+			<-errCh
+			release()
+		}()
+		return nil, ctx.Err()
+
+	case err := <-errCh:
+		defer release()
+		if err != nil {
+			if err == fasthttp.ErrTimeout {
+				// fasthttp reports its own error once the deadline expires
+				return nil, context.DeadlineExceeded
+			}
+			return nil, err
+		}
+		return readBody(res)
 	}
-	return nil
+}
+
+// readBody returns the body of the response
+func readBody(res *fasthttp.Response) ([]byte, error) {
+	return checkStatus(res.StatusCode(), append([]byte{}, res.Body()...))
 }
 
 // SetMaxConnsPerHost sets the maximum number of connections that can be established with a host
@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// doFunc sends the raw body of a request to the endpoint and returns the body of the response
+type doFunc func(ctx context.Context, raw []byte) ([]byte, error)
+
+// httpCall sends a single jsonrpc request over http
+func httpCall(ctx context.Context, do doFunc, method string, out interface{}, params []interface{}) error {
+	// Encode json-rpc request
+	request, err := newRequest(0, method, params)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	body, err := do(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	// Decode json-rpc response
+	var response codec.Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+
+	if err := json.Unmarshal(response.Result, out); err != nil {
+		return err
+	}
+	return nil
+}
+
+// httpBatchCall sends a batch of jsonrpc requests over http
+func httpBatchCall(ctx context.Context, do doFunc, b []BatchElem) error {
+	requests := make([]*codec.Request, len(b))
+	for i, elem := range b {
+		request, err := newRequest(uint64(i+1), elem.Method, elem.Params)
+		if err != nil {
+			return err
+		}
+		requests[i] = request
+	}
+	raw, err := json.Marshal(requests)
+	if err != nil {
+		return err
+	}
+
+	body, err := do(ctx, raw)
+	if err != nil {
+		return err
+	}
+
+	var responses []*codec.Response
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// the server may reply with a single error for the whole batch
+		var response codec.Response
+		if err2 := json.Unmarshal(body, &response); err2 == nil && response.Error != nil {
+			return response.Error
+		}
+		return err
+	}
+
+	found := make([]bool, len(b))
+	for _, response := range responses {
+		if response.ID == 0 || response.ID > uint64(len(b)) {
+			return fmt.Errorf("batch response with unknown id %d", response.ID)
+		}
+		indx := response.ID - 1
+		found[indx] = true
+
+		if response.Error != nil {
+			b[indx].Error = response.Error
+		} else {
+			b[indx].Error = decodeResult(response.Result, b[indx].Result)
+		}
+	}
+	for i := range b {
+		if !found[i] {
+			b[i].Error = fmt.Errorf("no response for batch call %s", b[i].Method)
+		}
+	}
+	return nil
+}
+
+// HTTPError is the error of a response with a non successful status code
+// and a body that is not a jsonrpc error
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error implements the error interface
+func (h *HTTPError) Error() string {
+	return fmt.Sprintf("http status %d: %s", h.StatusCode, string(h.Body))
+}
+
+// Is matches codec.ErrRateLimited for the 429 status code
+func (h *HTTPError) Is(target error) bool {
+	return target == codec.ErrRateLimited && h.StatusCode == http.StatusTooManyRequests
+}
+
+// checkStatus returns the body of the response. The jsonrpc errors are returned as
+// is for any status code since some endpoints reply with them on 4xx and 5xx.
+func checkStatus(status int, body []byte) ([]byte, error) {
+	if status >= 200 && status < 300 {
+		return body, nil
+	}
+	var response codec.Response
+	if err := json.Unmarshal(body, &response); err == nil && response.Error != nil {
+		return body, nil
+	}
+	var responses []*codec.Response
+	if err := json.Unmarshal(body, &responses); err == nil && len(responses) != 0 {
+		return body, nil
+	}
+	return nil, &HTTPError{StatusCode: status, Body: body}
+}
@@ -0,0 +1,129 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestHTTP_CallContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Delay") != "" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+
+	h := newHTTP(srv.URL, map[string]string{})
+
+	var out string
+	assert.NoError(t, h.CallContext(context.Background(), "eth_blockNumber", &out))
+	assert.Equal(t, "0x1", out)
+
+	// the deadline expires before the server responds
+	h = newHTTP(srv.URL, map[string]string{"X-Delay": "true"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, h.CallContext(ctx, "eth_blockNumber", &out))
+
+	// the context is cancelled while the request is in-flight
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	assert.Equal(t, context.Canceled, h.CallContext(ctx, "eth_blockNumber", &out))
+}
+
+func TestHTTP_BatchCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []*codec.Request
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatal(err)
+		}
+
+		// reply in reverse order and skip the last request
+		resps := []*codec.Response{}
+		for i := len(reqs) - 2; i >= 0; i-- {
+			req := reqs[i]
+			if req.Method == "eth_fail" {
+				resps = append(resps, &codec.Response{ID: req.ID, Error: &codec.ErrorObject{Code: -32000, Message: "failed"}})
+			} else {
+				resps = append(resps, &codec.Response{ID: req.ID, Result: req.Params})
+			}
+		}
+		json.NewEncoder(w).Encode(resps)
+	}))
+	defer srv.Close()
+
+	h := newHTTP(srv.URL, map[string]string{})
+
+	var res0 []string
+	b := []BatchElem{
+		{Method: "eth_echo", Params: []interface{}{"a"}, Result: &res0},
+		{Method: "eth_fail"},
+		{Method: "eth_echo"},
+	}
+	assert.NoError(t, h.BatchCallContext(context.Background(), b))
+
+	assert.NoError(t, b[0].Error)
+	assert.Equal(t, []string{"a"}, res0)
+	assert.Error(t, b[1].Error)
+	assert.Error(t, b[2].Error)
+}
+
+func TestHTTP_RequestHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer srv.Close()
+
+	h := newHTTP(srv.URL, map[string]string{"Authorization": "static"})
+
+	var out string
+	assert.NoError(t, h.CallContext(context.Background(), "eth_blockNumber", &out))
+	assert.Equal(t, "static", out)
+
+	ctx := WithRequestHeaders(context.Background(), map[string]string{"Authorization": "dynamic"})
+	assert.NoError(t, h.CallContext(ctx, "eth_blockNumber", &out))
+	assert.Equal(t, "dynamic", out)
+}
+
+func TestHTTP_StatusCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Error") != "" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":0,"error":{"code":-32005,"message":"limit exceeded"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("too many requests"))
+	}))
+	defer srv.Close()
+
+	var out string
+
+	h := newHTTP(srv.URL, map[string]string{})
+	err := h.CallContext(context.Background(), "eth_blockNumber", &out)
+
+	httpErr, ok := err.(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
+	assert.True(t, errors.Is(err, codec.ErrRateLimited))
+
+	// the jsonrpc error is returned if the body has one
+	h = newHTTP(srv.URL, map[string]string{"X-Error": "true"})
+	err = h.CallContext(context.Background(), "eth_blockNumber", &out)
+
+	_, ok = err.(*codec.ErrorObject)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, codec.ErrRateLimited))
+}
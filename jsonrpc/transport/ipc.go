@@ -1,29 +1,45 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
 	"net"
+	"sync"
 )
 
-func newIPC(addr string) (Transport, error) {
-	conn, err := net.Dial("unix", addr)
-	if err != nil {
-		return nil, err
-	}
+func newIPC(addr string, reconnect *ReconnectConfig) (Transport, error) {
+	return newConn(func() (net.Conn, error) {
+		return net.Dial("unix", addr)
+	}, reconnect)
+}
 
-	codec := &ipcCodec{
-		buf:  json.RawMessage{},
-		conn: conn,
-		dec:  json.NewDecoder(conn),
-	}
+// NewConn creates a stream transport over the connection returned by dial.
+// It connects to servers with custom connections (i.e. an in-process pipe).
+func NewConn(dial func() (net.Conn, error)) (Transport, error) {
+	return newConn(dial, nil)
+}
 
-	return newStream(codec)
+func newConn(dialConn func() (net.Conn, error), reconnect *ReconnectConfig) (Transport, error) {
+	dial := func() (Codec, error) {
+		conn, err := dialConn()
+		if err != nil {
+			return nil, err
+		}
+		codec := &ipcCodec{
+			buf:  json.RawMessage{},
+			conn: conn,
+			dec:  json.NewDecoder(conn),
+		}
+		return codec, nil
+	}
+	return newStream(dial, reconnect)
 }
 
 type ipcCodec struct {
-	buf  json.RawMessage
-	conn net.Conn
-	dec  *json.Decoder
+	buf       json.RawMessage
+	conn      net.Conn
+	dec       *json.Decoder
+	writeLock sync.Mutex
 }
 
 func (i *ipcCodec) Close() error {
@@ -39,7 +55,14 @@ func (i *ipcCodec) Read(b []byte) ([]byte, error) {
 	return b, nil
 }
 
-func (i *ipcCodec) Write(b []byte) error {
+func (i *ipcCodec) Write(ctx context.Context, b []byte) error {
+	i.writeLock.Lock()
+	defer i.writeLock.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	if err := i.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
 	_, err := i.conn.Write(b)
 	return err
 }
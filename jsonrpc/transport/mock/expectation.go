@@ -0,0 +1,243 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Matcher matches a param of a request
+type Matcher interface {
+	// Match returns true if the json encoded param matches
+	Match(param json.RawMessage) bool
+
+	// String describes the matcher in the errors
+	String() string
+}
+
+type anyMatcher struct{}
+
+func (anyMatcher) Match(param json.RawMessage) bool {
+	return true
+}
+
+func (anyMatcher) String() string {
+	return "<any>"
+}
+
+// Any returns a matcher that matches any param
+func Any() Matcher {
+	return anyMatcher{}
+}
+
+type eqMatcher struct {
+	expected json.RawMessage
+}
+
+func (e *eqMatcher) Match(param json.RawMessage) bool {
+	return bytes.Equal(e.expected, compact(param))
+}
+
+func (e *eqMatcher) String() string {
+	return string(e.expected)
+}
+
+// Eq returns a matcher that matches the params with the same json encoding as the value
+func Eq(v interface{}) Matcher {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("mock: failed to encode param: %v", err))
+	}
+	return &eqMatcher{expected: compact(data)}
+}
+
+type funcMatcher struct {
+	fn func(param json.RawMessage) bool
+}
+
+func (f *funcMatcher) Match(param json.RawMessage) bool {
+	return f.fn(param)
+}
+
+func (f *funcMatcher) String() string {
+	return "<func>"
+}
+
+// MatchFunc returns a matcher that matches the params for which the function returns true
+func MatchFunc(fn func(param json.RawMessage) bool) Matcher {
+	return &funcMatcher{fn: fn}
+}
+
+func compact(data []byte) json.RawMessage {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+// Expectation is an expected request of the mock transport
+type Expectation struct {
+	t         *Transport
+	subscribe bool
+	method    string
+
+	// params are the matchers of the params. Nil matches any params.
+	params []Matcher
+
+	result  json.RawMessage
+	err     error
+	handler func(params []json.RawMessage) (interface{}, error)
+
+	// pushes are the notifications sent to a new subscription
+	pushes []json.RawMessage
+
+	// times is the number of expected calls. Zero means any number of calls.
+	times int
+	calls int
+	after []*Expectation
+}
+
+func newExpectation(subscribe bool, method string, params []interface{}) *Expectation {
+	e := &Expectation{
+		subscribe: subscribe,
+		method:    method,
+		result:    json.RawMessage("null"),
+	}
+	if len(params) != 0 {
+		e.params = make([]Matcher, len(params))
+		for i, p := range params {
+			if m, ok := p.(Matcher); ok {
+				e.params[i] = m
+			} else {
+				e.params[i] = Eq(p)
+			}
+		}
+	}
+	return e
+}
+
+// Return sets the result of the request. It is encoded as json
+// and decoded in the output of the call.
+func (e *Expectation) Return(result interface{}) *Expectation {
+	data, err := json.Marshal(result)
+	if err != nil {
+		panic(fmt.Sprintf("mock: failed to encode result: %v", err))
+	}
+	e.result = data
+	return e
+}
+
+// ReturnError sets the error of the request. Use a *codec.ErrorObject
+// to return a jsonrpc error.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Run sets a function that computes the result of the request with its params
+func (e *Expectation) Run(fn func(params []json.RawMessage) (interface{}, error)) *Expectation {
+	e.handler = fn
+	return e
+}
+
+// Push adds notifications that are sent to the subscription once it starts
+func (e *Expectation) Push(values ...interface{}) *Expectation {
+	for _, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			panic(fmt.Sprintf("mock: failed to encode notification: %v", err))
+		}
+		e.pushes = append(e.pushes, data)
+	}
+	return e
+}
+
+// Times sets the number of times the request is expected
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Once expects the request only once
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// After expects the request only after the other expectations are satisfied
+func (e *Expectation) After(prev ...*Expectation) *Expectation {
+	e.after = append(e.after, prev...)
+	return e
+}
+
+// InOrder expects the requests in the given order
+func InOrder(exps ...*Expectation) {
+	for i := 1; i < len(exps); i++ {
+		exps[i].After(exps[i-1])
+	}
+}
+
+// Calls returns the number of requests matched by the expectation
+func (e *Expectation) Calls() int {
+	e.t.lock.Lock()
+	defer e.t.lock.Unlock()
+
+	return e.calls
+}
+
+// resolve returns the result of a matched request
+func (e *Expectation) resolve(params []json.RawMessage) (json.RawMessage, error) {
+	if e.handler == nil {
+		return e.result, e.err
+	}
+	result, err := e.handler(params)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+func (e *Expectation) match(subscribe bool, method string, params []json.RawMessage) bool {
+	if e.subscribe != subscribe || e.method != method {
+		return false
+	}
+	if e.params == nil {
+		return true
+	}
+	if len(e.params) != len(params) {
+		return false
+	}
+	for i, m := range e.params {
+		if !m.Match(params[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times != 0 && e.calls >= e.times
+}
+
+func (e *Expectation) satisfied() bool {
+	if e.times == 0 {
+		return e.calls > 0
+	}
+	return e.calls >= e.times
+}
+
+func (e *Expectation) String() string {
+	params := "<any>"
+	if e.params != nil {
+		strs := make([]string, len(e.params))
+		for i, m := range e.params {
+			strs[i] = m.String()
+		}
+		params = strings.Join(strs, ", ")
+	}
+	if e.subscribe {
+		return fmt.Sprintf("subscribe %s(%s)", e.method, params)
+	}
+	return fmt.Sprintf("%s(%s)", e.method, params)
+}
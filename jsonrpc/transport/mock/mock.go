@@ -0,0 +1,327 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// Call is a request received by the mock transport
+type Call struct {
+	Method string
+	Params []json.RawMessage
+
+	// Subscribe is true if the request started a subscription
+	Subscribe bool
+}
+
+func (c *Call) String() string {
+	params := make([]string, len(c.Params))
+	for i, p := range c.Params {
+		params[i] = string(p)
+	}
+	if c.Subscribe {
+		return fmt.Sprintf("subscribe %s(%s)", c.Method, strings.Join(params, ", "))
+	}
+	return fmt.Sprintf("%s(%s)", c.Method, strings.Join(params, ", "))
+}
+
+type subscription struct {
+	method   string
+	callback func(b []byte)
+
+	// lock serializes the notifications of the subscription
+	lock sync.Mutex
+}
+
+func (s *subscription) push(data []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.callback(data)
+}
+
+// TestingT is the subset of testing.T used by the assertions
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Transport is a programmable transport for the tests. The requests are
+// matched with the registered expectations in the registration order.
+type Transport struct {
+	lock         sync.Mutex
+	expectations []*Expectation
+	calls        []*Call
+	unexpected   []error
+	subs         map[int]*subscription
+	nextSub      int
+	closed       bool
+}
+
+// New creates a new mock transport
+func New() *Transport {
+	return &Transport{
+		subs: map[int]*subscription{},
+	}
+}
+
+// On expects a call of the method with the params. The params are matchers
+// or values compared by their json encoding. Without params, it matches
+// the calls with any params.
+func (t *Transport) On(method string, params ...interface{}) *Expectation {
+	return t.expect(false, method, params)
+}
+
+// OnSubscribe expects a subscription of the method with the params
+func (t *Transport) OnSubscribe(method string, params ...interface{}) *Expectation {
+	return t.expect(true, method, params)
+}
+
+func (t *Transport) expect(subscribe bool, method string, params []interface{}) *Expectation {
+	e := newExpectation(subscribe, method, params)
+	e.t = t
+
+	t.lock.Lock()
+	t.expectations = append(t.expectations, e)
+	t.lock.Unlock()
+
+	return e
+}
+
+// Calls returns the requests received by the transport
+func (t *Transport) Calls() []*Call {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return append([]*Call{}, t.calls...)
+}
+
+// CallCount returns the number of calls of the method
+func (t *Transport) CallCount(method string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	count := 0
+	for _, c := range t.calls {
+		if c.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertExpectations checks that all the expectations were satisfied
+// and that there were no unexpected requests
+func (t *Transport) AssertExpectations(tt TestingT) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ok := true
+	for _, e := range t.expectations {
+		if e.satisfied() {
+			continue
+		}
+		ok = false
+		if e.times == 0 {
+			tt.Errorf("mock: expected %s to be called", e)
+		} else {
+			tt.Errorf("mock: expected %s to be called %d times, called %d times", e, e.times, e.calls)
+		}
+	}
+	for _, err := range t.unexpected {
+		ok = false
+		tt.Errorf("%v", err)
+	}
+	return ok
+}
+
+// Notify sends a notification to all the active subscriptions of the method
+func (t *Transport) Notify(method string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	subs := []*subscription{}
+	for _, s := range t.subs {
+		if s.method == method {
+			subs = append(subs, s)
+		}
+	}
+	t.lock.Unlock()
+
+	if len(subs) == 0 {
+		return fmt.Errorf("mock: no active subscriptions of %s", method)
+	}
+	for _, s := range subs {
+		s.push(data)
+	}
+	return nil
+}
+
+// ActiveSubscriptions returns the number of subscriptions of the method not cancelled yet
+func (t *Transport) ActiveSubscriptions(method string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	count := 0
+	for _, s := range t.subs {
+		if s.method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// Close implements the transport interface
+func (t *Transport) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.closed = true
+	t.subs = map[int]*subscription{}
+	return nil
+}
+
+// IsClosed implements the transport interface
+func (t *Transport) IsClosed() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.closed
+}
+
+// SetMaxConnsPerHost implements the transport interface
+func (t *Transport) SetMaxConnsPerHost(count int) {
+}
+
+// Call implements the transport interface
+func (t *Transport) Call(method string, out interface{}, params ...interface{}) error {
+	return t.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (t *Transport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e, call, err := t.match(false, method, params)
+	if err != nil {
+		return err
+	}
+
+	result, err := e.resolve(call.Params)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result, out)
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (t *Transport) BatchCallContext(ctx context.Context, b []transport.BatchElem) error {
+	for i := range b {
+		b[i].Error = t.CallContext(ctx, b[i].Method, b[i].Result, b[i].Params...)
+	}
+	return nil
+}
+
+// Subscribe implements the PubSubTransport interface
+func (t *Transport) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	var reqParams []interface{}
+	if params != nil {
+		reqParams = []interface{}{params}
+	}
+	e, call, err := t.match(true, method, reqParams)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := e.resolve(call.Params); err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{method: method, callback: callback}
+
+	t.lock.Lock()
+	id := t.nextSub
+	t.nextSub++
+	t.subs[id] = sub
+	t.lock.Unlock()
+
+	if len(e.pushes) != 0 {
+		// the notifications are sent once the caller has the subscription
+		go func() {
+			for _, data := range e.pushes {
+				sub.push(data)
+			}
+		}()
+	}
+
+	cancel := func() error {
+		t.lock.Lock()
+		delete(t.subs, id)
+		t.lock.Unlock()
+		return nil
+	}
+	return cancel, nil
+}
+
+// match finds the expectation of the request and records the call
+func (t *Transport) match(subscribe bool, method string, params []interface{}) (*Expectation, *Call, error) {
+	call := &Call{
+		Method:    method,
+		Params:    make([]json.RawMessage, len(params)),
+		Subscribe: subscribe,
+	}
+	for i, p := range params {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		call.Params[i] = compact(data)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.closed {
+		return nil, nil, transport.ErrClosed
+	}
+	t.calls = append(t.calls, call)
+
+	var exhausted *Expectation
+	for _, e := range t.expectations {
+		if !e.match(subscribe, method, call.Params) {
+			continue
+		}
+		if e.exhausted() {
+			exhausted = e
+			continue
+		}
+		for _, prev := range e.after {
+			if !prev.satisfied() {
+				err := fmt.Errorf("mock: %s called before %s", call, prev)
+				t.unexpected = append(t.unexpected, err)
+				return nil, nil, err
+			}
+		}
+		e.calls++
+		return e, call, nil
+	}
+
+	var err error
+	if exhausted != nil {
+		err = fmt.Errorf("mock: %s called more than %d times", exhausted, exhausted.times)
+	} else {
+		err = fmt.Errorf("mock: unexpected call %s", call)
+	}
+	t.unexpected = append(t.unexpected, err)
+	return nil, nil, err
+}
@@ -0,0 +1,136 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMock_Call(t *testing.T) {
+	m := New()
+	c := jsonrpc.NewClientWithTransport(m)
+
+	addr := ethgo.Address{0x1}
+	m.On("eth_blockNumber").Return("0x10")
+	m.On("eth_getBalance", addr, "latest").Return("0x5").Once()
+	m.On("eth_getBalance", Any(), "0x1").Return("0x6")
+
+	num, err := c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), num)
+
+	balance, err := c.Eth().GetBalance(addr, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), balance)
+
+	balance, err = c.Eth().GetBalance(ethgo.Address{0x2}, ethgo.BlockNumber(1))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(6), balance)
+
+	// the expectation is only expected once
+	_, err = c.Eth().GetBalance(addr, ethgo.Latest)
+	assert.Error(t, err)
+
+	assert.Equal(t, 3, m.CallCount("eth_getBalance"))
+	assert.Len(t, m.Calls(), 4)
+	assert.Equal(t, `"0x0100000000000000000000000000000000000000"`, string(m.Calls()[1].Params[0]))
+
+	tt := &fakeT{}
+	assert.False(t, m.AssertExpectations(tt))
+	assert.Len(t, tt.errors, 1)
+}
+
+func TestMock_Error(t *testing.T) {
+	m := New()
+	c := jsonrpc.NewClientWithTransport(m)
+
+	m.On("eth_sendRawTransaction").ReturnError(&codec.ErrorObject{Code: -32000, Message: "nonce too low"})
+	m.On("eth_chainId").Run(func(params []json.RawMessage) (interface{}, error) {
+		assert.Len(t, params, 0)
+		return "0x539", nil
+	})
+
+	_, err := c.Eth().SendRawTransaction([]byte{0x1})
+	assert.True(t, errors.Is(err, jsonrpc.ErrNonceTooLow))
+
+	chainID, err := c.Eth().ChainID()
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1337), chainID)
+
+	// unexpected calls fail and are reported
+	_, err = c.Eth().GasPrice()
+	assert.Error(t, err)
+
+	tt := &fakeT{}
+	assert.False(t, m.AssertExpectations(tt))
+	assert.Len(t, tt.errors, 1)
+	assert.Contains(t, tt.errors[0], "eth_gasPrice")
+}
+
+func TestMock_Order(t *testing.T) {
+	m := New()
+	c := jsonrpc.NewClientWithTransport(m)
+
+	first := m.On("eth_chainId").Return("0x1")
+	second := m.On("eth_blockNumber").Return("0x1")
+	InOrder(first, second)
+
+	_, err := c.Eth().BlockNumber()
+	assert.Error(t, err)
+
+	_, err = c.Eth().ChainID()
+	assert.NoError(t, err)
+	_, err = c.Eth().BlockNumber()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, first.Calls())
+	assert.Equal(t, 1, second.Calls())
+}
+
+func TestMock_Subscribe(t *testing.T) {
+	m := New()
+	c := jsonrpc.NewClientWithTransport(m)
+
+	m.OnSubscribe("newHeads").Push(&ethgo.Block{Number: 1}).Once()
+
+	ch := make(chan *ethgo.Block, 2)
+	sub, err := c.Eth().SubscribeNewHeads(ch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.ActiveSubscriptions("newHeads"))
+
+	recv := func() *ethgo.Block {
+		select {
+		case b := <-ch:
+			return b
+		case <-time.After(5 * time.Second):
+			t.Fatal("block not received")
+		}
+		return nil
+	}
+	assert.Equal(t, uint64(1), recv().Number)
+
+	assert.NoError(t, m.Notify("newHeads", &ethgo.Block{Number: 2}))
+	assert.Equal(t, uint64(2), recv().Number)
+
+	assert.NoError(t, sub.Unsubscribe())
+	assert.Equal(t, 0, m.ActiveSubscriptions("newHeads"))
+	assert.Error(t, m.Notify("newHeads", map[string]interface{}{}))
+
+	assert.True(t, m.AssertExpectations(t))
+}
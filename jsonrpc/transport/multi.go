@@ -0,0 +1,501 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// ErrNoQuorum happens when not enough endpoints agree on the result of a call
+var ErrNoQuorum = fmt.Errorf("no quorum")
+
+// Strategy is the strategy used by the Multi transport to pick an endpoint
+type Strategy int
+
+const (
+	// StrategyRoundRobin spreads the calls among the healthy endpoints
+	StrategyRoundRobin Strategy = iota
+	// StrategyFallback sends the calls to the first healthy endpoint in order
+	StrategyFallback
+	// StrategyQuorum sends the calls to all the healthy endpoints and returns
+	// the result only if enough of them agree
+	StrategyQuorum
+)
+
+// MultiConfig is the configuration of the Multi transport
+type MultiConfig struct {
+	// Strategy is the strategy to pick the endpoints
+	Strategy Strategy
+
+	// Quorum is the number of endpoints that must return the same result
+	// with StrategyQuorum
+	Quorum int
+
+	// HealthCheckInterval is the interval between health checks of the
+	// endpoints with eth_blockNumber. Zero disables the health checks.
+	HealthCheckInterval time.Duration
+
+	// HealthCheckTimeout is the timeout of each health check
+	HealthCheckTimeout time.Duration
+
+	// MaxBlockLag is the number of blocks an endpoint can be behind the
+	// best one before it is ejected. Zero disables the check.
+	MaxBlockLag uint64
+
+	// MaxErrors is the number of consecutive failed calls before an
+	// endpoint is ejected. Zero disables the check. The ejected endpoints
+	// are restored by the health checks.
+	MaxErrors int
+}
+
+// DefaultMultiConfig returns the default configuration of the Multi transport
+func DefaultMultiConfig() *MultiConfig {
+	return &MultiConfig{
+		Strategy:            StrategyRoundRobin,
+		Quorum:              1,
+		HealthCheckInterval: 10 * time.Second,
+		HealthCheckTimeout:  5 * time.Second,
+		MaxBlockLag:         5,
+		MaxErrors:           3,
+	}
+}
+
+type endpoint struct {
+	transport Transport
+
+	lock    sync.Mutex
+	healthy bool
+	errors  int
+}
+
+func (e *endpoint) isHealthy() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.healthy
+}
+
+// Multi is a transport that wraps several transports to provide
+// load-balancing and failover among them
+type Multi struct {
+	config    *MultiConfig
+	endpoints []*endpoint
+	next      uint64
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMulti creates a new transport on top of the given transports. The
+// order of the transports is the priority with StrategyFallback.
+func NewMulti(transports []Transport, config *MultiConfig) (*Multi, error) {
+	if len(transports) == 0 {
+		return nil, fmt.Errorf("no transports")
+	}
+	if config == nil {
+		config = DefaultMultiConfig()
+	}
+	if config.Strategy == StrategyQuorum {
+		if config.Quorum < 1 || config.Quorum > len(transports) {
+			return nil, fmt.Errorf("quorum %d out of range [1, %d]", config.Quorum, len(transports))
+		}
+	}
+
+	m := &Multi{
+		config:  config,
+		closeCh: make(chan struct{}),
+	}
+	for _, t := range transports {
+		m.endpoints = append(m.endpoints, &endpoint{transport: t, healthy: true})
+	}
+	if config.HealthCheckInterval != 0 {
+		go m.healthCheckLoop()
+	}
+	return m, nil
+}
+
+// NewMultiTransport creates a Multi transport for the given urls
+func NewMultiTransport(urls []string, config *Config, multiConfig *MultiConfig) (*Multi, error) {
+	transports := []Transport{}
+	for _, url := range urls {
+		t, err := NewTransportWithConfig(url, config)
+		if err != nil {
+			for _, t := range transports {
+				t.Close()
+			}
+			return nil, fmt.Errorf("failed to create transport for %s: %v", url, err)
+		}
+		transports = append(transports, t)
+	}
+	m, err := NewMulti(transports, multiConfig)
+	if err != nil {
+		for _, t := range transports {
+			t.Close()
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// Close implements the transport interface
+func (m *Multi) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+		for _, e := range m.endpoints {
+			if cErr := e.transport.Close(); cErr != nil && err == nil {
+				err = cErr
+			}
+		}
+	})
+	return err
+}
+
+// IsClosed implements the transport interface
+func (m *Multi) IsClosed() bool {
+	select {
+	case <-m.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetMaxConnsPerHost implements the transport interface
+func (m *Multi) SetMaxConnsPerHost(count int) {
+	for _, e := range m.endpoints {
+		e.transport.SetMaxConnsPerHost(count)
+	}
+}
+
+// Call implements the transport interface
+func (m *Multi) Call(method string, out interface{}, params ...interface{}) error {
+	return m.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (m *Multi) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if m.config.Strategy == StrategyQuorum {
+		return m.quorumCall(ctx, method, out, params...)
+	}
+
+	send := IsSendMethod(method)
+
+	var err error
+	for _, e := range m.candidates() {
+		err = e.transport.CallContext(ctx, method, out, params...)
+		if !m.failover(e, err) {
+			return err
+		}
+		if send && !notReceived(err) {
+			// the transaction could have been received, do not send it twice
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (m *Multi) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	send := false
+	for _, elem := range b {
+		if IsSendMethod(elem.Method) {
+			send = true
+			break
+		}
+	}
+
+	var err error
+	for _, e := range m.candidates() {
+		if batch, ok := e.transport.(BatchTransport); ok {
+			err = batch.BatchCallContext(ctx, b)
+		} else {
+			for i := range b {
+				b[i].Error = e.transport.CallContext(ctx, b[i].Method, b[i].Result, b[i].Params...)
+			}
+			err = batchEndpointError(b)
+		}
+		if !m.failover(e, err) {
+			return err
+		}
+		if send && !notReceived(err) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// Subscribe implements the PubSubTransport interface. The subscription
+// is started on the first healthy endpoint that supports it.
+func (m *Multi) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	for _, e := range m.candidates() {
+		pub, ok := e.transport.(PubSubTransport)
+		if !ok {
+			continue
+		}
+		cancel, err := pub.Subscribe(method, params, callback)
+		if !m.failover(e, err) {
+			return cancel, err
+		}
+	}
+	return nil, fmt.Errorf("no endpoint supports the subscribe method")
+}
+
+// candidates returns the endpoints in the order they have to be tried
+func (m *Multi) candidates() []*endpoint {
+	healthy := []*endpoint{}
+	for _, e := range m.endpoints {
+		if e.isHealthy() {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		// better to try an ejected endpoint than to fail right away
+		healthy = append(healthy, m.endpoints...)
+	}
+	if m.config.Strategy != StrategyRoundRobin {
+		return healthy
+	}
+
+	start := int(atomic.AddUint64(&m.next, 1)-1) % len(healthy)
+
+	res := make([]*endpoint, 0, len(healthy))
+	res = append(res, healthy[start:]...)
+	res = append(res, healthy[:start]...)
+	return res
+}
+
+// failover records the result of a call on the endpoint and returns
+// true if the call has to be retried on another endpoint
+func (m *Multi) failover(e *endpoint, err error) bool {
+	if err == nil || !isEndpointError(err) {
+		e.lock.Lock()
+		e.errors = 0
+		e.lock.Unlock()
+		return false
+	}
+
+	e.lock.Lock()
+	e.errors++
+	if m.config.MaxErrors != 0 && e.errors >= m.config.MaxErrors {
+		e.healthy = false
+	}
+	e.lock.Unlock()
+	return true
+}
+
+// isEndpointError returns true if the error is a failure of the endpoint
+// and not a valid jsonrpc error response or a cancelled call
+func isEndpointError(err error) bool {
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return true
+}
+
+// batchEndpointError returns the error of the endpoint if all the
+// elements of the batch failed because of it
+func batchEndpointError(b []BatchElem) error {
+	if len(b) == 0 {
+		return nil
+	}
+	for _, elem := range b {
+		if elem.Error == nil || !isEndpointError(elem.Error) {
+			return nil
+		}
+	}
+	return b[0].Error
+}
+
+// IsSendMethod returns true if the method sends a transaction. These
+// methods are not sent again after an error unless it is sure that
+// the endpoint did not receive them.
+func IsSendMethod(method string) bool {
+	return method == "eth_sendRawTransaction" || method == "eth_sendTransaction"
+}
+
+// notReceived returns true if the error proves that the request did not reach
+// the node: the connection could not be established or it was rate limited
+func notReceived(err error) bool {
+	if errors.Is(err, codec.ErrRateLimited) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+type quorumResult struct {
+	raw json.RawMessage
+	err error
+}
+
+func (m *Multi) quorumCall(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	endpoints := m.candidates()
+	if len(endpoints) < m.config.Quorum {
+		return fmt.Errorf("%w: %d endpoints available, %d required", ErrNoQuorum, len(endpoints), m.config.Quorum)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resCh := make(chan *quorumResult, len(endpoints))
+	for _, e := range endpoints {
+		go func(e *endpoint) {
+			var raw json.RawMessage
+			err := e.transport.CallContext(ctx, method, &raw, params...)
+			if ctx.Err() == nil {
+				// the calls cancelled once there is quorum say
+				// nothing about the endpoint
+				m.failover(e, err)
+			}
+			resCh <- &quorumResult{raw: raw, err: err}
+		}(e)
+	}
+
+	// the jsonrpc errors are valid responses, the endpoints that
+	// return the same error object agree on the result too
+	type vote struct {
+		raw    []byte
+		errObj *codec.ErrorObject
+		count  int
+	}
+	votes := []*vote{}
+
+	var lastErr error
+	for i := 0; i < len(endpoints); i++ {
+		var res *quorumResult
+		select {
+		case res = <-resCh:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		var key []byte
+		var errObj *codec.ErrorObject
+		if res.err != nil {
+			if !errors.As(res.err, &errObj) {
+				lastErr = res.err
+				continue
+			}
+			buf, err := json.Marshal(errObj)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			key = buf
+		} else {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, res.raw); err != nil {
+				lastErr = err
+				continue
+			}
+			key = buf.Bytes()
+		}
+
+		var v *vote
+		for _, vv := range votes {
+			if (vv.errObj != nil) == (errObj != nil) && bytes.Equal(vv.raw, key) {
+				v = vv
+				break
+			}
+		}
+		if v == nil {
+			v = &vote{raw: key, errObj: errObj}
+			votes = append(votes, v)
+		}
+		v.count++
+
+		if v.count >= m.config.Quorum {
+			if v.errObj != nil {
+				return v.errObj
+			}
+			return json.Unmarshal(v.raw, out)
+		}
+	}
+
+	if lastErr != nil {
+		return fmt.Errorf("%w: %w", ErrNoQuorum, lastErr)
+	}
+	return ErrNoQuorum
+}
+
+func (m *Multi) healthCheckLoop() {
+	for {
+		m.healthCheck()
+
+		select {
+		case <-time.After(m.config.HealthCheckInterval):
+		case <-m.closeCh:
+			return
+		}
+	}
+}
+
+// healthCheck queries the head of every endpoint and ejects the
+// ones that fail or lag behind the best head
+func (m *Multi) healthCheck() {
+	heads := make([]uint64, len(m.endpoints))
+	errs := make([]error, len(m.endpoints))
+
+	var wg sync.WaitGroup
+	for i, e := range m.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+
+			ctx := context.Background()
+			if m.config.HealthCheckTimeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, m.config.HealthCheckTimeout)
+				defer cancel()
+			}
+
+			var out string
+			if err := e.transport.CallContext(ctx, "eth_blockNumber", &out); err != nil {
+				errs[i] = err
+				return
+			}
+			heads[i], errs[i] = strconv.ParseUint(strings.TrimPrefix(out, "0x"), 16, 64)
+		}(i, e)
+	}
+	wg.Wait()
+
+	best := uint64(0)
+	for i, head := range heads {
+		if errs[i] == nil && head > best {
+			best = head
+		}
+	}
+
+	for i, e := range m.endpoints {
+		e.lock.Lock()
+		if errs[i] != nil {
+			e.healthy = false
+		} else {
+			e.healthy = m.config.MaxBlockLag == 0 || heads[i]+m.config.MaxBlockLag >= best
+			if e.healthy {
+				e.errors = 0
+			}
+		}
+		e.lock.Unlock()
+	}
+}
@@ -0,0 +1,253 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// funcTransport is a transport that resolves the calls with a function
+type funcTransport struct {
+	calls  uint64
+	handle func(method string) (interface{}, error)
+}
+
+func (f *funcTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return f.CallContext(context.Background(), method, out, params...)
+}
+
+func (f *funcTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	atomic.AddUint64(&f.calls, 1)
+
+	res, err := f.handle(method)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (f *funcTransport) SetMaxConnsPerHost(count int) {}
+
+func (f *funcTransport) Close() error { return nil }
+
+func (f *funcTransport) IsClosed() bool { return false }
+
+func newFuncTransport(res interface{}, err error) *funcTransport {
+	return &funcTransport{
+		handle: func(method string) (interface{}, error) {
+			return res, err
+		},
+	}
+}
+
+func TestMulti_RoundRobin(t *testing.T) {
+	t0 := newFuncTransport("a", nil)
+	t1 := newFuncTransport("b", nil)
+
+	m, err := NewMulti([]Transport{t0, t1}, &MultiConfig{Strategy: StrategyRoundRobin})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	for i := 0; i < 4; i++ {
+		var out string
+		assert.NoError(t, m.Call("eth_method", &out))
+	}
+	assert.Equal(t, uint64(2), t0.calls)
+	assert.Equal(t, uint64(2), t1.calls)
+}
+
+func TestMulti_Fallback(t *testing.T) {
+	t0 := newFuncTransport(nil, fmt.Errorf("connection refused"))
+	t1 := newFuncTransport("b", nil)
+
+	m, err := NewMulti([]Transport{t0, t1}, &MultiConfig{Strategy: StrategyFallback, MaxErrors: 2})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	for i := 0; i < 3; i++ {
+		var out string
+		assert.NoError(t, m.Call("eth_method", &out))
+		assert.Equal(t, "b", out)
+	}
+	// the primary is ejected after two errors
+	assert.Equal(t, uint64(2), t0.calls)
+	assert.Equal(t, uint64(3), t1.calls)
+
+	// jsonrpc errors are valid responses and do not fail over
+	t2 := newFuncTransport(nil, &codec.ErrorObject{Code: -32000, Message: "execution reverted"})
+	m2, err := NewMulti([]Transport{t2, t1}, &MultiConfig{Strategy: StrategyFallback})
+	assert.NoError(t, err)
+	defer m2.Close()
+
+	var out string
+	assert.Error(t, m2.Call("eth_method", &out))
+	assert.Equal(t, uint64(3), t1.calls)
+}
+
+func TestMulti_Quorum(t *testing.T) {
+	m, err := NewMulti([]Transport{
+		newFuncTransport("a", nil),
+		newFuncTransport("b", nil),
+		newFuncTransport("a", nil),
+	}, &MultiConfig{Strategy: StrategyQuorum, Quorum: 2})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	var out string
+	assert.NoError(t, m.Call("eth_method", &out))
+	assert.Equal(t, "a", out)
+
+	m, err = NewMulti([]Transport{
+		newFuncTransport("a", nil),
+		newFuncTransport("b", nil),
+		newFuncTransport(nil, fmt.Errorf("connection refused")),
+	}, &MultiConfig{Strategy: StrategyQuorum, Quorum: 2})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	err = m.Call("eth_method", &out)
+	assert.True(t, errors.Is(err, ErrNoQuorum))
+	assert.Contains(t, err.Error(), "connection refused")
+
+	// the endpoints agree on the same jsonrpc error
+	revertErr := &codec.ErrorObject{Code: 3, Message: "execution reverted", Data: "0x01"}
+	m, err = NewMulti([]Transport{
+		newFuncTransport(nil, revertErr),
+		newFuncTransport(nil, fmt.Errorf("connection refused")),
+		newFuncTransport(nil, &codec.ErrorObject{Code: 3, Message: "execution reverted", Data: "0x01"}),
+	}, &MultiConfig{Strategy: StrategyQuorum, Quorum: 2})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	err = m.Call("eth_method", &out)
+	var obj *codec.ErrorObject
+	assert.True(t, errors.As(err, &obj))
+	assert.Equal(t, revertErr, obj)
+}
+
+// blockTransport blocks the calls until the context is done
+type blockTransport struct {
+	funcTransport
+	done chan struct{}
+}
+
+func (b *blockTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	<-ctx.Done()
+	close(b.done)
+	return ctx.Err()
+}
+
+func TestMulti_QuorumCancelled(t *testing.T) {
+	slow := &blockTransport{done: make(chan struct{})}
+
+	m, err := NewMulti([]Transport{
+		newFuncTransport("a", nil),
+		newFuncTransport("a", nil),
+		slow,
+	}, &MultiConfig{Strategy: StrategyQuorum, Quorum: 2})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	m.endpoints[2].errors = 1
+
+	var out string
+	assert.NoError(t, m.Call("eth_method", &out))
+
+	select {
+	case <-slow.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// the cancelled call is not recorded as a success
+	m.endpoints[2].lock.Lock()
+	assert.Equal(t, 1, m.endpoints[2].errors)
+	m.endpoints[2].lock.Unlock()
+}
+
+func TestMulti_Batch(t *testing.T) {
+	t0 := newFuncTransport(nil, fmt.Errorf("connection refused"))
+	t1 := newFuncTransport("b", nil)
+
+	m, err := NewMulti([]Transport{t0, t1}, &MultiConfig{Strategy: StrategyFallback})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// all the elements fail on the first endpoint
+	var out0, out1 string
+	b := []BatchElem{
+		{Method: "eth_method", Result: &out0},
+		{Method: "eth_method", Result: &out1},
+	}
+	assert.NoError(t, m.BatchCallContext(context.Background(), b))
+	assert.NoError(t, b[0].Error)
+	assert.NoError(t, b[1].Error)
+	assert.Equal(t, "b", out0)
+	assert.Equal(t, "b", out1)
+
+	// the errors of the elements are valid responses
+	m, err = NewMulti([]Transport{newFuncTransport(nil, &codec.ErrorObject{Code: 3}), t1}, &MultiConfig{Strategy: StrategyFallback})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	calls := atomic.LoadUint64(&t1.calls)
+	assert.NoError(t, m.BatchCallContext(context.Background(), b))
+	assert.Error(t, b[0].Error)
+	assert.Equal(t, calls, atomic.LoadUint64(&t1.calls))
+}
+
+func TestMulti_Send(t *testing.T) {
+	// the transaction may have reached the first endpoint
+	t0 := newFuncTransport(nil, fmt.Errorf("connection reset"))
+	t1 := newFuncTransport("0x1", nil)
+
+	m, err := NewMulti([]Transport{t0, t1}, &MultiConfig{Strategy: StrategyFallback})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	var out string
+	assert.Error(t, m.Call("eth_sendRawTransaction", &out))
+	assert.Equal(t, uint64(0), t1.calls)
+
+	// the other methods fail over
+	assert.NoError(t, m.Call("eth_method", &out))
+
+	// the connection could not be established
+	t0 = newFuncTransport(nil, &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")})
+	m, err = NewMulti([]Transport{t0, t1}, &MultiConfig{Strategy: StrategyFallback})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	assert.NoError(t, m.Call("eth_sendRawTransaction", &out))
+	assert.Equal(t, "0x1", out)
+}
+
+func TestMulti_HealthCheck(t *testing.T) {
+	t0 := newFuncTransport("0x10", nil)
+	t1 := newFuncTransport("0x1", nil)
+	t2 := newFuncTransport(nil, fmt.Errorf("connection refused"))
+
+	m, err := NewMulti([]Transport{t0, t1, t2}, &MultiConfig{MaxBlockLag: 5})
+	assert.NoError(t, err)
+	defer m.Close()
+
+	m.healthCheck()
+
+	assert.True(t, m.endpoints[0].isHealthy())
+	// lags more than 5 blocks behind
+	assert.False(t, m.endpoints[1].isHealthy())
+	assert.False(t, m.endpoints[2].isHealthy())
+}
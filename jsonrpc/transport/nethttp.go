@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// NetHTTPConfig is the configuration of the net/http transport
+type NetHTTPConfig struct {
+	// Client is the http client that sends the requests. If set, the
+	// RoundTripper, TLS, proxy, timeout and compression options are ignored.
+	Client *http.Client
+
+	// RoundTripper is the transport of the http client. If set, the TLS,
+	// proxy and compression options are ignored.
+	RoundTripper http.RoundTripper
+
+	// TLSConfig is the tls configuration of the connections (i.e. mTLS)
+	TLSConfig *tls.Config
+
+	// Proxy returns the proxy of each request. If nil, the proxy
+	// of the environment is used.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// Timeout is the timeout of each request. Zero means no timeout.
+	Timeout time.Duration
+
+	// MaxConnsPerHost is the maximum number of connections with the endpoint.
+	// Zero means no limit.
+	MaxConnsPerHost int
+
+	// DisableCompression disables the gzip compression of the responses
+	DisableCompression bool
+
+	// GzipRequests compresses the body of the requests with gzip
+	GzipRequests bool
+
+	// Token returns the bearer token of each request (i.e. NewJWTTokenSource)
+	Token func() (string, error)
+}
+
+// NetHTTP is an http transport built on top of net/http
+type NetHTTP struct {
+	addr    string
+	headers map[string]string
+
+	// the transport is replaced by SetMaxConnsPerHost
+	lock      sync.RWMutex
+	client    *http.Client
+	transport *http.Transport
+
+	gzip  bool
+	token func() (string, error)
+}
+
+// NewNetHTTP creates a new net/http transport
+func NewNetHTTP(addr string, headers map[string]string, config *NetHTTPConfig) *NetHTTP {
+	if config == nil {
+		config = &NetHTTPConfig{}
+	}
+	n := &NetHTTP{
+		addr:    addr,
+		headers: headers,
+		gzip:    config.GzipRequests,
+		token:   config.Token,
+		client:  config.Client,
+	}
+	if n.client == nil {
+		rt := config.RoundTripper
+		if rt == nil {
+			proxy := config.Proxy
+			if proxy == nil {
+				proxy = http.ProxyFromEnvironment
+			}
+			n.transport = &http.Transport{
+				Proxy:               proxy,
+				TLSClientConfig:     config.TLSConfig,
+				DisableCompression:  config.DisableCompression,
+				MaxIdleConnsPerHost: 16,
+				MaxConnsPerHost:     config.MaxConnsPerHost,
+				IdleConnTimeout:     90 * time.Second,
+			}
+			rt = n.transport
+		}
+		n.client = &http.Client{
+			Transport: rt,
+			Timeout:   config.Timeout,
+		}
+	}
+	return n
+}
+
+// Close implements the transport interface
+func (n *NetHTTP) Close() error {
+	n.lock.RLock()
+	transport := n.transport
+	n.lock.RUnlock()
+
+	if transport != nil {
+		transport.CloseIdleConnections()
+	}
+	return nil
+}
+
+// IsClosed implements the transport interface
+func (n *NetHTTP) IsClosed() bool {
+	return false
+}
+
+// SetMaxConnsPerHost implements the transport interface. It only applies
+// if the transport created the http client. The http transport cannot be
+// modified while it is in use, the new requests use a copy with the limit
+// while the in-flight ones finish with the previous one.
+func (n *NetHTTP) SetMaxConnsPerHost(count int) {
+	n.lock.Lock()
+	if n.transport == nil {
+		n.lock.Unlock()
+		return
+	}
+	prev := n.transport
+
+	n.transport = prev.Clone()
+	n.transport.MaxConnsPerHost = count
+	n.client = &http.Client{
+		Transport: n.transport,
+		Timeout:   n.client.Timeout,
+	}
+	n.lock.Unlock()
+
+	prev.CloseIdleConnections()
+}
+
+// Call implements the transport interface
+func (n *NetHTTP) Call(method string, out interface{}, params ...interface{}) error {
+	return n.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (n *NetHTTP) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	return httpCall(ctx, n.do, method, out, params)
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (n *NetHTTP) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	return httpBatchCall(ctx, n.do, b)
+}
+
+func (n *NetHTTP) do(ctx context.Context, raw []byte) ([]byte, error) {
+	if n.gzip {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		raw = buf.Bytes()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.addr, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	if n.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range n.headers {
+		req.Header.Add(k, v)
+	}
+	if n.token != nil {
+		token, err := n.token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the auth token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for k, v := range requestHeaders(ctx) {
+		req.Header.Set(k, v)
+	}
+
+	n.lock.RLock()
+	client := n.client
+	n.lock.RUnlock()
+
+	res, err := client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return checkStatus(res.StatusCode, body)
+}
+
+// jwtRefresh is the age of a token before it is refreshed. The engine
+// API rejects the tokens issued more than 60 seconds ago.
+const jwtRefresh = 30 * time.Second
+
+// NewJWTTokenSource returns a token source that issues HS256 tokens signed
+// with the secret and an 'iat' claim, as required by the engine API.
+// The tokens are refreshed before they expire.
+func NewJWTTokenSource(secret []byte) func() (string, error) {
+	var lock sync.Mutex
+	var token string
+	var issued time.Time
+
+	return func() (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		now := time.Now()
+		if token != "" && now.Sub(issued) < jwtRefresh {
+			return token, nil
+		}
+		token, issued = signJWT(secret, now), now
+		return token, nil
+	}
+}
+
+func signJWT(secret []byte, now time.Time) string {
+	enc := base64.RawURLEncoding
+
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := enc.EncodeToString([]byte(fmt.Sprintf(`{"iat":%d}`, now.Unix())))
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(header + "." + claims))
+	return header + "." + claims + "." + enc.EncodeToString(mac.Sum(nil))
+}
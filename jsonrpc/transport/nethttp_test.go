@@ -0,0 +1,140 @@
+package transport
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetHTTP_Call(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Delay") != "" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+
+	// the tls client of the test server trusts its certificate
+	n := NewNetHTTP(srv.URL, map[string]string{}, &NetHTTPConfig{Client: srv.Client()})
+
+	var out string
+	assert.NoError(t, n.Call("eth_blockNumber", &out))
+	assert.Equal(t, "0x1", out)
+
+	// the default client does not trust the certificate
+	n = NewNetHTTP(srv.URL, map[string]string{}, nil)
+	assert.Error(t, n.Call("eth_blockNumber", &out))
+
+	n = NewNetHTTP(srv.URL, map[string]string{"X-Delay": "true"}, &NetHTTPConfig{Client: srv.Client()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, n.CallContext(ctx, "eth_blockNumber", &out))
+}
+
+func TestNetHTTP_Auth(t *testing.T) {
+	secret := []byte("secret")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(parts[0] + "." + parts[1]))
+		if base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) != parts[2] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = zr
+		}
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "eth_blockNumber") {
+			t.Fatal("bad body")
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+
+	var out string
+
+	n := NewNetHTTP(srv.URL, map[string]string{}, nil)
+	err := n.Call("eth_blockNumber", &out)
+
+	httpErr, ok := err.(*HTTPError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+
+	n = NewNetHTTP(srv.URL, map[string]string{}, &NetHTTPConfig{
+		Token:        NewJWTTokenSource(secret),
+		GzipRequests: true,
+	})
+	assert.NoError(t, n.Call("eth_blockNumber", &out))
+	assert.Equal(t, "0x1", out)
+}
+
+func TestNetHTTP_JWTTokenSource(t *testing.T) {
+	source := NewJWTTokenSource([]byte("secret"))
+
+	token1, err := source()
+	assert.NoError(t, err)
+	token2, err := source()
+	assert.NoError(t, err)
+
+	// the token is cached
+	assert.Equal(t, token1, token2)
+
+	claims, err := base64.RawURLEncoding.DecodeString(strings.Split(token1, ".")[1])
+	assert.NoError(t, err)
+	assert.Contains(t, string(claims), `"iat":`)
+}
+
+func TestNetHTTP_SetMaxConnsPerHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+
+	n := NewNetHTTP(srv.URL, map[string]string{}, &NetHTTPConfig{MaxConnsPerHost: 2})
+	assert.Equal(t, 2, n.transport.MaxConnsPerHost)
+
+	// the limit can change while the transport is in use
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out string
+			assert.NoError(t, n.Call("eth_blockNumber", &out))
+		}()
+	}
+	n.SetMaxConnsPerHost(4)
+	wg.Wait()
+
+	assert.Equal(t, 4, n.transport.MaxConnsPerHost)
+}
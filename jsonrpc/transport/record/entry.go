@@ -0,0 +1,71 @@
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// entryType is the type of an entry of the fixture
+type entryType string
+
+const (
+	entryCall         entryType = "call"
+	entrySubscribe    entryType = "subscribe"
+	entryNotification entryType = "notification"
+	entryUnsubscribe  entryType = "unsubscribe"
+)
+
+// entry is a line of the fixture file
+type entry struct {
+	Type entryType `json:"type"`
+
+	// Method and Params of a call or a subscription
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+
+	// Result of a call or a notification
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error is the jsonrpc error of a call
+	Error *codec.ErrorObject `json:"error,omitempty"`
+
+	// TransportError is any other error of a call (i.e. a timeout)
+	TransportError string `json:"transportError,omitempty"`
+
+	// Subscription is the id of the subscription assigned by the recorder
+	Subscription string `json:"subscription,omitempty"`
+}
+
+// err returns the error of the recorded call
+func (e *entry) err() error {
+	if e.Error != nil {
+		return e.Error
+	}
+	if e.TransportError != "" {
+		return errors.New(e.TransportError)
+	}
+	return nil
+}
+
+// encodeParams returns the canonical json of the params
+func encodeParams(params interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(raw json.RawMessage, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
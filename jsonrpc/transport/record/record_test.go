@@ -0,0 +1,156 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// fakeTransport returns the method and the first param as the result
+// and pushes notifications to the subscriptions on demand
+type fakeTransport struct {
+	subs []func(b []byte)
+}
+
+func (f *fakeTransport) Call(method string, out interface{}, params ...interface{}) error {
+	return f.CallContext(context.Background(), method, out, params...)
+}
+
+func (f *fakeTransport) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if method == "eth_fail" {
+		return &codec.ErrorObject{Code: -32000, Message: "failed"}
+	}
+	res := method
+	if len(params) != 0 {
+		res = fmt.Sprintf("%s-%v", method, params[0])
+	}
+	data, _ := json.Marshal(res)
+	return json.Unmarshal(data, out)
+}
+
+func (f *fakeTransport) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	f.subs = append(f.subs, callback)
+	return func() error { return nil }, nil
+}
+
+func (f *fakeTransport) notify(i int, msg string) {
+	f.subs[i]([]byte(`"` + msg + `"`))
+}
+
+func (f *fakeTransport) SetMaxConnsPerHost(count int) {}
+
+func (f *fakeTransport) Close() error { return nil }
+
+func (f *fakeTransport) IsClosed() bool { return false }
+
+func record(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	fake := &fakeTransport{}
+	r := NewRecorder(fake, &buf)
+
+	var out string
+	assert.NoError(t, r.Call("eth_a", &out, 1))
+	assert.Equal(t, "eth_a-1", out)
+
+	_, err := r.Subscribe("newHeads", nil, func(b []byte) {})
+	assert.NoError(t, err)
+	fake.notify(0, "h1")
+
+	batch := []transport.BatchElem{
+		{Method: "eth_b", Result: &out},
+		{Method: "eth_fail", Result: &out},
+	}
+	assert.NoError(t, r.BatchCallContext(context.Background(), batch))
+	assert.NoError(t, batch[0].Error)
+	assert.Error(t, batch[1].Error)
+
+	fake.notify(0, "h2")
+	fake.notify(0, "h3")
+	assert.NoError(t, r.Call("eth_a", &out, 2))
+
+	assert.NoError(t, r.Err())
+	return &buf
+}
+
+func TestReplay_Strict(t *testing.T) {
+	r, err := NewReplayer(record(t), MatchStrict)
+	assert.NoError(t, err)
+
+	events := []string{}
+
+	var out string
+	assert.NoError(t, r.Call("eth_a", &out, 1))
+	assert.Equal(t, "eth_a-1", out)
+
+	_, err = r.Subscribe("newHeads", nil, func(b []byte) {
+		var head string
+		assert.NoError(t, json.Unmarshal(b, &head))
+		events = append(events, head)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"h1"}, events)
+
+	assert.NoError(t, r.Call("eth_b", &out))
+	assert.Equal(t, "eth_b", out)
+
+	err = r.Call("eth_fail", &out)
+	obj, ok := err.(*codec.ErrorObject)
+	assert.True(t, ok)
+	assert.Equal(t, -32000, obj.Code)
+	assert.Equal(t, []string{"h1", "h2", "h3"}, events)
+
+	// the params do not match
+	assert.Error(t, r.Call("eth_a", &out, 3))
+	assert.NoError(t, r.Call("eth_a", &out, 2))
+	assert.Equal(t, "eth_a-2", out)
+
+	// no more requests
+	assert.Error(t, r.Call("eth_a", &out, 2))
+}
+
+func TestReplay_Lenient(t *testing.T) {
+	r, err := NewReplayer(record(t), MatchLenient)
+	assert.NoError(t, err)
+
+	var out string
+	assert.NoError(t, r.Call("eth_a", &out, 2))
+	assert.Equal(t, "eth_a-2", out)
+	assert.NoError(t, r.Call("eth_a", &out, 1))
+	assert.Equal(t, "eth_a-1", out)
+
+	// the last match is reused
+	assert.NoError(t, r.Call("eth_a", &out, 1))
+	assert.Equal(t, "eth_a-1", out)
+
+	assert.Error(t, r.Call("eth_a", &out, 3))
+	assert.Error(t, r.Call("eth_c", &out))
+
+	heads := make(chan string, 3)
+	_, err = r.Subscribe("newHeads", nil, func(b []byte) {
+		var head string
+		assert.NoError(t, json.Unmarshal(b, &head))
+		heads <- head
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "h1", <-heads)
+	assert.Equal(t, "h2", <-heads)
+	assert.Equal(t, "h3", <-heads)
+}
+
+func TestReplay_Method(t *testing.T) {
+	r, err := NewReplayer(record(t), MatchMethod)
+	assert.NoError(t, err)
+
+	var out string
+	assert.NoError(t, r.Call("eth_a", &out, 5))
+	assert.Equal(t, "eth_a-1", out)
+	assert.NoError(t, r.Call("eth_a", &out, 5))
+	assert.Equal(t, "eth_a-2", out)
+}
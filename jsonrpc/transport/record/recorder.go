@@ -0,0 +1,209 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// Recorder is a transport that records the requests and responses
+// of the wrapped transport as json lines
+type Recorder struct {
+	transport transport.Transport
+
+	lock   sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	subSeq uint64
+	err    error
+}
+
+// NewRecorder records the requests of the transport in the writer
+func NewRecorder(t transport.Transport, w io.Writer) *Recorder {
+	return &Recorder{
+		transport: t,
+		w:         w,
+	}
+}
+
+// NewFileRecorder records the requests of the transport in the file at path
+func NewFileRecorder(t transport.Transport, path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecorder(t, f)
+	r.closer = f
+	return r, nil
+}
+
+// Err returns the first error writing the fixture
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.err
+}
+
+func (r *Recorder) write(e *entry) {
+	data, err := json.Marshal(e)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err == nil {
+		_, err = r.w.Write(append(data, '\n'))
+	}
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) recordCall(method string, params []interface{}, result json.RawMessage, err error) {
+	e := &entry{
+		Type:   entryCall,
+		Method: method,
+		Result: result,
+	}
+	if len(params) != 0 {
+		e.Params, _ = encodeParams(params)
+	}
+	if err != nil {
+		e.Result = nil
+		if obj, ok := err.(*codec.ErrorObject); ok {
+			e.Error = obj
+		} else {
+			e.TransportError = err.Error()
+		}
+	}
+	r.write(e)
+}
+
+// Close implements the transport interface
+func (r *Recorder) Close() error {
+	err := r.transport.Close()
+	if r.closer != nil {
+		if cErr := r.closer.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+// IsClosed implements the transport interface
+func (r *Recorder) IsClosed() bool {
+	return r.transport.IsClosed()
+}
+
+// SetMaxConnsPerHost implements the transport interface
+func (r *Recorder) SetMaxConnsPerHost(count int) {
+	r.transport.SetMaxConnsPerHost(count)
+}
+
+// Call implements the transport interface
+func (r *Recorder) Call(method string, out interface{}, params ...interface{}) error {
+	var raw json.RawMessage
+	err := r.transport.Call(method, &raw, params...)
+	r.recordCall(method, params, raw, err)
+	if err != nil {
+		return err
+	}
+	return decode(raw, out)
+}
+
+// CallContext implements the transport interface
+func (r *Recorder) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	var raw json.RawMessage
+	err := r.transport.CallContext(ctx, method, &raw, params...)
+	r.recordCall(method, params, raw, err)
+	if err != nil {
+		return err
+	}
+	return decode(raw, out)
+}
+
+// BatchCallContext implements the BatchTransport interface. Each
+// element of the batch is recorded as a call.
+func (r *Recorder) BatchCallContext(ctx context.Context, b []transport.BatchElem) error {
+	raws := make([]json.RawMessage, len(b))
+	elems := make([]transport.BatchElem, len(b))
+	for i, elem := range b {
+		elems[i] = transport.BatchElem{Method: elem.Method, Params: elem.Params, Result: &raws[i]}
+	}
+
+	if batch, ok := r.transport.(transport.BatchTransport); ok {
+		if err := batch.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+	} else {
+		for i := range elems {
+			elems[i].Error = r.transport.CallContext(ctx, elems[i].Method, elems[i].Result, elems[i].Params...)
+		}
+	}
+
+	for i := range b {
+		r.recordCall(b[i].Method, b[i].Params, raws[i], elems[i].Error)
+		if elems[i].Error != nil {
+			b[i].Error = elems[i].Error
+		} else {
+			b[i].Error = decode(raws[i], b[i].Result)
+		}
+	}
+	return nil
+}
+
+// Subscribe implements the PubSubTransport interface
+func (r *Recorder) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	pub, ok := r.transport.(transport.PubSubTransport)
+	if !ok {
+		return nil, fmt.Errorf("transport does not support the subscribe method")
+	}
+
+	r.lock.Lock()
+	r.subSeq++
+	id := strconv.FormatUint(r.subSeq, 10)
+	r.lock.Unlock()
+
+	e := &entry{
+		Type:         entrySubscribe,
+		Method:       method,
+		Subscription: id,
+	}
+	if params != nil {
+		e.Params, _ = encodeParams(params)
+	}
+
+	// the subscribe entry is written before any notification
+	var once sync.Once
+	writeSubscribe := func() {
+		once.Do(func() {
+			r.write(e)
+		})
+	}
+
+	cancel, err := pub.Subscribe(method, params, func(b []byte) {
+		writeSubscribe()
+		r.write(&entry{
+			Type:         entryNotification,
+			Subscription: id,
+			Result:       append(json.RawMessage{}, b...),
+		})
+		callback(b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeSubscribe()
+
+	return func() error {
+		r.write(&entry{Type: entryUnsubscribe, Subscription: id})
+		return cancel()
+	}, nil
+}
@@ -0,0 +1,274 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+)
+
+// Matching is how the replayer matches the requests with the recorded ones
+type Matching int
+
+const (
+	// MatchStrict replays the requests in the recorded order. Any request
+	// with a different method or params fails. The notifications are
+	// delivered in the recorded order with respect to the calls.
+	MatchStrict Matching = iota
+
+	// MatchLenient replays the first unused recorded request with the same
+	// method and params in any order. Once all of them are used, the last
+	// one is replayed again.
+	MatchLenient
+
+	// MatchMethod is like MatchLenient but it ignores the params
+	MatchMethod
+)
+
+// Replayer is a transport that replays the requests recorded by a Recorder
+type Replayer struct {
+	matching Matching
+
+	lock    sync.Mutex
+	entries []*entry
+	used    []bool
+	cursor  int
+	subs    map[string]func(b []byte)
+	closed  bool
+}
+
+// NewReplayer creates a replayer with the fixture read from r
+func NewReplayer(r io.Reader, matching Matching) (*Replayer, error) {
+	entries := []*entry{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("failed to decode entry %d: %v", len(entries), err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Replayer{
+		matching: matching,
+		entries:  entries,
+		used:     make([]bool, len(entries)),
+		subs:     map[string]func(b []byte){},
+	}, nil
+}
+
+// NewFileReplayer creates a replayer with the fixture at path
+func NewFileReplayer(path string, matching Matching) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewReplayer(f, matching)
+}
+
+// Close implements the transport interface
+func (r *Replayer) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closed = true
+	return nil
+}
+
+// IsClosed implements the transport interface
+func (r *Replayer) IsClosed() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.closed
+}
+
+// SetMaxConnsPerHost implements the transport interface
+func (r *Replayer) SetMaxConnsPerHost(count int) {
+}
+
+// Call implements the transport interface
+func (r *Replayer) Call(method string, out interface{}, params ...interface{}) error {
+	return r.CallContext(context.Background(), method, out, params...)
+}
+
+// CallContext implements the transport interface
+func (r *Replayer) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	var raw json.RawMessage
+	if len(params) != 0 {
+		data, err := encodeParams(params)
+		if err != nil {
+			return err
+		}
+		raw = data
+	}
+
+	e, notifications, err := r.match(entryCall, method, raw)
+	if err != nil {
+		return err
+	}
+	r.deliver(notifications)
+
+	if err := e.err(); err != nil {
+		return err
+	}
+	return decode(e.Result, out)
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (r *Replayer) BatchCallContext(ctx context.Context, b []transport.BatchElem) error {
+	for i := range b {
+		b[i].Error = r.CallContext(ctx, b[i].Method, b[i].Result, b[i].Params...)
+	}
+	return nil
+}
+
+// Subscribe implements the PubSubTransport interface
+func (r *Replayer) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	var raw json.RawMessage
+	if params != nil {
+		data, err := encodeParams(params)
+		if err != nil {
+			return nil, err
+		}
+		raw = data
+	}
+
+	r.lock.Lock()
+	e, notifications, err := r.matchLocked(entrySubscribe, method, raw)
+	if err != nil {
+		r.lock.Unlock()
+		return nil, err
+	}
+	id := e.Subscription
+	r.subs[id] = callback
+	if r.matching != MatchStrict {
+		// deliver all the notifications of the subscription
+		for i, n := range r.entries {
+			if n.Type == entryNotification && n.Subscription == id && !r.used[i] {
+				r.used[i] = true
+				notifications = append(notifications, n)
+			}
+		}
+	}
+	r.lock.Unlock()
+
+	if r.matching == MatchStrict {
+		r.deliver(notifications)
+	} else {
+		go r.deliver(notifications)
+	}
+
+	cancel := func() error {
+		r.lock.Lock()
+		delete(r.subs, id)
+		r.lock.Unlock()
+		return nil
+	}
+	return cancel, nil
+}
+
+// deliver sends the notifications to the callbacks of their subscriptions
+func (r *Replayer) deliver(notifications []*entry) {
+	for _, n := range notifications {
+		r.lock.Lock()
+		callback, ok := r.subs[n.Subscription]
+		r.lock.Unlock()
+
+		if ok {
+			callback(n.Result)
+		}
+	}
+}
+
+func (r *Replayer) match(typ entryType, method string, params json.RawMessage) (*entry, []*entry, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.matchLocked(typ, method, params)
+}
+
+// matchLocked finds the recorded entry of the request. In strict mode, it also
+// returns the notifications recorded right after it.
+func (r *Replayer) matchLocked(typ entryType, method string, params json.RawMessage) (*entry, []*entry, error) {
+	if r.closed {
+		return nil, nil, transport.ErrClosed
+	}
+
+	if r.matching == MatchStrict {
+		r.skip()
+		if r.cursor >= len(r.entries) {
+			return nil, nil, fmt.Errorf("replay: unexpected %s %s, no more recorded requests", typ, method)
+		}
+		e := r.entries[r.cursor]
+		if e.Type != typ || e.Method != method || !bytes.Equal(e.Params, params) {
+			return nil, nil, fmt.Errorf("replay: unexpected %s %s %s, recorded %s %s %s", typ, method, string(params), e.Type, e.Method, string(e.Params))
+		}
+		r.used[r.cursor] = true
+		r.cursor++
+
+		notifications := []*entry{}
+		for r.cursor < len(r.entries) {
+			n := r.entries[r.cursor]
+			if n.Type == entryUnsubscribe {
+				r.cursor++
+				continue
+			}
+			if n.Type != entryNotification {
+				break
+			}
+			r.used[r.cursor] = true
+			notifications = append(notifications, n)
+			r.cursor++
+		}
+		return e, notifications, nil
+	}
+
+	last := -1
+	for i, e := range r.entries {
+		if e.Type != typ || e.Method != method {
+			continue
+		}
+		if r.matching == MatchLenient && !bytes.Equal(e.Params, params) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return e, nil, nil
+		}
+		last = i
+	}
+	if last == -1 || typ == entrySubscribe {
+		return nil, nil, fmt.Errorf("replay: no recorded %s %s %s", typ, method, string(params))
+	}
+	return r.entries[last], nil, nil
+}
+
+// skip moves the cursor over the unsubscribe entries and the
+// notifications of subscriptions not started in the replay
+func (r *Replayer) skip() {
+	for r.cursor < len(r.entries) {
+		e := r.entries[r.cursor]
+		if e.Type != entryUnsubscribe && e.Type != entryNotification {
+			return
+		}
+		r.cursor++
+	}
+}
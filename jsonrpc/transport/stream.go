@@ -0,0 +1,841 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+var (
+	// ErrTimeout happens when the websocket requests times out
+	ErrTimeout = fmt.Errorf("timeout")
+
+	// ErrClosed happens when the transport is closed
+	ErrClosed = fmt.Errorf("transport closed")
+
+	// ErrConnectionLost happens when the connection drops while a request is in-flight
+	ErrConnectionLost = fmt.Errorf("connection lost")
+)
+
+// defaultTimeout is the timeout used by the calls without a context
+const defaultTimeout = 30 * time.Second
+
+// ConnState is the state of the connection of a stream transport
+type ConnState int
+
+const (
+	// ConnStateConnected happens when the connection is (re)established
+	ConnStateConnected ConnState = iota
+	// ConnStateDisconnected happens when the connection drops
+	ConnStateDisconnected
+	// ConnStateClosed happens when the transport is closed or it stops reconnecting
+	ConnStateClosed
+)
+
+func (c ConnState) String() string {
+	switch c {
+	case ConnStateConnected:
+		return "connected"
+	case ConnStateDisconnected:
+		return "disconnected"
+	case ConnStateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("ConnState(%d)", int(c))
+	}
+}
+
+// ConnStateTransport is a transport that notifies the changes
+// on the state of its connection
+type ConnStateTransport interface {
+	// OnConnState registers a hook that is called every time the state of the
+	// connection changes. It returns a function to remove the hook.
+	OnConnState(hook func(state ConnState)) func()
+}
+
+// ReconnectConfig is the configuration to reconnect a stream transport
+// after the connection drops
+type ReconnectConfig struct {
+	// MinBackoff is the wait before the first reconnect attempt
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum wait between reconnect attempts
+	MaxBackoff time.Duration
+
+	// MaxAttempts is the number of consecutive failed attempts before the
+	// transport is closed. Zero means it never gives up.
+	MaxAttempts int
+}
+
+// DefaultReconnectConfig returns the default reconnect configuration
+func DefaultReconnectConfig() *ReconnectConfig {
+	return &ReconnectConfig{
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		MaxAttempts: 0,
+	}
+}
+
+func (r *ReconnectConfig) backoff(attempt int) time.Duration {
+	backoff := r.MinBackoff
+	for i := 0; i < attempt && backoff < r.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.MaxBackoff {
+		backoff = r.MaxBackoff
+	}
+	return backoff
+}
+
+type ackMessage struct {
+	buf []byte
+	err error
+}
+
+type callback func(b []byte, err error)
+
+// dialFn opens a new connection for a stream
+type dialFn func() (Codec, error)
+
+// subscriptionQueueSize is the number of notifications buffered for each
+// subscription. The stream stops reading from the connection while the
+// queue of a subscription is full.
+const subscriptionQueueSize = 128
+
+// maxEarlySubscriptions is the number of unknown subscriptions whose
+// notifications are held until the subscription is registered
+const maxEarlySubscriptions = 16
+
+type subscription struct {
+	// id is the id assigned by the server, it changes after a reconnect
+	id       string
+	method   string
+	params   interface{}
+	callback func(b []byte)
+
+	queue     chan []byte
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func newSubscription(id, method string, params interface{}, callback func(b []byte)) *subscription {
+	return &subscription{
+		id:       id,
+		method:   method,
+		params:   params,
+		callback: callback,
+		queue:    make(chan []byte, subscriptionQueueSize),
+		closeCh:  make(chan struct{}),
+	}
+}
+
+// run delivers the notifications to the callback in order
+func (s *subscription) run() {
+	for {
+		select {
+		case buf := <-s.queue:
+			s.callback(buf)
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+func (s *subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
+}
+
+type stream struct {
+	seq uint64
+
+	dial      dialFn
+	reconnect *ReconnectConfig
+
+	// connection state
+	connLock sync.Mutex
+	codec    Codec
+	state    ConnState
+	readyCh  chan struct{}
+	closeCh  chan struct{}
+
+	// call handlers
+	handlerLock sync.Mutex
+	handler     map[uint64]callback
+
+	// subscriptions
+	subsLock sync.Mutex
+	subs     map[string]*subscription
+
+	// early holds the notifications that arrive before the subscription is
+	// registered, the server can send them right after the subscribe response
+	early map[string][][]byte
+
+	// connection state hooks
+	hooksLock sync.Mutex
+	hooks     map[uint64]func(ConnState)
+	hooksSeq  uint64
+
+	// inFlight bounds the requests waiting for a response, nil if there is no limit
+	inFlightLock sync.Mutex
+	inFlight     chan struct{}
+}
+
+// newStream dials a new stream. If reconnect is not nil, the stream
+// dials a new connection every time the current one drops.
+func newStream(dial dialFn, reconnect *ReconnectConfig) (*stream, error) {
+	codec, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &stream{
+		dial:      dial,
+		reconnect: reconnect,
+		codec:     codec,
+		state:     ConnStateConnected,
+		readyCh:   make(chan struct{}),
+		closeCh:   make(chan struct{}),
+		handler:   map[uint64]callback{},
+		subs:      map[string]*subscription{},
+		early:     map[string][][]byte{},
+		hooks:     map[uint64]func(ConnState){},
+	}
+	close(s.readyCh)
+
+	go s.listen(codec)
+	return s, nil
+}
+
+// Close implements the the transport interface
+func (s *stream) Close() error {
+	s.connLock.Lock()
+	if s.state == ConnStateClosed {
+		s.connLock.Unlock()
+		return nil
+	}
+	s.state = ConnStateClosed
+	close(s.closeCh)
+	codec := s.codec
+	s.connLock.Unlock()
+
+	err := codec.Close()
+	s.failHandlers(ErrClosed)
+	s.closeSubscriptions()
+	s.notify(ConnStateClosed)
+	return err
+}
+
+func (s *stream) incSeq() uint64 {
+	return atomic.AddUint64(&s.seq, 1)
+}
+
+func (s *stream) IsClosed() bool {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	return s.state == ConnStateClosed
+}
+
+// OnConnState implements the ConnStateTransport interface
+func (s *stream) OnConnState(hook func(state ConnState)) func() {
+	s.hooksLock.Lock()
+	defer s.hooksLock.Unlock()
+
+	s.hooksSeq++
+	id := s.hooksSeq
+	s.hooks[id] = hook
+
+	return func() {
+		s.hooksLock.Lock()
+		delete(s.hooks, id)
+		s.hooksLock.Unlock()
+	}
+}
+
+func (s *stream) notify(state ConnState) {
+	s.hooksLock.Lock()
+	hooks := make([]func(ConnState), 0, len(s.hooks))
+	for _, hook := range s.hooks {
+		hooks = append(hooks, hook)
+	}
+	s.hooksLock.Unlock()
+
+	for _, hook := range hooks {
+		hook(state)
+	}
+}
+
+// getCodec returns the codec of the current connection. It waits
+// for the stream to reconnect if the connection is down.
+func (s *stream) getCodec(ctx context.Context) (Codec, error) {
+	for {
+		s.connLock.Lock()
+		state, codec, readyCh := s.state, s.codec, s.readyCh
+		s.connLock.Unlock()
+
+		switch state {
+		case ConnStateConnected:
+			return codec, nil
+		case ConnStateClosed:
+			return nil, ErrClosed
+		}
+
+		select {
+		case <-readyCh:
+		case <-s.closeCh:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *stream) listen(codec Codec) {
+	buf := []byte{}
+
+	for {
+		var err error
+		buf, err = codec.Read(buf[:0])
+		if err != nil {
+			s.handleDisconnect(codec, err)
+			return
+		}
+
+		if err := s.handleMessage(buf); err != nil {
+			// the connection is in an unknown state, drop it
+			s.handleDisconnect(codec, err)
+			return
+		}
+	}
+}
+
+func (s *stream) handleDisconnect(codec Codec, err error) {
+	s.connLock.Lock()
+	if s.state != ConnStateConnected || s.codec != codec {
+		// closed by the user
+		s.connLock.Unlock()
+		return
+	}
+	codec.Close()
+
+	if s.reconnect == nil {
+		s.state = ConnStateClosed
+		close(s.closeCh)
+	} else {
+		s.state = ConnStateDisconnected
+		s.readyCh = make(chan struct{})
+	}
+	state := s.state
+	s.connLock.Unlock()
+
+	s.failHandlers(fmt.Errorf("%w: %v", ErrConnectionLost, err))
+	s.notify(ConnStateDisconnected)
+
+	if state == ConnStateClosed {
+		s.closeSubscriptions()
+		s.notify(ConnStateClosed)
+		return
+	}
+	go s.reconnectLoop()
+}
+
+func (s *stream) reconnectLoop() {
+	for attempt := 0; ; attempt++ {
+		if s.reconnect.MaxAttempts != 0 && attempt >= s.reconnect.MaxAttempts {
+			s.Close()
+			return
+		}
+
+		select {
+		case <-time.After(s.reconnect.backoff(attempt)):
+		case <-s.closeCh:
+			return
+		}
+
+		codec, err := s.dial()
+		if err != nil {
+			continue
+		}
+
+		s.connLock.Lock()
+		if s.state == ConnStateClosed {
+			s.connLock.Unlock()
+			codec.Close()
+			return
+		}
+		s.codec = codec
+		s.state = ConnStateConnected
+		close(s.readyCh)
+		s.connLock.Unlock()
+
+		go s.listen(codec)
+
+		s.resubscribe()
+		s.notify(ConnStateConnected)
+		return
+	}
+}
+
+// resubscribe starts again the active subscriptions after a reconnect
+// and maps the new ids to the existing callbacks
+func (s *stream) resubscribe() {
+	s.subsLock.Lock()
+	subs := make([]*subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.subsLock.Unlock()
+
+	for _, sub := range subs {
+		id, err := s.subscribe(sub.method, sub.params)
+		if err != nil {
+			var obj *codec.ErrorObject
+			if !errors.As(err, &obj) {
+				// the connection dropped again, the next reconnect retries it
+				return
+			}
+
+			// the server rejected the subscription, the others can still be started
+			s.subsLock.Lock()
+			if _, ok := s.subs[sub.id]; ok {
+				delete(s.subs, sub.id)
+				sub.close()
+			}
+			s.subsLock.Unlock()
+			continue
+		}
+
+		s.subsLock.Lock()
+		if _, ok := s.subs[sub.id]; ok {
+			delete(s.subs, sub.id)
+			sub.id = id
+			s.addSubscription(id, sub)
+		}
+		s.subsLock.Unlock()
+	}
+}
+
+// closeSubscriptions stops the delivery of notifications of all the subscriptions
+func (s *stream) closeSubscriptions() {
+	s.subsLock.Lock()
+	defer s.subsLock.Unlock()
+
+	for _, sub := range s.subs {
+		sub.close()
+	}
+}
+
+// failHandlers fails all the in-flight calls
+func (s *stream) failHandlers(err error) {
+	s.handlerLock.Lock()
+	handlers := s.handler
+	s.handler = map[uint64]callback{}
+	s.handlerLock.Unlock()
+
+	for _, callback := range handlers {
+		callback(nil, err)
+	}
+}
+
+func (s *stream) handleMessage(buf []byte) error {
+	if isBatch(buf) {
+		// response to a batch request
+		var msgs []json.RawMessage
+		if err := json.Unmarshal(buf, &msgs); err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := s.handleMessage(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var resp codec.Response
+	if err := json.Unmarshal(buf, &resp); err != nil {
+		return err
+	}
+
+	if resp.ID != 0 {
+		go s.handleMsg(resp)
+	} else {
+		// handle subscription
+		var respSub codec.Request
+		if err := json.Unmarshal(buf, &respSub); err != nil {
+			return err
+		}
+
+		if respSub.Method == "eth_subscription" {
+			s.handleSubscription(respSub)
+		}
+	}
+	return nil
+}
+
+// isBatch returns true if the message is a json array
+func isBatch(buf []byte) bool {
+	for _, c := range buf {
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+		return c == '['
+	}
+	return false
+}
+
+func (s *stream) handleSubscription(response codec.Request) {
+	var sub codec.Subscription
+	if err := json.Unmarshal(response.Params, &sub); err != nil {
+		// malformed notification
+		return
+	}
+
+	s.subsLock.Lock()
+	subscription, ok := s.subs[sub.ID]
+	if !ok {
+		s.holdEarly(sub.ID, sub.Result)
+	}
+	s.subsLock.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// queue the notification for the callback
+	select {
+	case subscription.queue <- sub.Result:
+	case <-subscription.closeCh:
+	case <-s.closeCh:
+	}
+}
+
+// holdEarly keeps a notification of an unknown subscription. It must
+// be called with the subsLock held.
+func (s *stream) holdEarly(id string, result []byte) {
+	msgs, ok := s.early[id]
+	if !ok && len(s.early) >= maxEarlySubscriptions {
+		// most likely notifications of subscriptions already removed
+		for k := range s.early {
+			delete(s.early, k)
+			break
+		}
+	}
+	if len(msgs) < subscriptionQueueSize {
+		s.early[id] = append(msgs, result)
+	}
+}
+
+// addSubscription registers the subscription with the id and queues the notifications
+// that arrived before. It must be called with the subsLock held.
+func (s *stream) addSubscription(id string, sub *subscription) {
+	s.subs[id] = sub
+	msgs := s.early[id]
+	delete(s.early, id)
+
+	for _, msg := range msgs {
+		select {
+		case sub.queue <- msg:
+		case <-sub.closeCh:
+			return
+		}
+	}
+}
+
+func (s *stream) handleMsg(response codec.Response) {
+	s.handlerLock.Lock()
+	callback, ok := s.handler[response.ID]
+	if !ok {
+		s.handlerLock.Unlock()
+		return
+	}
+
+	// delete handler
+	delete(s.handler, response.ID)
+	s.handlerLock.Unlock()
+
+	if response.Error != nil {
+		callback(nil, response.Error)
+	} else {
+		callback(response.Result, nil)
+	}
+}
+
+func (s *stream) setHandler(id uint64, ack chan *ackMessage) {
+	callback := func(b []byte, err error) {
+		select {
+		case ack <- &ackMessage{b, err}:
+		default:
+		}
+	}
+
+	s.handlerLock.Lock()
+	s.handler[id] = callback
+	s.handlerLock.Unlock()
+}
+
+// register sets the handlers of the calls and returns the codec to send them.
+// The handlers are set while the connection is up, if it drops afterwards
+// the handlers are failed by handleDisconnect.
+func (s *stream) register(ctx context.Context, ids []uint64, acks []chan *ackMessage) (Codec, error) {
+	for {
+		codec, err := s.getCodec(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.connLock.Lock()
+		if s.state == ConnStateConnected && s.codec == codec {
+			for i, id := range ids {
+				s.setHandler(id, acks[i])
+			}
+			s.connLock.Unlock()
+			return codec, nil
+		}
+		// the connection dropped, wait for the next one
+		s.connLock.Unlock()
+	}
+}
+
+func (s *stream) removeHandler(id uint64) {
+	s.handlerLock.Lock()
+	delete(s.handler, id)
+	s.handlerLock.Unlock()
+}
+
+// Call implements the transport interface
+func (s *stream) Call(method string, out interface{}, params ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	err := s.CallContext(ctx, method, out, params...)
+	if err == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
+}
+
+// CallContext implements the transport interface
+func (s *stream) CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	seq := s.incSeq()
+	request, err := newRequest(seq, method, params)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ack := make(chan *ackMessage, 1)
+	codec, err := s.register(ctx, []uint64{seq}, []chan *ackMessage{ack})
+	if err != nil {
+		return err
+	}
+
+	if err := codec.Write(ctx, raw); err != nil {
+		s.removeHandler(seq)
+		return err
+	}
+
+	var resp *ackMessage
+	select {
+	case resp = <-ack:
+	case <-ctx.Done():
+		// drop the handler, a late response for this id is discarded
+		s.removeHandler(seq)
+		return ctx.Err()
+	}
+
+	if resp.err != nil {
+		return resp.err
+	}
+	if err := json.Unmarshal(resp.buf, out); err != nil {
+		return err
+	}
+	return nil
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (s *stream) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	requests := make([]*codec.Request, len(b))
+	acks := make([]chan *ackMessage, len(b))
+
+	removeHandlers := func() {
+		for _, request := range requests {
+			if request != nil {
+				s.removeHandler(request.ID)
+			}
+		}
+	}
+
+	for i, elem := range b {
+		request, err := newRequest(s.incSeq(), elem.Method, elem.Params)
+		if err != nil {
+			return err
+		}
+		requests[i] = request
+	}
+	raw, err := json.Marshal(requests)
+	if err != nil {
+		return err
+	}
+
+	// the batch counts as a single request for the in-flight limit
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ids := make([]uint64, len(requests))
+	for i, request := range requests {
+		ids[i] = request.ID
+		acks[i] = make(chan *ackMessage, 1)
+	}
+	codec, err := s.register(ctx, ids, acks)
+	if err != nil {
+		return err
+	}
+	if err := codec.Write(ctx, raw); err != nil {
+		removeHandlers()
+		return err
+	}
+
+	for i := range b {
+		select {
+		case resp := <-acks[i]:
+			if resp.err != nil {
+				b[i].Error = resp.err
+			} else {
+				b[i].Error = decodeResult(resp.buf, b[i].Result)
+			}
+		case <-ctx.Done():
+			removeHandlers()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *stream) unsubscribe(sub *subscription) error {
+	s.subsLock.Lock()
+	id := sub.id
+	if _, ok := s.subs[id]; !ok {
+		s.subsLock.Unlock()
+		return fmt.Errorf("subscription %s not found", id)
+	}
+	delete(s.subs, id)
+	s.subsLock.Unlock()
+
+	sub.close()
+
+	var result bool
+	if err := s.Call("eth_unsubscribe", &result, id); err != nil {
+		return err
+	}
+	if !result {
+		return fmt.Errorf("failed to unsubscribe")
+	}
+	return nil
+}
+
+// subscribe starts a subscription on the server and returns its id
+func (s *stream) subscribe(method string, params interface{}) (string, error) {
+	var out string
+	if params == nil {
+		if err := s.Call("eth_subscribe", &out, method); err != nil {
+			return "", err
+		}
+	} else {
+		if err := s.Call("eth_subscribe", &out, method, params); err != nil {
+			return "", err
+		}
+	}
+	return out, nil
+}
+
+// Subscribe implements the PubSubTransport interface
+func (s *stream) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	id, err := s.subscribe(method, params)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := newSubscription(id, method, params, callback)
+	go sub.run()
+
+	s.subsLock.Lock()
+	s.addSubscription(id, sub)
+	s.subsLock.Unlock()
+
+	cancel := func() error {
+		return s.unsubscribe(sub)
+	}
+	return cancel, nil
+}
+
+// SetMaxConnsPerHost implements the transport interface. The stream uses a single
+// connection, it bounds the number of requests waiting for a response instead.
+// Zero or a negative count removes the limit.
+func (s *stream) SetMaxConnsPerHost(count int) {
+	s.inFlightLock.Lock()
+	defer s.inFlightLock.Unlock()
+
+	if count <= 0 {
+		s.inFlight = nil
+	} else {
+		s.inFlight = make(chan struct{}, count)
+	}
+}
+
+// acquire waits until the request can be sent without exceeding the in-flight limit.
+// The returned function releases the slot once the response arrives.
+func (s *stream) acquire(ctx context.Context) (func(), error) {
+	s.inFlightLock.Lock()
+	inFlight := s.inFlight
+	s.inFlightLock.Unlock()
+
+	if inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case inFlight <- struct{}{}:
+		return func() { <-inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closeCh:
+		return nil, ErrClosed
+	}
+}
+
+// Codec is the codec to write and read messages
+type Codec interface {
+	Read([]byte) ([]byte, error)
+	// Write sends the message before the deadline of the context (if any)
+	Write(context.Context, []byte) error
+	Close() error
+}
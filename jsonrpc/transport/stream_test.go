@@ -0,0 +1,348 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// mockCodec is an in-memory codec. Each request written is handed
+// to the handle function and its response (if any) is read back
+type mockCodec struct {
+	handle    func(req *codec.Request) *codec.Response
+	respCh    chan []byte
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func newMockCodec(handle func(req *codec.Request) *codec.Response) *mockCodec {
+	return &mockCodec{
+		handle:  handle,
+		respCh:  make(chan []byte, 16),
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (m *mockCodec) Read(b []byte) ([]byte, error) {
+	select {
+	case buf := <-m.respCh:
+		return append(b, buf...), nil
+	case <-m.closeCh:
+		return nil, fmt.Errorf("closed")
+	}
+}
+
+func (m *mockCodec) Write(ctx context.Context, b []byte) error {
+	if isBatch(b) {
+		var reqs []*codec.Request
+		if err := json.Unmarshal(b, &reqs); err != nil {
+			return err
+		}
+		resps := []*codec.Response{}
+		for _, req := range reqs {
+			if resp := m.handle(req); resp != nil {
+				resps = append(resps, resp)
+			}
+		}
+		return m.reply(resps)
+	}
+
+	var req codec.Request
+	if err := json.Unmarshal(b, &req); err != nil {
+		return err
+	}
+	if resp := m.handle(&req); resp != nil {
+		return m.reply(resp)
+	}
+	return nil
+}
+
+func (m *mockCodec) reply(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	m.respCh <- data
+	return nil
+}
+
+func (m *mockCodec) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+	return nil
+}
+
+func (m *mockCodec) dial() (Codec, error) {
+	return m, nil
+}
+
+func TestStream_CallContext(t *testing.T) {
+	c := newMockCodec(func(req *codec.Request) *codec.Response {
+		if req.Method == "eth_stuck" {
+			// never respond
+			return nil
+		}
+		return &codec.Response{ID: req.ID, Result: json.RawMessage(`"0x1"`)}
+	})
+
+	s, err := newStream(c.dial, nil)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	var out string
+	assert.NoError(t, s.CallContext(context.Background(), "eth_blockNumber", &out))
+	assert.Equal(t, "0x1", out)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, s.CallContext(ctx, "eth_stuck", &out))
+
+	// the handler of the abandoned call is dropped
+	s.handlerLock.Lock()
+	assert.Len(t, s.handler, 0)
+	s.handlerLock.Unlock()
+
+	// a cancelled context does not send the request
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, context.Canceled, s.CallContext(ctx, "eth_blockNumber", &out))
+}
+
+func TestStream_BatchCall(t *testing.T) {
+	c := newMockCodec(func(req *codec.Request) *codec.Response {
+		if req.Method == "eth_fail" {
+			return &codec.Response{ID: req.ID, Error: &codec.ErrorObject{Code: -32000, Message: "failed"}}
+		}
+		return &codec.Response{ID: req.ID, Result: req.Params}
+	})
+
+	s, err := newStream(c.dial, nil)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	var res0, res2 []string
+	b := []BatchElem{
+		{Method: "eth_echo", Params: []interface{}{"a"}, Result: &res0},
+		{Method: "eth_fail"},
+		{Method: "eth_echo", Params: []interface{}{"b"}, Result: &res2},
+	}
+	assert.NoError(t, s.BatchCallContext(context.Background(), b))
+
+	assert.NoError(t, b[0].Error)
+	assert.Equal(t, []string{"a"}, res0)
+	assert.Error(t, b[1].Error)
+	assert.NoError(t, b[2].Error)
+	assert.Equal(t, []string{"b"}, res2)
+}
+
+func TestStream_MaxInFlight(t *testing.T) {
+	var lock sync.Mutex
+	var methods []string
+
+	c := newMockCodec(func(req *codec.Request) *codec.Response {
+		lock.Lock()
+		methods = append(methods, req.Method)
+		lock.Unlock()
+
+		if req.Method == "eth_stuck" {
+			return nil
+		}
+		return &codec.Response{ID: req.ID, Result: json.RawMessage(`"0x1"`)}
+	})
+
+	s, err := newStream(c.dial, nil)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	s.SetMaxConnsPerHost(1)
+
+	stuckCtx, stuckCancel := context.WithCancel(context.Background())
+	doneCh := make(chan error)
+	go func() {
+		var out string
+		doneCh <- s.CallContext(stuckCtx, "eth_stuck", &out)
+	}()
+
+	// wait for the stuck request to be sent
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(methods) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// the second request waits for a free slot and it is not sent
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var out string
+	assert.Equal(t, context.DeadlineExceeded, s.CallContext(ctx, "eth_blockNumber", &out))
+
+	stuckCancel()
+	assert.Equal(t, context.Canceled, <-doneCh)
+
+	assert.NoError(t, s.CallContext(context.Background(), "eth_blockNumber", &out))
+	assert.Equal(t, []string{"eth_stuck", "eth_blockNumber"}, methods)
+}
+
+func TestStream_Reconnect(t *testing.T) {
+	var lock sync.Mutex
+	var codecs []*mockCodec
+
+	dial := func() (Codec, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		num := len(codecs)
+		c := newMockCodec(func(req *codec.Request) *codec.Response {
+			switch req.Method {
+			case "eth_subscribe":
+				// the server assigns a new id on every connection
+				return &codec.Response{ID: req.ID, Result: json.RawMessage(fmt.Sprintf(`"0x%d"`, num))}
+			case "eth_stuck":
+				return nil
+			}
+			return &codec.Response{ID: req.ID, Result: json.RawMessage(`"0x1"`)}
+		})
+		codecs = append(codecs, c)
+		return c, nil
+	}
+	codecAt := func(i int) *mockCodec {
+		lock.Lock()
+		defer lock.Unlock()
+		return codecs[i]
+	}
+
+	s, err := newStream(dial, &ReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	stateCh := make(chan ConnState, 10)
+	s.OnConnState(func(state ConnState) {
+		stateCh <- state
+	})
+
+	dataCh := make(chan []byte, 10)
+	_, err = s.Subscribe("newHeads", nil, func(b []byte) {
+		dataCh <- b
+	})
+	assert.NoError(t, err)
+
+	// the in-flight call fails when the connection drops
+	errCh := make(chan error)
+	go func() {
+		var out string
+		errCh <- s.CallContext(context.Background(), "eth_stuck", &out)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	codecAt(0).Close()
+
+	err = <-errCh
+	assert.True(t, errors.Is(err, ErrConnectionLost))
+
+	assert.Equal(t, ConnStateDisconnected, <-stateCh)
+	assert.Equal(t, ConnStateConnected, <-stateCh)
+
+	// the subscription is mapped to the id of the new connection
+	s.subsLock.Lock()
+	_, ok := s.subs["0x1"]
+	s.subsLock.Unlock()
+	assert.True(t, ok)
+
+	codecAt(1).reply(&codec.Request{
+		JsonRPC: "2.0",
+		Method:  "eth_subscription",
+		Params:  json.RawMessage(`{"subscription": "0x1", "result": "0x2"}`),
+	})
+	assert.Equal(t, []byte(`"0x2"`), <-dataCh)
+
+	var out string
+	assert.NoError(t, s.CallContext(context.Background(), "eth_blockNumber", &out))
+}
+
+func TestStream_ResubscribeRejected(t *testing.T) {
+	var lock sync.Mutex
+	var codecs []*mockCodec
+
+	dial := func() (Codec, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		num := len(codecs)
+		c := newMockCodec(func(req *codec.Request) *codec.Response {
+			var params []string
+			json.Unmarshal(req.Params, &params)
+
+			if num > 0 && params[0] == "logs" {
+				// the server does not accept the subscription anymore
+				return &codec.Response{ID: req.ID, Error: &codec.ErrorObject{Code: -32601, Message: "not supported"}}
+			}
+			return &codec.Response{ID: req.ID, Result: json.RawMessage(fmt.Sprintf(`"0x%d%s"`, num, params[0]))}
+		})
+		codecs = append(codecs, c)
+		return c, nil
+	}
+
+	s, err := newStream(dial, &ReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	stateCh := make(chan ConnState, 10)
+	s.OnConnState(func(state ConnState) {
+		stateCh <- state
+	})
+
+	_, err = s.Subscribe("logs", nil, func(b []byte) {})
+	assert.NoError(t, err)
+	_, err = s.Subscribe("newHeads", nil, func(b []byte) {})
+	assert.NoError(t, err)
+
+	s.subsLock.Lock()
+	logs := s.subs["0x0logs"]
+	s.subsLock.Unlock()
+
+	lock.Lock()
+	codecs[0].Close()
+	lock.Unlock()
+
+	assert.Equal(t, ConnStateDisconnected, <-stateCh)
+	assert.Equal(t, ConnStateConnected, <-stateCh)
+
+	// the rejected subscription is closed and the other one is started again
+	s.subsLock.Lock()
+	_, ok := s.subs["0x1newHeads"]
+	assert.True(t, ok)
+	assert.Len(t, s.subs, 1)
+	s.subsLock.Unlock()
+
+	select {
+	case <-logs.closeCh:
+	default:
+		t.Fatal("subscription not closed")
+	}
+}
+
+func TestStream_CloseOnDisconnect(t *testing.T) {
+	c := newMockCodec(func(req *codec.Request) *codec.Response {
+		return nil
+	})
+
+	s, err := newStream(c.dial, nil)
+	assert.NoError(t, err)
+
+	c.Close()
+
+	var out string
+	assert.Error(t, s.CallContext(context.Background(), "eth_blockNumber", &out))
+	assert.True(t, s.IsClosed())
+}
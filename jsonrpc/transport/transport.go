@@ -1,8 +1,12 @@
 package transport
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"strings"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
 )
 
 // Transport is an inteface for transport methods to send jsonrpc requests
@@ -10,6 +14,10 @@ type Transport interface {
 	// Call makes a jsonrpc request
 	Call(method string, out interface{}, params ...interface{}) error
 
+	// CallContext makes a jsonrpc request that honours the deadline
+	// and cancellation of the context
+	CallContext(ctx context.Context, method string, out interface{}, params ...interface{}) error
+
 	// SetMaxConnsPerHost sets the maximum number of connections that can be established with a host
 	SetMaxConnsPerHost(count int)
 
@@ -25,15 +33,101 @@ type PubSubTransport interface {
 	Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error)
 }
 
+// BatchElem is a single call in a batch request
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	// Result is the object the result of the call is decoded into.
+	// It is not decoded if nil.
+	Result interface{}
+	// Error is set if the call failed or the result could not be decoded
+	Error error
+}
+
+// BatchTransport is a transport that allows batch requests
+type BatchTransport interface {
+	// BatchCallContext sends all the calls in a single request. The error
+	// returned is only set if the whole batch failed
+	BatchCallContext(ctx context.Context, b []BatchElem) error
+}
+
+type headersKey struct{}
+
+// WithRequestHeaders returns a copy of the context with headers that are
+// added to the requests made with it. Only the http transports (HTTP and
+// NetHTTP) send them, the websocket and ipc transports ignore them.
+func WithRequestHeaders(ctx context.Context, headers map[string]string) context.Context {
+	if prev, ok := ctx.Value(headersKey{}).(map[string]string); ok {
+		merged := make(map[string]string, len(prev)+len(headers))
+		for k, v := range prev {
+			merged[k] = v
+		}
+		for k, v := range headers {
+			merged[k] = v
+		}
+		headers = merged
+	}
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// requestHeaders returns the headers of the request set in the context
+func requestHeaders(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
+
+func newRequest(id uint64, method string, params []interface{}) (*codec.Request, error) {
+	request := &codec.Request{
+		JsonRPC: "2.0",
+		ID:      id,
+		Method:  method,
+	}
+	if len(params) > 0 {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		request.Params = data
+	}
+	return request, nil
+}
+
+// decodeResult decodes the result of a response into out
+func decodeResult(result json.RawMessage, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result, out)
+}
+
 const (
 	wsPrefix  = "ws://"
 	wssPrefix = "wss://"
 )
 
+// Config is the configuration of the transport
+type Config struct {
+	// Headers are the headers included in the http requests
+	Headers map[string]string
+
+	// Reconnect is the reconnect configuration of the websocket and
+	// ipc transports. If nil, the transport is closed when the connection drops.
+	Reconnect *ReconnectConfig
+
+	// NetHTTP is the configuration of the net/http transport. If set, it is
+	// used for the http endpoints instead of the fasthttp transport.
+	NetHTTP *NetHTTPConfig
+}
+
 // NewTransport creates a new transport object
 func NewTransport(url string, headers map[string]string) (Transport, error) {
+	return NewTransportWithConfig(url, &Config{Headers: headers})
+}
+
+// NewTransportWithConfig creates a new transport object with the given configuration
+func NewTransportWithConfig(url string, config *Config) (Transport, error) {
 	if strings.HasPrefix(url, wsPrefix) || strings.HasPrefix(url, wssPrefix) {
-		t, err := newWebsocket(url, headers)
+		t, err := newWebsocket(url, config.Headers, config.Reconnect)
 		if err != nil {
 			return nil, err
 		}
@@ -41,11 +135,14 @@ func NewTransport(url string, headers map[string]string) (Transport, error) {
 	}
 	if _, err := os.Stat(url); err == nil {
 		// path exists, it could be an ipc path
-		t, err := newIPC(url)
+		t, err := newIPC(url, config.Reconnect)
 		if err != nil {
 			return nil, err
 		}
 		return t, nil
 	}
-	return newHTTP(url, headers), nil
+	if config.NetHTTP != nil {
+		return NewNetHTTP(url, config.Headers, config.NetHTTP), nil
+	}
+	return newHTTP(url, config.Headers), nil
 }
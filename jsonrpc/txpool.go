@@ -0,0 +1,200 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/umbracle/ethgo"
+)
+
+// TxPool is the txpool namespace (geth)
+type TxPool struct {
+	c   *Client
+	ctx context.Context
+}
+
+// TxPool returns the reference to the txpool namespace
+func (c *Client) TxPool() *TxPool {
+	return c.endpoints.p
+}
+
+// WithContext returns a copy of the txpool namespace whose calls are bound to ctx
+func (t *TxPool) WithContext(ctx context.Context) *TxPool {
+	t2 := *t
+	t2.ctx = ctx
+	return &t2
+}
+
+// TxPoolContent are the transactions in the pool by sender and nonce
+type TxPoolContent struct {
+	Pending map[ethgo.Address]map[uint64]*ethgo.Transaction
+	Queued  map[ethgo.Address]map[uint64]*ethgo.Transaction
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (t *TxPoolContent) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Pending map[ethgo.Address]map[string]*ethgo.Transaction `json:"pending"`
+		Queued  map[ethgo.Address]map[string]*ethgo.Transaction `json:"queued"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	if t.Pending, err = decodePoolAccounts(obj.Pending); err != nil {
+		return err
+	}
+	if t.Queued, err = decodePoolAccounts(obj.Queued); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TxPoolAccountContent are the transactions in the pool of an account by nonce
+type TxPoolAccountContent struct {
+	Pending map[uint64]*ethgo.Transaction
+	Queued  map[uint64]*ethgo.Transaction
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (t *TxPoolAccountContent) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Pending map[string]*ethgo.Transaction `json:"pending"`
+		Queued  map[string]*ethgo.Transaction `json:"queued"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	if t.Pending, err = decodePoolNonces(obj.Pending); err != nil {
+		return err
+	}
+	if t.Queued, err = decodePoolNonces(obj.Queued); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TxPoolInspect is the summary of the transactions in the pool by sender and nonce
+type TxPoolInspect struct {
+	Pending map[ethgo.Address]map[uint64]string
+	Queued  map[ethgo.Address]map[uint64]string
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (t *TxPoolInspect) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Pending map[ethgo.Address]map[string]string `json:"pending"`
+		Queued  map[ethgo.Address]map[string]string `json:"queued"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	decode := func(accounts map[ethgo.Address]map[string]string) (map[ethgo.Address]map[uint64]string, error) {
+		res := make(map[ethgo.Address]map[uint64]string, len(accounts))
+		for addr, txns := range accounts {
+			res[addr] = make(map[uint64]string, len(txns))
+			for key, summary := range txns {
+				nonce, err := strconv.ParseUint(key, 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				res[addr][nonce] = summary
+			}
+		}
+		return res, nil
+	}
+
+	var err error
+	if t.Pending, err = decode(obj.Pending); err != nil {
+		return err
+	}
+	if t.Queued, err = decode(obj.Queued); err != nil {
+		return err
+	}
+	return nil
+}
+
+// decodePoolAccounts decodes the nonces of the transactions of each account
+func decodePoolAccounts(accounts map[ethgo.Address]map[string]*ethgo.Transaction) (map[ethgo.Address]map[uint64]*ethgo.Transaction, error) {
+	res := make(map[ethgo.Address]map[uint64]*ethgo.Transaction, len(accounts))
+	for addr, txns := range accounts {
+		nonces, err := decodePoolNonces(txns)
+		if err != nil {
+			return nil, err
+		}
+		res[addr] = nonces
+	}
+	return res, nil
+}
+
+// decodePoolNonces decodes the keys of the transactions, the nonces are encoded as decimals
+func decodePoolNonces(txns map[string]*ethgo.Transaction) (map[uint64]*ethgo.Transaction, error) {
+	res := make(map[uint64]*ethgo.Transaction, len(txns))
+	for key, txn := range txns {
+		nonce, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		res[nonce] = txn
+	}
+	return res, nil
+}
+
+// TxPoolStatus is the number of transactions in the pool
+type TxPoolStatus struct {
+	Pending uint64
+	Queued  uint64
+}
+
+// UnmarshalJSON implements the json unmarshal interface
+func (t *TxPoolStatus) UnmarshalJSON(buf []byte) error {
+	var obj struct {
+		Pending string `json:"pending"`
+		Queued  string `json:"queued"`
+	}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return err
+	}
+
+	var err error
+	if t.Pending, err = parseOptionalUint64(obj.Pending); err != nil {
+		return err
+	}
+	if t.Queued, err = parseOptionalUint64(obj.Queued); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Content returns the pending and queued transactions in the pool
+func (t *TxPool) Content() (*TxPoolContent, error) {
+	var out *TxPoolContent
+	err := t.c.CallContext(t.ctx, "txpool_content", &out)
+	return out, err
+}
+
+// ContentFrom returns the pending and queued transactions in the pool sent by the account
+func (t *TxPool) ContentFrom(addr ethgo.Address) (*TxPoolAccountContent, error) {
+	var out *TxPoolAccountContent
+	err := t.c.CallContext(t.ctx, "txpool_contentFrom", &out, addr)
+	return out, err
+}
+
+// Inspect returns a text summary of the pending and queued transactions in the pool
+func (t *TxPool) Inspect() (*TxPoolInspect, error) {
+	var out *TxPoolInspect
+	err := t.c.CallContext(t.ctx, "txpool_inspect", &out)
+	return out, err
+}
+
+// Status returns the number of pending and queued transactions in the pool
+func (t *TxPool) Status() (*TxPoolStatus, error) {
+	var out *TxPoolStatus
+	err := t.c.CallContext(t.ctx, "txpool_status", &out)
+	return out, err
+}
@@ -0,0 +1,84 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/transport/mock"
+)
+
+const txPoolTxn = `{
+	"blockHash": null,
+	"blockNumber": null,
+	"from": "0x0000000000000000000000000000000000000001",
+	"gas": "0x5208",
+	"gasPrice": "0x3b9aca00",
+	"hash": "0x0100000000000000000000000000000000000000000000000000000000000000",
+	"input": "0x",
+	"nonce": "0xa",
+	"to": "0x0000000000000000000000000000000000000002",
+	"transactionIndex": null,
+	"value": "0x1",
+	"type": "0x0",
+	"v": "0x1b",
+	"r": "0x1",
+	"s": "0x1"
+}`
+
+func TestTxPoolContent_UnmarshalJSON(t *testing.T) {
+	raw := `{
+		"pending": {
+			"0x0000000000000000000000000000000000000001": {
+				"10": ` + txPoolTxn + `
+			}
+		},
+		"queued": {}
+	}`
+
+	var content *TxPoolContent
+	assert.NoError(t, json.Unmarshal([]byte(raw), &content))
+	assert.Len(t, content.Queued, 0)
+
+	txn := content.Pending[ethgo.Address{19: 0x1}][10]
+	assert.NotNil(t, txn)
+	assert.Equal(t, uint64(10), txn.Nonce)
+	assert.Equal(t, ethgo.Address{19: 0x2}, *txn.To)
+	assert.Equal(t, big.NewInt(1), txn.Value)
+
+	var invalid *TxPoolContent
+	assert.Error(t, json.Unmarshal([]byte(`{"pending":{"0x0000000000000000000000000000000000000001":{"0xa":{}}}}`), &invalid))
+}
+
+func TestTxPool_Calls(t *testing.T) {
+	m := mock.New()
+	c := NewClientWithTransport(m)
+
+	addr := ethgo.Address{19: 0x1}
+	m.On("txpool_status").Return(map[string]string{"pending": "0x2", "queued": "0x1"})
+	m.On("txpool_inspect").Return(map[string]interface{}{
+		"pending": map[string]interface{}{
+			addr.String(): map[string]string{
+				"10": "0x0000000000000000000000000000000000000002: 1 wei + 21000 gas × 1000000000 wei",
+			},
+		},
+	})
+	m.On("txpool_contentFrom", addr).Return(json.RawMessage(`{"pending":{},"queued":{"11":` + txPoolTxn + `}}`))
+
+	status, err := c.TxPool().Status()
+	assert.NoError(t, err)
+	assert.Equal(t, &TxPoolStatus{Pending: 2, Queued: 1}, status)
+
+	inspect, err := c.TxPool().Inspect()
+	assert.NoError(t, err)
+	assert.Contains(t, inspect.Pending[addr][10], "21000 gas")
+
+	content, err := c.TxPool().ContentFrom(addr)
+	assert.NoError(t, err)
+	assert.Len(t, content.Pending, 0)
+	assert.Equal(t, uint64(10), content.Queued[11].Nonce)
+
+	assert.True(t, m.AssertExpectations(t))
+}
@@ -44,3 +44,31 @@ func parseHexBytes(str string) ([]byte, error) {
 	}
 	return buf, nil
 }
+
+func parseHexBytesList(strs []string) ([][]byte, error) {
+	res := make([][]byte, len(strs))
+	for indx, str := range strs {
+		buf, err := parseHexBytes(str)
+		if err != nil {
+			return nil, err
+		}
+		res[indx] = buf
+	}
+	return res, nil
+}
+
+// parseOptionalUint64 parses an hex or decimal number that may be empty
+func parseOptionalUint64(str string) (uint64, error) {
+	if str == "" {
+		return 0, nil
+	}
+	return parseUint64orHex(str)
+}
+
+// parseOptionalHexBytes parses hex bytes that may be empty
+func parseOptionalHexBytes(str string) ([]byte, error) {
+	if str == "" {
+		return nil, nil
+	}
+	return parseHexBytes(str)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+// Recorder receives the measurements of the jsonrpc requests
+type Recorder interface {
+	// RequestStarted is called before a request is sent
+	RequestStarted(method string)
+
+	// RequestFinished is called once the request finishes. Use ErrorCode
+	// to classify the error.
+	RequestFinished(method string, duration time.Duration, err error)
+
+	// Reconnected is called every time a stream transport (websocket
+	// or ipc) reconnects after the connection dropped
+	Reconnected()
+}
+
+// ErrorCode returns the label of the error of a request. It is empty if there
+// is no error, the jsonrpc code for the jsonrpc errors and 'transport' for any
+// other error (i.e. a timeout or a closed connection).
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var obj *codec.ErrorObject
+	if errors.As(err, &obj) {
+		return strconv.Itoa(obj.Code)
+	}
+	return "transport"
+}
+
+// Attribute is a key value pair attached to a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates a new attribute
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer creates the spans of the operations. The spans started with the
+// context of another span are its children.
+type Tracer interface {
+	// Start starts a span and returns a context that carries it
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation being traced
+type Span interface {
+	// SetAttributes adds attributes to the span
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError marks the span as failed
+	RecordError(err error)
+
+	// End finishes the span
+	End()
+}
+
+// StartSpan starts a span with the tracer. It returns a no-op span if the tracer is nil.
+func StartSpan(ctx context.Context, tracer Tracer, name string, attrs ...Attribute) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name, attrs...)
+}
+
+// EndSpan records the error, if any, and ends the span
+func EndSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
@@ -0,0 +1,91 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/umbracle/ethgo/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer in the provider
+const instrumentationName = "github.com/umbracle/ethgo"
+
+// Tracer is a metrics.Tracer that creates the spans with an OpenTelemetry
+// tracer. The spans started with a context that carries a span of the
+// application are its children, so they join the traces of the application.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ metrics.Tracer = &Tracer{}
+
+// NewTracer creates a tracer with the tracer provider. If the provider
+// is nil, the global one of OpenTelemetry is used.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// Start implements the metrics.Tracer interface
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...metrics.Attribute) (context.Context, metrics.Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithAttributes(convertAttrs(attrs)...))
+	return ctx, &span{span: s}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (s *span) SetAttributes(attrs ...metrics.Attribute) {
+	s.span.SetAttributes(convertAttrs(attrs)...)
+}
+
+func (s *span) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+func convertAttrs(attrs []metrics.Attribute) []attribute.KeyValue {
+	res := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		res = append(res, convertAttr(attr))
+	}
+	return res
+}
+
+func convertAttr(attr metrics.Attribute) attribute.KeyValue {
+	switch v := attr.Value.(type) {
+	case string:
+		return attribute.String(attr.Key, v)
+	case bool:
+		return attribute.Bool(attr.Key, v)
+	case int:
+		return attribute.Int(attr.Key, v)
+	case int64:
+		return attribute.Int64(attr.Key, v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return attribute.String(attr.Key, fmt.Sprint(v))
+		}
+		return attribute.Int64(attr.Key, int64(v))
+	case float64:
+		return attribute.Float64(attr.Key, v)
+	case []string:
+		return attribute.StringSlice(attr.Key, v)
+	case fmt.Stringer:
+		return attribute.String(attr.Key, v.String())
+	default:
+		return attribute.String(attr.Key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,50 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	// the span of the application is the parent of the spans of the tracer
+	ctx, parent := provider.Tracer("app").Start(context.Background(), "app")
+
+	tracer := NewTracer(provider)
+	ctx, span := tracer.Start(ctx, "tracker.syncBatch", metrics.Attr("from", uint64(1)), metrics.Attr("to", 10))
+	_, child := tracer.Start(ctx, "jsonrpc eth_getLogs")
+	child.SetAttributes(metrics.Attr("block.hash", ethgo.Hash{0x1}))
+	child.RecordError(fmt.Errorf("failed"))
+	child.End()
+	metrics.EndSpan(span, nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	c, s, p := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "jsonrpc eth_getLogs", c.Name())
+	assert.Equal(t, s.SpanContext().SpanID(), c.Parent().SpanID())
+	assert.Equal(t, codes.Error, c.Status().Code)
+	assert.Equal(t, "failed", c.Status().Description)
+	assert.Len(t, c.Events(), 1)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("block.hash", ethgo.Hash{0x1}.String())}, c.Attributes())
+
+	assert.Equal(t, "tracker.syncBatch", s.Name())
+	assert.Equal(t, p.SpanContext().SpanID(), s.Parent().SpanID())
+	assert.Equal(t, p.SpanContext().TraceID(), s.SpanContext().TraceID())
+	assert.Equal(t, p.SpanContext().TraceID(), c.SpanContext().TraceID())
+	assert.Equal(t, codes.Unset, s.Status().Code)
+	assert.Equal(t, []attribute.KeyValue{attribute.Int64("from", 1), attribute.Int("to", 10)}, s.Attributes())
+}
@@ -0,0 +1,121 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/umbracle/ethgo/metrics"
+)
+
+// Config is the configuration of the recorder
+type Config struct {
+	// Namespace is the prefix of the metric names. It defaults to 'ethgo'.
+	Namespace string
+
+	// Buckets are the upper bounds of the latency histogram in seconds.
+	// It defaults to prometheus.DefBuckets.
+	Buckets []float64
+
+	// ConstLabels are added to all the metrics (i.e. the endpoint)
+	ConstLabels prometheus.Labels
+}
+
+// Recorder is a metrics.Recorder that keeps the measurements in Prometheus
+// metrics. It is a prometheus.Collector to be registered in any registerer:
+//
+//	recorder := New(nil)
+//	registry.MustRegister(recorder)
+type Recorder struct {
+	duration   *prometheus.HistogramVec
+	errors     *prometheus.CounterVec
+	inFlight   *prometheus.GaugeVec
+	reconnects prometheus.Counter
+}
+
+var (
+	_ metrics.Recorder     = &Recorder{}
+	_ prometheus.Collector = &Recorder{}
+)
+
+// New creates a new Prometheus recorder
+func New(config *Config) *Recorder {
+	if config == nil {
+		config = &Config{}
+	}
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = "ethgo"
+	}
+	buckets := config.Buckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	return &Recorder{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "request_duration_seconds",
+			Help:        "Latency of the jsonrpc requests.",
+			Buckets:     buckets,
+			ConstLabels: config.ConstLabels,
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "request_errors_total",
+			Help:        "Failed jsonrpc requests by error code.",
+			ConstLabels: config.ConstLabels,
+		}, []string{"method", "code"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "requests_in_flight",
+			Help:        "Jsonrpc requests waiting for a response.",
+			ConstLabels: config.ConstLabels,
+		}, []string{"method"}),
+		reconnects: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "jsonrpc",
+			Name:        "reconnects_total",
+			Help:        "Reconnections of the websocket and ipc transports.",
+			ConstLabels: config.ConstLabels,
+		}),
+	}
+}
+
+// RequestStarted implements the metrics.Recorder interface
+func (r *Recorder) RequestStarted(method string) {
+	r.inFlight.WithLabelValues(method).Inc()
+}
+
+// RequestFinished implements the metrics.Recorder interface
+func (r *Recorder) RequestFinished(method string, duration time.Duration, err error) {
+	r.inFlight.WithLabelValues(method).Dec()
+	r.duration.WithLabelValues(method).Observe(duration.Seconds())
+
+	if code := metrics.ErrorCode(err); code != "" {
+		r.errors.WithLabelValues(method, code).Inc()
+	}
+}
+
+// Reconnected implements the metrics.Recorder interface
+func (r *Recorder) Reconnected() {
+	r.reconnects.Inc()
+}
+
+// Describe implements the prometheus.Collector interface
+func (r *Recorder) Describe(ch chan<- *prometheus.Desc) {
+	r.duration.Describe(ch)
+	r.errors.Describe(ch)
+	r.inFlight.Describe(ch)
+	r.reconnects.Describe(ch)
+}
+
+// Collect implements the prometheus.Collector interface
+func (r *Recorder) Collect(ch chan<- prometheus.Metric) {
+	r.duration.Collect(ch)
+	r.errors.Collect(ch)
+	r.inFlight.Collect(ch)
+	r.reconnects.Collect(ch)
+}
@@ -0,0 +1,53 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo/jsonrpc/codec"
+)
+
+func TestRecorder(t *testing.T) {
+	r := New(&Config{Namespace: "test", Buckets: []float64{0.1, 1}})
+
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(r))
+
+	// the recorder can only be registered once
+	assert.Error(t, registry.Register(r))
+
+	r.RequestStarted("eth_call")
+	r.RequestFinished("eth_call", 50*time.Millisecond, nil)
+	r.RequestStarted("eth_call")
+	r.RequestFinished("eth_call", 500*time.Millisecond, &codec.ErrorObject{Code: 3, Message: "execution reverted"})
+	r.RequestStarted("eth_call")
+	r.RequestFinished("eth_call", 2*time.Second, fmt.Errorf("timeout"))
+	r.RequestStarted("eth_blockNumber")
+	r.Reconnected()
+
+	expected := `# HELP test_jsonrpc_request_duration_seconds Latency of the jsonrpc requests.
+# TYPE test_jsonrpc_request_duration_seconds histogram
+test_jsonrpc_request_duration_seconds_bucket{method="eth_call",le="0.1"} 1
+test_jsonrpc_request_duration_seconds_bucket{method="eth_call",le="1"} 2
+test_jsonrpc_request_duration_seconds_bucket{method="eth_call",le="+Inf"} 3
+test_jsonrpc_request_duration_seconds_sum{method="eth_call"} 2.55
+test_jsonrpc_request_duration_seconds_count{method="eth_call"} 3
+# HELP test_jsonrpc_request_errors_total Failed jsonrpc requests by error code.
+# TYPE test_jsonrpc_request_errors_total counter
+test_jsonrpc_request_errors_total{code="3",method="eth_call"} 1
+test_jsonrpc_request_errors_total{code="transport",method="eth_call"} 1
+# HELP test_jsonrpc_requests_in_flight Jsonrpc requests waiting for a response.
+# TYPE test_jsonrpc_requests_in_flight gauge
+test_jsonrpc_requests_in_flight{method="eth_blockNumber"} 1
+test_jsonrpc_requests_in_flight{method="eth_call"} 0
+# HELP test_jsonrpc_reconnects_total Reconnections of the websocket and ipc transports.
+# TYPE test_jsonrpc_reconnects_total counter
+test_jsonrpc_reconnects_total 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
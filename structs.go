@@ -243,6 +243,34 @@ type CallMsg struct {
 	Value    *big.Int
 }
 
+// OverrideAccount is the set of fields of an account that are replaced
+// during an eth_call. A nil field is not overridden. A non-nil empty Code
+// removes the code of the account. State replaces the whole storage of
+// the account while StateDiff only replaces the given slots, only one
+// of them can be set.
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[Hash]Hash
+	StateDiff map[Hash]Hash
+}
+
+// StateOverride is the set of accounts to override during an eth_call
+type StateOverride map[Address]*OverrideAccount
+
+// BlockOverrides is the set of fields of the block header that are
+// replaced during an eth_call. A nil field is not overridden.
+type BlockOverrides struct {
+	Number     *big.Int
+	Difficulty *big.Int
+	Time       *uint64
+	GasLimit   *uint64
+	Coinbase   *Address // feeRecipient
+	Random     *Hash    // prevRandao
+	BaseFee    *big.Int // baseFeePerGas
+}
+
 type LogFilter struct {
 	Address   []Address
 	Topics    [][]*Hash
@@ -158,6 +158,15 @@ func (t *Transaction) marshalJSON(a *fastjson.Arena) *fastjson.Value {
 	return o
 }
 
+// MarshalJSON implements the Marshal interface.
+func (t *AccessList) MarshalJSON() ([]byte, error) {
+	a := defaultArena.Get()
+	v := t.marshalJSON(a)
+	res := v.MarshalTo(nil)
+	defaultArena.Put(a)
+	return res, nil
+}
+
 func (t *AccessList) marshalJSON(a *fastjson.Arena) *fastjson.Value {
 	arr := a.NewArray()
 	for indx, elem := range *t {
@@ -201,6 +210,83 @@ func (c *CallMsg) MarshalJSON() ([]byte, error) {
 	return res, nil
 }
 
+// MarshalJSON implements the Marshal interface.
+func (s StateOverride) MarshalJSON() ([]byte, error) {
+	a := defaultArena.Get()
+
+	o := a.NewObject()
+	for addr, account := range s {
+		if account == nil {
+			continue
+		}
+		if account.State != nil && account.StateDiff != nil {
+			defaultArena.Put(a)
+			return nil, fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr)
+		}
+		oo := a.NewObject()
+		if account.Nonce != nil {
+			oo.Set("nonce", a.NewString(fmt.Sprintf("0x%x", *account.Nonce)))
+		}
+		if account.Code != nil {
+			oo.Set("code", a.NewString("0x"+hex.EncodeToString(account.Code)))
+		}
+		if account.Balance != nil {
+			oo.Set("balance", a.NewString(fmt.Sprintf("0x%x", account.Balance)))
+		}
+		if account.State != nil {
+			oo.Set("state", marshalStorage(a, account.State))
+		}
+		if account.StateDiff != nil {
+			oo.Set("stateDiff", marshalStorage(a, account.StateDiff))
+		}
+		o.Set(addr.String(), oo)
+	}
+
+	res := o.MarshalTo(nil)
+	defaultArena.Put(a)
+	return res, nil
+}
+
+func marshalStorage(a *fastjson.Arena, storage map[Hash]Hash) *fastjson.Value {
+	o := a.NewObject()
+	for k, v := range storage {
+		o.Set(k.String(), a.NewString(v.String()))
+	}
+	return o
+}
+
+// MarshalJSON implements the Marshal interface.
+func (b *BlockOverrides) MarshalJSON() ([]byte, error) {
+	a := defaultArena.Get()
+
+	o := a.NewObject()
+	if b.Number != nil {
+		o.Set("number", a.NewString(fmt.Sprintf("0x%x", b.Number)))
+	}
+	if b.Difficulty != nil {
+		o.Set("difficulty", a.NewString(fmt.Sprintf("0x%x", b.Difficulty)))
+	}
+	if b.Time != nil {
+		o.Set("time", a.NewString(fmt.Sprintf("0x%x", *b.Time)))
+	}
+	if b.GasLimit != nil {
+		o.Set("gasLimit", a.NewString(fmt.Sprintf("0x%x", *b.GasLimit)))
+	}
+	if b.Coinbase != nil {
+		o.Set("feeRecipient", a.NewString(b.Coinbase.String()))
+	}
+	if b.Random != nil {
+		o.Set("prevRandao", a.NewString(b.Random.String()))
+	}
+	if b.BaseFee != nil {
+		o.Set("baseFeePerGas", a.NewString(fmt.Sprintf("0x%x", b.BaseFee)))
+	}
+
+	res := o.MarshalTo(nil)
+	defaultArena.Put(a)
+	return res, nil
+}
+
 // MarshalJSON implements the Marshal interface.
 func (l *LogFilter) MarshalJSON() ([]byte, error) {
 	a := defaultArena.Get()
@@ -2,6 +2,7 @@ package ethgo
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -94,3 +95,48 @@ func TestLogFilter_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestStateOverride_MarshalJSON(t *testing.T) {
+	nonce := uint64(1)
+	override := StateOverride{
+		HexToAddress("0x1"): {
+			Nonce:   &nonce,
+			Code:    []byte{},
+			Balance: big.NewInt(16),
+			StateDiff: map[Hash]Hash{
+				HexToHash("0x1"): HexToHash("0x2"),
+			},
+		},
+	}
+	data, err := json.Marshal(override)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"0x0000000000000000000000000000000000000001": {
+			"nonce": "0x1",
+			"code": "0x",
+			"balance": "0x10",
+			"stateDiff": {
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002"
+			}
+		}
+	}`, string(data))
+
+	gasLimit := uint64(100)
+	data, err = json.Marshal(&BlockOverrides{Number: big.NewInt(10), GasLimit: &gasLimit})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"number":"0xa","gasLimit":"0x64"}`, string(data))
+
+	// the names of the fields are the ones of geth
+	data, err = json.Marshal(&BlockOverrides{Coinbase: &Address{0x1}, Random: &Hash{0x2}, BaseFee: big.NewInt(1)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"feeRecipient": "0x0100000000000000000000000000000000000000",
+		"prevRandao": "0x0200000000000000000000000000000000000000000000000000000000000000",
+		"baseFeePerGas": "0x1"
+	}`, string(data))
+
+	// state and stateDiff cannot be set at the same time
+	override[HexToAddress("0x1")].State = map[Hash]Hash{}
+	_, err = json.Marshal(override)
+	assert.Error(t, err)
+}
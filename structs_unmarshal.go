@@ -235,6 +235,19 @@ func (t *Transaction) unmarshalJSON(v *fastjson.Value) error {
 	return nil
 }
 
+// UnmarshalJSON implements the unmarshal interface
+func (t *AccessList) UnmarshalJSON(buf []byte) error {
+	p := defaultPool.Get()
+	defer defaultPool.Put(p)
+
+	v, err := p.Parse(string(buf))
+	if err != nil {
+		return err
+	}
+	*t = (*t)[:0]
+	return t.unmarshalJSON(v)
+}
+
 func (t *AccessList) unmarshalJSON(v *fastjson.Value) error {
 	elems, err := v.Array()
 	if err != nil {
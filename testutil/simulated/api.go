@@ -0,0 +1,505 @@
+package simulated
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/server"
+)
+
+// executionErrorCode is the jsonrpc code of a reverted execution
+const executionErrorCode = 3
+
+// revertError is the error of a call whose execution reverted
+type revertError struct {
+	data []byte
+}
+
+func (r *revertError) Error() string {
+	return ErrExecutionReverted.Error()
+}
+
+func (r *revertError) ErrorCode() int {
+	return executionErrorCode
+}
+
+func (r *revertError) ErrorData() interface{} {
+	return hexBytes(r.data)
+}
+
+// executionError returns the error of a failed execution
+func executionError(res *ExecutionResult) error {
+	if errors.Is(res.Err, ErrExecutionReverted) {
+		return &revertError{data: res.ReturnData}
+	}
+	return res.Err
+}
+
+// stateAt returns the state and the context of the block. The
+// state is not a copy and it must not be modified.
+func (b *Backend) stateAt(arg *blockArg) (*State, *BlockContext, error) {
+	c := b.chain
+	if arg == nil {
+		arg = &blockArg{num: ethgo.Latest}
+	}
+	if arg.hash == nil && arg.num == ethgo.Pending {
+		return c.pending.state, b.blockContext(c, nil), nil
+	}
+	block, err := b.blockAt(arg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block == nil {
+		return nil, nil, fmt.Errorf("header not found")
+	}
+	return c.states[block.Number], b.blockContext(c, block), nil
+}
+
+// blockAt returns the block or nil if it does not exist
+func (b *Backend) blockAt(arg *blockArg) (*ethgo.Block, error) {
+	c := b.chain
+	if arg.hash != nil {
+		for _, block := range c.blocks {
+			if block.Hash == *arg.hash {
+				return block, nil
+			}
+		}
+		return nil, nil
+	}
+
+	switch arg.num {
+	case ethgo.Latest, ethgo.Pending:
+		return c.head(), nil
+	case ethgo.Earliest:
+		return c.blocks[0], nil
+	}
+	if num := uint64(arg.num); num < uint64(len(c.blocks)) {
+		return c.blocks[num], nil
+	}
+	return nil, nil
+}
+
+// ethAPI is the eth namespace of the backend
+type ethAPI struct {
+	b *Backend
+}
+
+func (e *ethAPI) ChainId() *hexBig {
+	return newHexBig(e.b.config.ChainID)
+}
+
+func (e *ethAPI) BlockNumber() hexUint {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	return hexUint(e.b.chain.head().Number)
+}
+
+func (e *ethAPI) GasPrice() hexUint {
+	return hexUint(e.b.config.GasPrice)
+}
+
+func (e *ethAPI) MaxPriorityFeePerGas() hexUint {
+	return 0
+}
+
+func (e *ethAPI) Syncing() bool {
+	return false
+}
+
+func (e *ethAPI) Accounts() []ethgo.Address {
+	return append([]ethgo.Address{}, e.b.config.Accounts...)
+}
+
+func (e *ethAPI) GetBalance(addr ethgo.Address, block *blockArg) (*hexBig, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	state, _, err := e.b.stateAt(block)
+	if err != nil {
+		return nil, err
+	}
+	return newHexBig(state.GetBalance(addr)), nil
+}
+
+func (e *ethAPI) GetTransactionCount(addr ethgo.Address, block *blockArg) (hexUint, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	state, _, err := e.b.stateAt(block)
+	if err != nil {
+		return 0, err
+	}
+	return hexUint(state.GetNonce(addr)), nil
+}
+
+func (e *ethAPI) GetCode(addr ethgo.Address, block *blockArg) (hexBytes, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	state, _, err := e.b.stateAt(block)
+	if err != nil {
+		return nil, err
+	}
+	return hexBytes(state.GetCode(addr)), nil
+}
+
+func (e *ethAPI) GetStorageAt(addr ethgo.Address, slot hexBig, block *blockArg) (ethgo.Hash, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	state, _, err := e.b.stateAt(block)
+	if err != nil {
+		return ethgo.Hash{}, err
+	}
+	key := ethgo.BytesToHash(slot.toInt().Bytes())
+	return state.GetState(addr, key), nil
+}
+
+// stateOverrideArg is the state override of eth_call
+type stateOverrideArg map[ethgo.Address]*overrideAccountArg
+
+func (e *ethAPI) Call(args callArgs, block *blockArg, overrides *stateOverrideArg, blockOverrides *blockOverridesArg) (hexBytes, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	state, ctx, err := e.b.stateAt(block)
+	if err != nil {
+		return nil, err
+	}
+	state = state.Copy()
+
+	if overrides != nil {
+		for addr, acct := range *overrides {
+			if acct.State != nil && acct.StateDiff != nil {
+				return nil, fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr)
+			}
+			if acct.Nonce != nil {
+				state.SetNonce(addr, uint64(*acct.Nonce))
+			}
+			if acct.Code != nil {
+				state.SetCode(addr, *acct.Code)
+			}
+			if acct.Balance != nil {
+				state.SetBalance(addr, acct.Balance.toInt())
+			}
+			if acct.State != nil {
+				for key := range state.account(addr).Storage {
+					state.SetState(addr, key, ethgo.Hash{})
+				}
+				for key, value := range acct.State {
+					state.SetState(addr, key, value)
+				}
+			}
+			for key, value := range acct.StateDiff {
+				state.SetState(addr, key, value)
+			}
+		}
+	}
+	if blockOverrides != nil {
+		if blockOverrides.Number != nil {
+			ctx.Number = blockOverrides.Number.toInt().Uint64()
+		}
+		if blockOverrides.Difficulty != nil {
+			ctx.Difficulty = blockOverrides.Difficulty.toInt()
+		}
+		if blockOverrides.Time != nil {
+			ctx.Timestamp = uint64(*blockOverrides.Time)
+		}
+		if blockOverrides.GasLimit != nil {
+			ctx.GasLimit = uint64(*blockOverrides.GasLimit)
+		}
+		if blockOverrides.Coinbase != nil {
+			ctx.Coinbase = *blockOverrides.Coinbase
+		}
+		if blockOverrides.BaseFee != nil {
+			ctx.BaseFee = blockOverrides.BaseFee.toInt()
+		}
+	}
+
+	gas := ctx.GasLimit
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	}
+	res, _, err := e.b.call(state, ctx, e.message(&args), gas)
+	if err != nil {
+		return nil, err
+	}
+	if res.Err != nil {
+		return nil, executionError(res)
+	}
+	return hexBytes(res.ReturnData), nil
+}
+
+func (e *ethAPI) EstimateGas(args callArgs, block *blockArg) (hexUint, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	if block == nil {
+		block = &blockArg{num: ethgo.Pending}
+	}
+	state, ctx, err := e.b.stateAt(block)
+	if err != nil {
+		return 0, err
+	}
+	gas, err := e.b.estimateGas(state.Copy(), ctx, e.message(&args))
+	if err != nil {
+		return 0, err
+	}
+	return hexUint(gas), nil
+}
+
+func (e *ethAPI) message(args *callArgs) *Message {
+	return &Message{
+		From:       args.from(),
+		To:         args.To,
+		Value:      args.Value.toInt(),
+		Data:       args.data(),
+		GasPrice:   args.gasPrice(),
+		AccessList: args.AccessList,
+	}
+}
+
+func (e *ethAPI) SendRawTransaction(data hexBytes) (ethgo.Hash, error) {
+	txn := new(ethgo.Transaction)
+	if err := txn.UnmarshalRLP(data); err != nil {
+		return ethgo.Hash{}, fmt.Errorf("failed to decode transaction: %v", err)
+	}
+	return e.b.SendTransaction(txn)
+}
+
+func (e *ethAPI) SendTransaction(args callArgs) (ethgo.Hash, error) {
+	txn := &ethgo.Transaction{
+		From:     args.from(),
+		To:       args.To,
+		Input:    args.data(),
+		Value:    args.Value.toInt(),
+		GasPrice: args.gasPrice().Uint64(),
+	}
+	if txn.GasPrice == 0 {
+		txn.GasPrice = e.b.config.GasPrice
+	}
+
+	e.b.lock.Lock()
+	pending := e.b.chain.pending
+	if args.Nonce != nil {
+		txn.Nonce = uint64(*args.Nonce)
+	} else {
+		txn.Nonce = pending.state.GetNonce(txn.From)
+	}
+	if args.Gas != nil {
+		txn.Gas = uint64(*args.Gas)
+	} else {
+		gas, err := e.b.estimateGas(pending.state, e.b.blockContext(e.b.chain, nil), e.message(&args))
+		if err != nil {
+			e.b.lock.Unlock()
+			return ethgo.Hash{}, err
+		}
+		txn.Gas = gas
+	}
+	e.b.lock.Unlock()
+
+	return e.b.sendUnsignedTransaction(txn)
+}
+
+func (e *ethAPI) GetTransactionByHash(hash ethgo.Hash) *rpcTransaction {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	if txn, ok := e.b.chain.txns[hash]; ok {
+		return newRPCTransaction(txn, true)
+	}
+	for _, txn := range e.b.chain.pending.txns {
+		if txn.Hash == hash {
+			return newRPCTransaction(txn, false)
+		}
+	}
+	return nil
+}
+
+func (e *ethAPI) GetTransactionReceipt(hash ethgo.Hash) *rpcReceipt {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	receipt, ok := e.b.chain.receipts[hash]
+	if !ok {
+		return nil
+	}
+	return newRPCReceipt(receipt, e.b.chain.txns[hash])
+}
+
+func (e *ethAPI) GetBlockByNumber(block blockArg, full bool) (*rpcBlock, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	b, err := e.b.blockAt(&block)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return newRPCBlock(b, full), nil
+}
+
+func (e *ethAPI) GetBlockByHash(hash ethgo.Hash, full bool) (*rpcBlock, error) {
+	return e.GetBlockByNumber(blockArg{hash: &hash}, full)
+}
+
+func (e *ethAPI) GetBlockReceipts(block blockArg) ([]*rpcReceipt, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	b, err := e.b.blockAt(&block)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	res := []*rpcReceipt{}
+	for _, txn := range b.Transactions {
+		res = append(res, newRPCReceipt(e.b.chain.receipts[txn.Hash], txn))
+	}
+	return res, nil
+}
+
+func (e *ethAPI) GetLogs(filter filterArg) ([]*ethgo.Log, error) {
+	e.b.lock.Lock()
+	defer e.b.lock.Unlock()
+
+	c := e.b.chain
+
+	var blocks []*ethgo.Block
+	if filter.BlockHash != nil {
+		b, _ := e.b.blockAt(&blockArg{hash: filter.BlockHash})
+		if b == nil {
+			return nil, fmt.Errorf("unknown block")
+		}
+		blocks = []*ethgo.Block{b}
+	} else {
+		from, to := c.head().Number, c.head().Number
+		if filter.FromBlock != nil {
+			b, _ := e.b.blockAt(filter.FromBlock)
+			if b == nil {
+				return []*ethgo.Log{}, nil
+			}
+			from = b.Number
+		}
+		if filter.ToBlock != nil {
+			if b, _ := e.b.blockAt(filter.ToBlock); b != nil {
+				to = b.Number
+			}
+		}
+		if from > to {
+			return nil, fmt.Errorf("invalid block range params")
+		}
+		blocks = c.blocks[from : to+1]
+	}
+
+	res := []*ethgo.Log{}
+	for _, b := range blocks {
+		for _, txn := range b.Transactions {
+			for _, log := range c.receipts[txn.Hash].Logs {
+				if filter.match(log) {
+					res = append(res, log)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+func (e *ethAPI) NewHeads(sub *server.Subscription) error {
+	events, cancel := e.b.subscribe()
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case event := <-events:
+				if err := sub.Notify(newRPCBlock(event.block, false)); err != nil {
+					return
+				}
+			case <-sub.Closed():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *ethAPI) Logs(sub *server.Subscription, filter *filterArg) error {
+	if filter == nil {
+		filter = &filterArg{}
+	}
+	events, cancel := e.b.subscribe()
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case event := <-events:
+				for _, log := range event.logs {
+					if !filter.match(log) {
+						continue
+					}
+					if err := sub.Notify(log); err != nil {
+						return
+					}
+				}
+			case <-sub.Closed():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// netAPI is the net namespace of the backend
+type netAPI struct {
+	b *Backend
+}
+
+func (n *netAPI) Version() string {
+	return n.b.config.ChainID.String()
+}
+
+func (n *netAPI) Listening() bool {
+	return true
+}
+
+func (n *netAPI) PeerCount() hexUint {
+	return 0
+}
+
+// web3API is the web3 namespace of the backend
+type web3API struct{}
+
+func (w *web3API) ClientVersion() string {
+	return "ethgo/simulated"
+}
+
+func (w *web3API) Sha3(data hexBytes) hexBytes {
+	return ethgo.Keccak256(data)
+}
+
+// evmAPI is the evm namespace used by the development nodes
+// to control the chain (i.e. evm_snapshot or evm_mine)
+type evmAPI struct {
+	b *Backend
+}
+
+func (e *evmAPI) Snapshot() hexUint {
+	return hexUint(e.b.Snapshot())
+}
+
+func (e *evmAPI) Revert(id hexUint) bool {
+	return e.b.Revert(int(id)) == nil
+}
+
+func (e *evmAPI) Mine() string {
+	e.b.Commit()
+	return "0x0"
+}
+
+func (e *evmAPI) IncreaseTime(seconds uint64) (hexUint, error) {
+	if err := e.b.AdjustTime(time.Duration(seconds) * time.Second); err != nil {
+		return 0, err
+	}
+	return hexUint(seconds), nil
+}
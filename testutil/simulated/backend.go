@@ -0,0 +1,687 @@
+package simulated
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/jsonrpc/server"
+	"github.com/umbracle/ethgo/jsonrpc/transport"
+	"github.com/umbracle/ethgo/wallet"
+)
+
+// emptyUncleHash is the hash of an empty list of uncles
+var emptyUncleHash = ethgo.HexToHash("0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347")
+
+// gas costs of the transactions
+const (
+	txGas                  = 21000
+	txGasContractCreation  = 53000
+	txDataZeroGas          = 4
+	txDataNonZeroGas       = 16
+	initCodeWordGas        = 2
+	txAccessListAddressGas = 2400
+	txAccessListKeyGas     = 1900
+	defaultGasLimit        = 30000000
+	defaultChainID         = 1337
+	defaultGasPrice        = 1000000000
+	maxEstimateGasAttempts = 64
+)
+
+// Config is the configuration of the simulated backend
+type Config struct {
+	// ChainID is the chain id used to validate the signatures
+	ChainID *big.Int
+
+	// Alloc are the accounts of the genesis
+	Alloc map[ethgo.Address]*Account
+
+	// Accounts are the unlocked accounts that can send transactions
+	// with eth_sendTransaction without a signature
+	Accounts []ethgo.Address
+
+	// GasLimit is the gas limit of the blocks
+	GasLimit uint64
+
+	// GasPrice is the gas price returned by eth_gasPrice
+	GasPrice uint64
+
+	// Coinbase is the address that receives the fees
+	Coinbase ethgo.Address
+
+	// AutoMine mines a block with every transaction
+	AutoMine bool
+
+	// Executor executes the messages. The default one runs
+	// the code with the evm interpreter.
+	Executor Executor
+}
+
+// DefaultConfig returns the default configuration of the backend
+func DefaultConfig() *Config {
+	return &Config{
+		ChainID:  big.NewInt(defaultChainID),
+		Alloc:    map[ethgo.Address]*Account{},
+		GasLimit: defaultGasLimit,
+		GasPrice: defaultGasPrice,
+		Executor: evmExecutor{},
+	}
+}
+
+// pendingBlock are the transactions to include in the next block
+type pendingBlock struct {
+	state     *State
+	timestamp uint64
+	txns      []*ethgo.Transaction
+	receipts  []*ethgo.Receipt
+	gasUsed   uint64
+}
+
+// chain is the blockchain of the backend
+type chain struct {
+	blocks   []*ethgo.Block
+	states   []*State
+	txns     map[ethgo.Hash]*ethgo.Transaction
+	receipts map[ethgo.Hash]*ethgo.Receipt
+	pending  *pendingBlock
+}
+
+func (c *chain) copy() *chain {
+	cc := &chain{
+		blocks:   append([]*ethgo.Block{}, c.blocks...),
+		states:   append([]*State{}, c.states...),
+		txns:     map[ethgo.Hash]*ethgo.Transaction{},
+		receipts: map[ethgo.Hash]*ethgo.Receipt{},
+		pending: &pendingBlock{
+			state:     c.pending.state.Copy(),
+			timestamp: c.pending.timestamp,
+			txns:      append([]*ethgo.Transaction{}, c.pending.txns...),
+			receipts:  append([]*ethgo.Receipt{}, c.pending.receipts...),
+			gasUsed:   c.pending.gasUsed,
+		},
+	}
+	for k, v := range c.txns {
+		cc.txns[k] = v
+	}
+	for k, v := range c.receipts {
+		cc.receipts[k] = v
+	}
+	return cc
+}
+
+func (c *chain) head() *ethgo.Block {
+	return c.blocks[len(c.blocks)-1]
+}
+
+// chainEvent is a new block with its logs
+type chainEvent struct {
+	block *ethgo.Block
+	logs  []*ethgo.Log
+}
+
+type subscriber struct {
+	ch      chan *chainEvent
+	closeCh chan struct{}
+}
+
+// Backend is an in-process blockchain that implements the jsonrpc transport.
+// It keeps the accounts in memory, accepts signed transactions and mines
+// them on demand.
+type Backend struct {
+	transport.Transport
+
+	config *Config
+	server *server.Server
+
+	lock       sync.Mutex
+	chain      *chain
+	snapshots  map[int]*chain
+	snapshotID int
+
+	subsLock sync.Mutex
+	subs     map[*subscriber]struct{}
+}
+
+// NewBackend creates a simulated backend with a genesis block
+func NewBackend(config *Config) (*Backend, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.ChainID == nil {
+		config.ChainID = big.NewInt(defaultChainID)
+	}
+	if config.GasLimit == 0 {
+		config.GasLimit = defaultGasLimit
+	}
+	if config.Executor == nil {
+		config.Executor = evmExecutor{}
+	}
+
+	genesis := &ethgo.Block{
+		Number:     0,
+		Sha3Uncles: emptyUncleHash,
+		Miner:      config.Coinbase,
+		Difficulty: new(big.Int),
+		GasLimit:   config.GasLimit,
+		Timestamp:  uint64(time.Now().Unix()),
+	}
+	genesis.Hash = blockHash(genesis)
+
+	state := NewState(config.Alloc)
+
+	b := &Backend{
+		config: config,
+		server: server.NewServer(),
+		chain: &chain{
+			blocks:   []*ethgo.Block{genesis},
+			states:   []*State{state},
+			txns:     map[ethgo.Hash]*ethgo.Transaction{},
+			receipts: map[ethgo.Hash]*ethgo.Receipt{},
+		},
+		snapshots: map[int]*chain{},
+		subs:      map[*subscriber]struct{}{},
+	}
+	b.chain.pending = b.newPendingBlock(b.chain)
+
+	services := map[string]interface{}{
+		"eth":  &ethAPI{b: b},
+		"net":  &netAPI{b: b},
+		"web3": &web3API{},
+		"evm":  &evmAPI{b: b},
+	}
+	for namespace, service := range services {
+		if err := b.server.Register(namespace, service); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := b.server.DialInProc()
+	if err != nil {
+		return nil, err
+	}
+	b.Transport = t
+	return b, nil
+}
+
+// Close implements the transport interface
+func (b *Backend) Close() error {
+	err := b.Transport.Close()
+	b.server.Stop()
+	return err
+}
+
+// BatchCallContext implements the BatchTransport interface
+func (b *Backend) BatchCallContext(ctx context.Context, batch []transport.BatchElem) error {
+	return b.Transport.(transport.BatchTransport).BatchCallContext(ctx, batch)
+}
+
+// Subscribe implements the PubSubTransport interface
+func (b *Backend) Subscribe(method string, params interface{}, callback func(b []byte)) (func() error, error) {
+	return b.Transport.(transport.PubSubTransport).Subscribe(method, params, callback)
+}
+
+func (b *Backend) newPendingBlock(c *chain) *pendingBlock {
+	head := c.head()
+	return &pendingBlock{
+		state:     c.states[len(c.states)-1].Copy(),
+		timestamp: head.Timestamp + 1,
+	}
+}
+
+// Commit mines a block with the pending transactions
+func (b *Backend) Commit() *ethgo.Block {
+	b.lock.Lock()
+	event := b.commitLocked()
+	b.lock.Unlock()
+
+	b.notify(event)
+	return event.block
+}
+
+func (b *Backend) commitLocked() *chainEvent {
+	c := b.chain
+	pending := c.pending
+	parent := c.head()
+
+	block := &ethgo.Block{
+		Number:     parent.Number + 1,
+		ParentHash: parent.Hash,
+		Sha3Uncles: emptyUncleHash,
+		Miner:      b.config.Coinbase,
+		Difficulty: new(big.Int),
+		GasLimit:   b.config.GasLimit,
+		GasUsed:    pending.gasUsed,
+		Timestamp:  pending.timestamp,
+	}
+	for _, txn := range pending.txns {
+		block.TransactionsHashes = append(block.TransactionsHashes, txn.Hash)
+	}
+	block.Hash = blockHash(block)
+
+	logs := []*ethgo.Log{}
+	for indx, txn := range pending.txns {
+		minedTxn := *txn
+		minedTxn.BlockHash = block.Hash
+		minedTxn.BlockNumber = block.Number
+		minedTxn.TxnIndex = uint64(indx)
+		block.Transactions = append(block.Transactions, &minedTxn)
+		c.txns[txn.Hash] = &minedTxn
+
+		receipt := *pending.receipts[indx]
+		receipt.BlockHash = block.Hash
+		receipt.BlockNumber = block.Number
+		receipt.TransactionIndex = uint64(indx)
+		receipt.Logs = []*ethgo.Log{}
+		for _, l := range pending.receipts[indx].Logs {
+			log := *l
+			log.BlockHash = block.Hash
+			log.BlockNumber = block.Number
+			log.TransactionHash = txn.Hash
+			log.TransactionIndex = uint64(indx)
+			log.LogIndex = uint64(len(logs))
+			receipt.Logs = append(receipt.Logs, &log)
+			logs = append(logs, &log)
+		}
+		receipt.LogsBloom = logsBloom(receipt.Logs)
+		c.receipts[txn.Hash] = &receipt
+	}
+
+	c.blocks = append(c.blocks, block)
+	c.states = append(c.states, pending.state)
+	c.pending = b.newPendingBlock(c)
+
+	return &chainEvent{block: block, logs: logs}
+}
+
+// Snapshot stores the state of the chain and returns its identifier
+func (b *Backend) Snapshot() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.snapshotID++
+	b.snapshots[b.snapshotID] = b.chain.copy()
+	return b.snapshotID
+}
+
+// Revert reverts the chain to the snapshot. The snapshot and
+// the ones taken after it are removed.
+func (b *Backend) Revert(id int) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	snapshot, ok := b.snapshots[id]
+	if !ok {
+		return fmt.Errorf("snapshot %d not found", id)
+	}
+	for i := range b.snapshots {
+		if i >= id {
+			delete(b.snapshots, i)
+		}
+	}
+	b.chain = snapshot
+	return nil
+}
+
+// AdjustTime moves the timestamp of the next block. It fails
+// if there are pending transactions.
+func (b *Backend) AdjustTime(d time.Duration) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.chain.pending.txns) != 0 {
+		return fmt.Errorf("could not adjust time on non-empty block")
+	}
+	b.chain.pending.timestamp += uint64(d / time.Second)
+	return nil
+}
+
+// SendTransaction adds a signed transaction to the pending block. It accepts
+// the legacy EIP-155 transactions and the EIP-2930 and EIP-1559 typed ones.
+func (b *Backend) SendTransaction(txn *ethgo.Transaction) (ethgo.Hash, error) {
+	chainID := b.config.ChainID.Uint64()
+
+	switch txn.Type {
+	case ethgo.TransactionLegacy:
+		v := new(big.Int).SetBytes(txn.V).Uint64()
+		if v != 35+chainID*2 && v != 36+chainID*2 {
+			return ethgo.Hash{}, fmt.Errorf("invalid chain id for signer")
+		}
+
+	case ethgo.TransactionAccessList, ethgo.TransactionDynamicFee:
+		if txn.ChainID == nil || txn.ChainID.Cmp(b.config.ChainID) != 0 {
+			return ethgo.Hash{}, fmt.Errorf("invalid chain id for signer")
+		}
+
+	default:
+		return ethgo.Hash{}, fmt.Errorf("transaction type %d not supported", txn.Type)
+	}
+
+	if txn.Type == ethgo.TransactionDynamicFee {
+		if txn.MaxFeePerGas == nil || txn.MaxPriorityFeePerGas == nil {
+			return ethgo.Hash{}, fmt.Errorf("max fee per gas and max priority fee per gas are required")
+		}
+		if txn.MaxPriorityFeePerGas.Cmp(txn.MaxFeePerGas) > 0 {
+			return ethgo.Hash{}, fmt.Errorf("max priority fee per gas higher than max fee per gas")
+		}
+		if !txn.MaxFeePerGas.IsUint64() {
+			return ethgo.Hash{}, fmt.Errorf("max fee per gas higher than 2^64-1")
+		}
+	}
+
+	from, err := wallet.NewEIP155Signer(chainID).RecoverSender(txn)
+	if err != nil {
+		return ethgo.Hash{}, fmt.Errorf("invalid sender: %v", err)
+	}
+	txn.From = from
+
+	if txn.Hash, err = txn.GetHash(); err != nil {
+		return ethgo.Hash{}, err
+	}
+	return b.addTransaction(txn)
+}
+
+// sendUnsignedTransaction adds a transaction of an unlocked account to the pending block
+func (b *Backend) sendUnsignedTransaction(txn *ethgo.Transaction) (ethgo.Hash, error) {
+	unlocked := false
+	for _, addr := range b.config.Accounts {
+		if addr == txn.From {
+			unlocked = true
+			break
+		}
+	}
+	if !unlocked {
+		return ethgo.Hash{}, fmt.Errorf("unknown account %s", txn.From)
+	}
+
+	raw, err := txn.MarshalRLPTo(nil)
+	if err != nil {
+		return ethgo.Hash{}, err
+	}
+	txn.Hash = ethgo.BytesToHash(ethgo.Keccak256(raw, txn.From.Bytes()))
+	return b.addTransaction(txn)
+}
+
+func (b *Backend) addTransaction(txn *ethgo.Transaction) (ethgo.Hash, error) {
+	b.lock.Lock()
+
+	c := b.chain
+	pending := c.pending
+	if _, ok := c.txns[txn.Hash]; ok {
+		b.lock.Unlock()
+		return ethgo.Hash{}, fmt.Errorf("already known")
+	}
+	for _, p := range pending.txns {
+		if p.Hash == txn.Hash {
+			b.lock.Unlock()
+			return ethgo.Hash{}, fmt.Errorf("already known")
+		}
+	}
+
+	if nonce := pending.state.GetNonce(txn.From); txn.Nonce < nonce {
+		b.lock.Unlock()
+		return ethgo.Hash{}, fmt.Errorf("nonce too low: address %s, tx: %d state: %d", txn.From, txn.Nonce, nonce)
+	} else if txn.Nonce > nonce {
+		b.lock.Unlock()
+		return ethgo.Hash{}, fmt.Errorf("nonce too high: address %s, tx: %d state: %d", txn.From, txn.Nonce, nonce)
+	}
+	if pending.gasUsed+txn.Gas > b.config.GasLimit {
+		b.lock.Unlock()
+		return ethgo.Hash{}, fmt.Errorf("exceeds block gas limit")
+	}
+
+	block := b.blockContext(c, nil)
+	if txn.Type == ethgo.TransactionDynamicFee {
+		if txn.MaxFeePerGas.Cmp(block.BaseFee) < 0 {
+			b.lock.Unlock()
+			return ethgo.Hash{}, fmt.Errorf("max fee per gas less than block base fee: maxFeePerGas: %s baseFee: %s", txn.MaxFeePerGas, block.BaseFee)
+		}
+		// the sender pays the base fee and the tip up to the fee cap
+		price := new(big.Int).Add(block.BaseFee, txn.MaxPriorityFeePerGas)
+		if price.Cmp(txn.MaxFeePerGas) > 0 {
+			price = txn.MaxFeePerGas
+		}
+		txn.GasPrice = price.Uint64()
+	}
+
+	msg := &Message{
+		From:       txn.From,
+		To:         txn.To,
+		Nonce:      txn.Nonce,
+		Value:      txn.Value,
+		Data:       txn.Input,
+		GasPrice:   new(big.Int).SetUint64(txn.GasPrice),
+		AccessList: txn.AccessList,
+	}
+	res, gasUsed, err := b.applyMessage(pending.state, block, msg, txn.Gas)
+	if err != nil {
+		b.lock.Unlock()
+		return ethgo.Hash{}, err
+	}
+	pending.gasUsed += gasUsed
+
+	receipt := &ethgo.Receipt{
+		TransactionHash:   txn.Hash,
+		From:              txn.From,
+		ContractAddress:   res.ContractAddress,
+		GasUsed:           gasUsed,
+		CumulativeGasUsed: pending.gasUsed,
+		Logs:              res.Logs,
+		Status:            1,
+	}
+	if res.Err != nil {
+		receipt.Status = 0
+	}
+	pending.txns = append(pending.txns, txn)
+	pending.receipts = append(pending.receipts, receipt)
+
+	var event *chainEvent
+	if b.config.AutoMine {
+		event = b.commitLocked()
+	}
+	b.lock.Unlock()
+
+	if event != nil {
+		b.notify(event)
+	}
+	return txn.Hash, nil
+}
+
+// blockContext returns the context of the pending block or of the given block
+func (b *Backend) blockContext(c *chain, block *ethgo.Block) *BlockContext {
+	ctx := &BlockContext{
+		Coinbase:   b.config.Coinbase,
+		GasLimit:   b.config.GasLimit,
+		Difficulty: new(big.Int),
+		BaseFee:    new(big.Int),
+		ChainID:    new(big.Int).Set(b.config.ChainID),
+	}
+	if block == nil {
+		ctx.Number = c.head().Number + 1
+		ctx.Timestamp = c.pending.timestamp
+	} else {
+		ctx.Number = block.Number
+		ctx.Timestamp = block.Timestamp
+	}
+
+	blocks := c.blocks
+	ctx.GetHash = func(num uint64) ethgo.Hash {
+		if num >= ctx.Number || num >= uint64(len(blocks)) {
+			return ethgo.Hash{}
+		}
+		return blocks[num].Hash
+	}
+	return ctx
+}
+
+// applyMessage buys the gas of the message and executes it on the state.
+// It returns the result of the execution and the gas used.
+func (b *Backend) applyMessage(state *State, block *BlockContext, msg *Message, gasLimit uint64) (*ExecutionResult, uint64, error) {
+	intrinsic := intrinsicGas(msg.Data, msg.To == nil, msg.AccessList)
+	if gasLimit < intrinsic {
+		return nil, 0, fmt.Errorf("intrinsic gas too low: have %d, want %d", gasLimit, intrinsic)
+	}
+
+	value := msg.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	price := msg.GasPrice
+	if price == nil {
+		price = new(big.Int)
+	}
+	gasCost := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), price)
+	if cost, balance := new(big.Int).Add(gasCost, value), state.GetBalance(msg.From); balance.Cmp(cost) < 0 {
+		return nil, 0, fmt.Errorf("insufficient funds for gas * price + value: address %s have %s want %s", msg.From, balance, cost)
+	}
+
+	snapshot := state.Snapshot()
+	state.SubBalance(msg.From, gasCost)
+	state.SetNonce(msg.From, msg.Nonce+1)
+
+	msg.Gas = gasLimit - intrinsic
+	msg.IntrinsicGas = intrinsic
+	execSnapshot := state.Snapshot()
+
+	res, err := b.config.Executor.Execute(state, block, msg)
+	if err != nil {
+		state.RevertToSnapshot(snapshot)
+		return nil, 0, err
+	}
+	if res.Err != nil {
+		state.RevertToSnapshot(execSnapshot)
+		res.Logs = nil
+	}
+
+	gasUsed := gasLimit - res.GasLeft
+	state.AddBalance(msg.From, new(big.Int).Mul(new(big.Int).SetUint64(res.GasLeft), price))
+	state.AddBalance(block.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), price))
+
+	return res, gasUsed, nil
+}
+
+// call executes the message on top of the state without committing the changes
+func (b *Backend) call(state *State, block *BlockContext, msg *Message, gasLimit uint64) (*ExecutionResult, uint64, error) {
+	snapshot := state.Snapshot()
+	defer state.RevertToSnapshot(snapshot)
+
+	msg.Nonce = state.GetNonce(msg.From)
+	return b.applyMessage(state, block, msg, gasLimit)
+}
+
+// estimateGas returns the lowest gas limit with which the message does not fail
+func (b *Backend) estimateGas(state *State, block *BlockContext, msg *Message) (uint64, error) {
+	hi := b.config.GasLimit
+
+	res, gasUsed, err := b.call(state, block, msg, hi)
+	if err != nil {
+		return 0, err
+	}
+	if res.Err != nil {
+		return 0, executionError(res)
+	}
+
+	lo := gasUsed - 1
+	for i := 0; lo+1 < hi && i < maxEstimateGasAttempts; i++ {
+		mid := lo + (hi-lo)/2
+		res, _, err := b.call(state, block, msg, mid)
+		if err != nil || res.Err != nil {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi, nil
+}
+
+func (b *Backend) subscribe() (<-chan *chainEvent, func()) {
+	sub := &subscriber{
+		ch:      make(chan *chainEvent, 16),
+		closeCh: make(chan struct{}),
+	}
+
+	b.subsLock.Lock()
+	b.subs[sub] = struct{}{}
+	b.subsLock.Unlock()
+
+	cancel := func() {
+		b.subsLock.Lock()
+		delete(b.subs, sub)
+		b.subsLock.Unlock()
+		close(sub.closeCh)
+	}
+	return sub.ch, cancel
+}
+
+func (b *Backend) notify(event *chainEvent) {
+	b.subsLock.Lock()
+	subs := make([]*subscriber, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.subsLock.Unlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- event:
+		case <-sub.closeCh:
+		}
+	}
+}
+
+// intrinsicGas returns the gas paid by a transaction before its execution
+func intrinsicGas(data []byte, creation bool, accessList ethgo.AccessList) uint64 {
+	gas := uint64(txGas)
+	if creation {
+		gas = txGasContractCreation
+		gas += initCodeWordGas * ((uint64(len(data)) + 31) / 32)
+	}
+	for _, b := range data {
+		if b == 0 {
+			gas += txDataZeroGas
+		} else {
+			gas += txDataNonZeroGas
+		}
+	}
+	for _, entry := range accessList {
+		gas += txAccessListAddressGas
+		gas += txAccessListKeyGas * uint64(len(entry.Storage))
+	}
+	return gas
+}
+
+// blockHash returns the hash that identifies the block in the simulated chain
+func blockHash(b *ethgo.Block) ethgo.Hash {
+	buf := make([]byte, 24)
+	binary.BigEndian.PutUint64(buf[0:], b.Number)
+	binary.BigEndian.PutUint64(buf[8:], b.Timestamp)
+	binary.BigEndian.PutUint64(buf[16:], b.GasUsed)
+
+	data := [][]byte{b.ParentHash.Bytes(), buf}
+	for _, hash := range b.TransactionsHashes {
+		data = append(data, hash.Bytes())
+	}
+	return ethgo.BytesToHash(ethgo.Keccak256(data...))
+}
+
+// logsBloom returns the bloom filter of the addresses and topics of the logs
+func logsBloom(logs []*ethgo.Log) []byte {
+	bloom := make([]byte, 256)
+	add := func(b []byte) {
+		hash := ethgo.Keccak256(b)
+		for i := 0; i < 6; i += 2 {
+			bit := (uint(hash[i])<<8 | uint(hash[i+1])) & 2047
+			bloom[255-bit/8] |= 1 << (bit % 8)
+		}
+	}
+	for _, log := range logs {
+		add(log.Address.Bytes())
+		for _, topic := range log.Topics {
+			add(topic.Bytes())
+		}
+	}
+	return bloom
+}
@@ -0,0 +1,498 @@
+package simulated
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/abi"
+	"github.com/umbracle/ethgo/contract"
+	"github.com/umbracle/ethgo/evm"
+	"github.com/umbracle/ethgo/jsonrpc"
+	"github.com/umbracle/ethgo/wallet"
+)
+
+// echoExecutor returns the input as the output and emits it in a log.
+// The messages whose input starts with 0xff revert.
+type echoExecutor struct{}
+
+func (echoExecutor) Execute(state *State, block *BlockContext, msg *Message) (*ExecutionResult, error) {
+	if len(msg.Data) != 0 && msg.Data[0] == 0xff {
+		return &ExecutionResult{ReturnData: msg.Data[1:], Err: ErrExecutionReverted}, nil
+	}
+	if msg.To == nil {
+		return nil, errors.New("contract creation not supported")
+	}
+	res := &ExecutionResult{
+		ReturnData: msg.Data,
+		GasLeft:    msg.Gas - uint64(len(msg.Data))*100,
+		Logs: []*ethgo.Log{
+			{Address: *msg.To, Topics: []ethgo.Hash{ethgo.BytesToHash(msg.Data)}, Data: msg.Data},
+		},
+	}
+	return res, nil
+}
+
+func newTestBackend(t *testing.T, executor Executor) (*Backend, *wallet.Key, *jsonrpc.Client) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	config := DefaultConfig()
+	config.Alloc[key.Address()] = &Account{Balance: ethgo.Ether(10)}
+	config.Executor = executor
+
+	b, err := NewBackend(config)
+	assert.NoError(t, err)
+	return b, key, jsonrpc.NewClientWithTransport(b)
+}
+
+func sendTxn(t *testing.T, c *jsonrpc.Client, key *wallet.Key, txn *ethgo.Transaction) (ethgo.Hash, error) {
+	chainID, err := c.Eth().ChainID()
+	assert.NoError(t, err)
+
+	signed, err := wallet.NewEIP155Signer(chainID.Uint64()).SignTx(txn, key)
+	assert.NoError(t, err)
+
+	raw, err := signed.MarshalRLPTo(nil)
+	assert.NoError(t, err)
+	return c.Eth().SendRawTransaction(raw)
+}
+
+func TestBackend_Transfer(t *testing.T) {
+	b, key, c := newTestBackend(t, nil)
+	defer c.Close()
+
+	to := ethgo.Address{0x1}
+	hash, err := sendTxn(t, c, key, &ethgo.Transaction{
+		To:       &to,
+		Value:    ethgo.Ether(1),
+		Gas:      21000,
+		GasPrice: 1,
+	})
+	assert.NoError(t, err)
+
+	// the transaction is pending
+	receipt, err := c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Nil(t, receipt)
+
+	nonce, err := c.Eth().GetNonce(key.Address(), ethgo.Pending)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), nonce)
+
+	// the nonce is already used
+	_, err = sendTxn(t, c, key, &ethgo.Transaction{To: &to, Gas: 21000})
+	assert.True(t, errors.Is(err, jsonrpc.ErrNonceTooLow))
+
+	block := b.Commit()
+	assert.Equal(t, uint64(1), block.Number)
+
+	receipt, err = c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, uint64(21000), receipt.GasUsed)
+	assert.Equal(t, block.Hash, receipt.BlockHash)
+
+	balance, err := c.Eth().GetBalance(to, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, ethgo.Ether(1), balance)
+
+	expected := new(big.Int).Sub(ethgo.Ether(9), big.NewInt(21000))
+	balance, err = c.Eth().GetBalance(key.Address(), ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, balance)
+
+	// the genesis state is kept
+	balance, err = c.Eth().GetBalance(to, ethgo.BlockNumber(0))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, balance.Sign())
+
+	txn, err := c.Eth().GetTransactionByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Address(), txn.From)
+	assert.Equal(t, uint64(1), txn.BlockNumber)
+
+	full, err := c.Eth().GetBlockByNumber(ethgo.Latest, true)
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash, full.Hash)
+	assert.Len(t, full.Transactions, 1)
+}
+
+func TestBackend_DynamicFee(t *testing.T) {
+	b, key, c := newTestBackend(t, nil)
+	defer c.Close()
+
+	to := ethgo.Address{0x1}
+	txn := &ethgo.Transaction{
+		Type:                 ethgo.TransactionDynamicFee,
+		To:                   &to,
+		Value:                ethgo.Ether(1),
+		Gas:                  21000 + 2400,
+		MaxFeePerGas:         big.NewInt(10),
+		MaxPriorityFeePerGas: big.NewInt(2),
+		AccessList:           ethgo.AccessList{{Address: to}},
+	}
+	hash, err := sendTxn(t, c, key, txn)
+	assert.NoError(t, err)
+	b.Commit()
+
+	receipt, err := c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, uint64(21000+2400), receipt.GasUsed)
+
+	// the base fee is zero, the sender only pays the tip
+	expected := new(big.Int).Sub(ethgo.Ether(9), big.NewInt((21000+2400)*2))
+	balance, err := c.Eth().GetBalance(key.Address(), ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, balance)
+
+	mined, err := c.Eth().GetTransactionByHash(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, ethgo.TransactionDynamicFee, mined.Type)
+	assert.Equal(t, uint64(10), mined.MaxFeePerGas.Uint64())
+	assert.Equal(t, uint64(2), mined.GasPrice)
+
+	// the tip cannot be higher than the fee cap
+	_, err = sendTxn(t, c, key, &ethgo.Transaction{
+		Type:                 ethgo.TransactionDynamicFee,
+		Nonce:                1,
+		To:                   &to,
+		Gas:                  21000,
+		MaxFeePerGas:         big.NewInt(1),
+		MaxPriorityFeePerGas: big.NewInt(2),
+	})
+	assert.Error(t, err)
+
+	// the chain id has to match
+	_, err = sendTxn(t, c, key, &ethgo.Transaction{
+		Type:                 ethgo.TransactionDynamicFee,
+		ChainID:              big.NewInt(1),
+		Nonce:                1,
+		To:                   &to,
+		Gas:                  21000,
+		MaxFeePerGas:         big.NewInt(1),
+		MaxPriorityFeePerGas: big.NewInt(1),
+	})
+	assert.Error(t, err)
+}
+
+func TestBackend_Contract(t *testing.T) {
+	b, key, c := newTestBackend(t, nil)
+	defer c.Close()
+
+	// the contract stores the input in the slot 0 and returns the previous value
+	runtime := []byte{
+		0x60, 0x00, 0x54, 0x60, 0x00, 0x52,
+		0x60, 0x00, 0x35, 0x60, 0x00, 0x55,
+		0x60, 0x20, 0x60, 0x00, 0xf3,
+	}
+	initCode := append([]byte{0x70}, runtime...)
+	initCode = append(initCode, 0x60, 0x00, 0x52, 0x60, 0x11, 0x60, 0x0f, 0xf3)
+
+	gas, err := c.Eth().EstimateGas(&ethgo.CallMsg{From: key.Address(), Data: initCode})
+	assert.NoError(t, err)
+
+	hash, err := sendTxn(t, c, key, &ethgo.Transaction{Gas: gas, Input: initCode})
+	assert.NoError(t, err)
+	b.Commit()
+
+	receipt, err := c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, evm.CreateAddress(key.Address(), 0), receipt.ContractAddress)
+
+	addr := receipt.ContractAddress
+	code, err := c.Eth().GetCode(addr, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x"+hex.EncodeToString(runtime), code)
+
+	input := ethgo.BytesToHash([]byte{0x5})
+	_, err = sendTxn(t, c, key, &ethgo.Transaction{Nonce: 1, To: &addr, Gas: 100000, Input: input[:]})
+	assert.NoError(t, err)
+	b.Commit()
+
+	slot, err := c.Eth().GetStorageAt(addr, ethgo.Hash{}, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, input, slot)
+
+	out, err := c.Eth().Call(&ethgo.CallMsg{To: &addr, Data: []byte{}}, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, input.String(), out)
+}
+
+func TestBackend_ContractBinding(t *testing.T) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	config := DefaultConfig()
+	config.Alloc[key.Address()] = &Account{Balance: ethgo.Ether(10)}
+	config.AutoMine = true
+
+	b, err := NewBackend(config)
+	assert.NoError(t, err)
+
+	c := jsonrpc.NewClientWithTransport(b)
+	defer c.Close()
+
+	artifact := abi.MustNewABI(`[
+		{"type": "function", "name": "set", "stateMutability": "nonpayable", "inputs": [{"name": "val", "type": "uint256"}], "outputs": []},
+		{"type": "function", "name": "get", "stateMutability": "view", "inputs": [], "outputs": [{"name": "val", "type": "uint256"}]},
+		{"type": "event", "name": "Set", "anonymous": false, "inputs": [{"name": "val", "type": "uint256", "indexed": false}]}
+	]`)
+	event := artifact.Events["Set"]
+
+	// set stores the value in the slot 0 and emits Set, get returns it
+	runtime := []byte{
+		0x60, 0x00, 0x35, 0x60, 0xe0, 0x1c,
+		0x80, 0x63, 0x60, 0xfe, 0x47, 0xb1, 0x14, 0x60, 0x1d, 0x57,
+		0x63, 0x6d, 0x4c, 0xe6, 0x3c, 0x14, 0x60, 0x4f, 0x57,
+		0x60, 0x00, 0x80, 0xfd,
+		// set
+		0x5b, 0x60, 0x04, 0x35, 0x80, 0x60, 0x00, 0x55, 0x60, 0x00, 0x52, 0x7f,
+	}
+	topic := event.ID()
+	runtime = append(runtime, topic[:]...)
+	runtime = append(runtime,
+		0x60, 0x20, 0x60, 0x00, 0xa1, 0x00,
+		// get
+		0x5b, 0x60, 0x00, 0x54, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3,
+	)
+	bin := append([]byte{0x60, byte(len(runtime)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}, runtime...)
+
+	opts := []contract.ContractOption{contract.WithJsonRPC(c.Eth()), contract.WithSender(key)}
+
+	txn, err := contract.DeployContract(artifact, bin, nil, opts...)
+	assert.NoError(t, err)
+	assert.NoError(t, txn.Do())
+
+	receipt, err := txn.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+
+	code, err := c.Eth().GetCode(receipt.ContractAddress, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x"+hex.EncodeToString(runtime), code)
+
+	addr := receipt.ContractAddress
+	cc := contract.NewContract(addr, artifact, opts...)
+
+	receipt, err = cc.Send("set", &contract.TxnOpts{}, big.NewInt(42))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Len(t, receipt.Logs, 1)
+
+	log, err := event.ParseLog(receipt.Logs[0])
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), log["val"])
+
+	res, err := cc.Call("get", ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), res["val"])
+
+	// the unknown methods revert
+	_, err = c.Eth().Call(&ethgo.CallMsg{To: &addr, Data: []byte{0x1, 0x2, 0x3, 0x4}}, ethgo.Latest)
+	assert.True(t, errors.Is(err, jsonrpc.ErrExecutionReverted))
+}
+
+func TestBackend_Refund(t *testing.T) {
+	key, err := wallet.GenerateKey()
+	assert.NoError(t, err)
+
+	// the contract clears the slots 0 and 1
+	addr := ethgo.Address{0x1}
+	config := DefaultConfig()
+	config.Alloc[key.Address()] = &Account{Balance: ethgo.Ether(10)}
+	config.Alloc[addr] = &Account{
+		Code: []byte{0x60, 0x00, 0x60, 0x00, 0x55, 0x60, 0x00, 0x60, 0x01, 0x55},
+		Storage: map[ethgo.Hash]ethgo.Hash{
+			ethgo.BytesToHash([]byte{0x0}): ethgo.BytesToHash([]byte{0x1}),
+			ethgo.BytesToHash([]byte{0x1}): ethgo.BytesToHash([]byte{0x1}),
+		},
+	}
+
+	b, err := NewBackend(config)
+	assert.NoError(t, err)
+
+	c := jsonrpc.NewClientWithTransport(b)
+	defer c.Close()
+
+	hash, err := sendTxn(t, c, key, &ethgo.Transaction{To: &addr, Gas: 100000, GasPrice: 1})
+	assert.NoError(t, err)
+	b.Commit()
+
+	// the gas used is the one of geth, the refund is capped
+	// to a fifth of the gas used including the intrinsic gas
+	receipt, err := c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Equal(t, uint64(21000+10012-31012/5), receipt.GasUsed)
+}
+
+func TestBackend_Executor(t *testing.T) {
+	b, key, c := newTestBackend(t, echoExecutor{})
+	defer c.Close()
+
+	to := ethgo.Address{0x1}
+
+	out, err := c.Eth().Call(&ethgo.CallMsg{To: &to, Data: []byte{0x1, 0x2}}, ethgo.Latest)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0102", out)
+
+	_, err = c.Eth().Call(&ethgo.CallMsg{To: &to, Data: []byte{0xff, 0x1}}, ethgo.Latest)
+	var revertErr *jsonrpc.RevertError
+	assert.True(t, errors.As(err, &revertErr))
+	assert.Equal(t, []byte{0x1}, revertErr.Data)
+
+	gas, err := c.Eth().EstimateGas(&ethgo.CallMsg{From: key.Address(), To: &to, Data: []byte{0x1, 0x2}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(21000+2*16+200), gas)
+
+	hash, err := sendTxn(t, c, key, &ethgo.Transaction{To: &to, Gas: gas, Input: []byte{0x1, 0x2}})
+	assert.NoError(t, err)
+	reverted, err := sendTxn(t, c, key, &ethgo.Transaction{Nonce: 1, To: &to, Gas: gas, Input: []byte{0xff}})
+	assert.NoError(t, err)
+	b.Commit()
+
+	receipt, err := c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+	assert.Len(t, receipt.Logs, 1)
+	assert.Equal(t, hash, receipt.Logs[0].TransactionHash)
+
+	receipt, err = c.Eth().GetTransactionReceipt(reverted)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), receipt.Status)
+	assert.Len(t, receipt.Logs, 0)
+
+	filter := &ethgo.LogFilter{Address: []ethgo.Address{to}}
+	filter.SetFromUint64(0)
+	logs, err := c.Eth().GetLogs(filter)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, []byte{0x1, 0x2}, logs[0].Data)
+
+	filter = &ethgo.LogFilter{Address: []ethgo.Address{{0x2}}}
+	logs, err = c.Eth().GetLogs(filter)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 0)
+}
+
+func TestBackend_SnapshotRevert(t *testing.T) {
+	config := DefaultConfig()
+	config.AutoMine = true
+
+	b, err := NewBackend(config)
+	assert.NoError(t, err)
+	c := jsonrpc.NewClientWithTransport(b)
+	defer c.Close()
+
+	id := b.Snapshot()
+
+	b.Commit()
+	b.Commit()
+
+	num, err := c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), num)
+
+	assert.NoError(t, b.Revert(id))
+	num, err = c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), num)
+
+	// the snapshot is removed after the revert
+	assert.Error(t, b.Revert(id))
+
+	// the snapshots are also available with the evm namespace
+	var snapshot string
+	assert.NoError(t, c.Call("evm_snapshot", &snapshot))
+	var mined string
+	assert.NoError(t, c.Call("evm_mine", &mined))
+
+	var reverted bool
+	assert.NoError(t, c.Call("evm_revert", &reverted, snapshot))
+	assert.True(t, reverted)
+
+	num, err = c.Eth().BlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), num)
+}
+
+func TestBackend_Accounts(t *testing.T) {
+	from := ethgo.Address{0x1}
+
+	config := DefaultConfig()
+	config.Alloc[from] = &Account{Balance: ethgo.Ether(1)}
+	config.Accounts = []ethgo.Address{from}
+	config.AutoMine = true
+
+	b, err := NewBackend(config)
+	assert.NoError(t, err)
+	c := jsonrpc.NewClientWithTransport(b)
+	defer c.Close()
+
+	accounts, err := c.Eth().Accounts()
+	assert.NoError(t, err)
+	assert.Equal(t, []ethgo.Address{from}, accounts)
+
+	to := ethgo.Address{0x2}
+	hash, err := c.Eth().SendTransaction(&ethgo.Transaction{From: from, To: &to, Value: big.NewInt(10)})
+	assert.NoError(t, err)
+
+	receipt, err := c.Eth().GetTransactionReceipt(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), receipt.Status)
+
+	// the account is not unlocked
+	_, err = c.Eth().SendTransaction(&ethgo.Transaction{From: to, To: &from})
+	assert.Error(t, err)
+}
+
+func TestBackend_Subscribe(t *testing.T) {
+	b, key, c := newTestBackend(t, echoExecutor{})
+	defer c.Close()
+
+	heads := make(chan *ethgo.Block, 1)
+	sub, err := c.Eth().SubscribeNewHeads(heads)
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	logs := make(chan *ethgo.Log, 1)
+	logsSub, err := c.Eth().SubscribeLogs(&ethgo.LogFilter{}, logs)
+	assert.NoError(t, err)
+	defer logsSub.Unsubscribe()
+
+	to := ethgo.Address{0x1}
+	_, err = sendTxn(t, c, key, &ethgo.Transaction{To: &to, Gas: 100000, Input: []byte{0x1}})
+	assert.NoError(t, err)
+	block := b.Commit()
+
+	select {
+	case head := <-heads:
+		assert.Equal(t, block.Hash, head.Hash)
+	case <-time.After(5 * time.Second):
+		t.Fatal("head not received")
+	}
+	select {
+	case log := <-logs:
+		assert.Equal(t, block.Hash, log.BlockHash)
+	case <-time.After(5 * time.Second):
+		t.Fatal("log not received")
+	}
+}
+
+func TestBackend_AdjustTime(t *testing.T) {
+	b, err := NewBackend(nil)
+	assert.NoError(t, err)
+	defer b.Close()
+
+	parent := b.Commit()
+	assert.NoError(t, b.AdjustTime(time.Hour))
+	block := b.Commit()
+	assert.Equal(t, parent.Timestamp+3601, block.Timestamp)
+}
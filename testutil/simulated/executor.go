@@ -0,0 +1,113 @@
+package simulated
+
+import (
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+	"github.com/umbracle/ethgo/evm"
+)
+
+// ErrExecutionReverted is the error of an execution that reverts.
+// The return data of the result is the revert data.
+var ErrExecutionReverted = evm.ErrExecutionReverted
+
+// BlockContext is the block in which a message is executed
+type BlockContext struct {
+	Number     uint64
+	Timestamp  uint64
+	Coinbase   ethgo.Address
+	GasLimit   uint64
+	Difficulty *big.Int
+	BaseFee    *big.Int
+	ChainID    *big.Int
+
+	// GetHash returns the hash of a previous block
+	GetHash func(num uint64) ethgo.Hash
+}
+
+// Message is a transaction or a call to execute
+type Message struct {
+	From     ethgo.Address
+	To       *ethgo.Address
+	Nonce    uint64
+	Value    *big.Int
+	Data     []byte
+	GasPrice *big.Int
+
+	// AccessList are the accounts and slots of the EIP-2930
+	// transactions that are warm from the start
+	AccessList ethgo.AccessList
+
+	// Gas is the gas available for the execution once the
+	// intrinsic gas of the transaction is paid
+	Gas uint64
+
+	// IntrinsicGas is the gas paid before the execution
+	IntrinsicGas uint64
+}
+
+// ExecutionResult is the result of the execution of a message
+type ExecutionResult struct {
+	// ReturnData is the output of the call or the revert data
+	ReturnData []byte
+
+	// GasLeft is the unused gas of the message
+	GasLeft uint64
+
+	// Logs are the logs emitted by the execution
+	Logs []*ethgo.Log
+
+	// ContractAddress is the address of the created contract
+	ContractAddress ethgo.Address
+
+	// Err is the failure of the execution. The changes in the state are
+	// reverted but the transaction is still included in the block.
+	Err error
+}
+
+// Executor executes the messages on the state. The backend increments the
+// nonce of the sender and buys the gas before the execution and refunds the
+// gas left after it. An error means that the message cannot be executed at all.
+type Executor interface {
+	Execute(state *State, block *BlockContext, msg *Message) (*ExecutionResult, error)
+}
+
+// evmExecutor executes the messages with the evm interpreter
+type evmExecutor struct{}
+
+func (evmExecutor) Execute(state *State, block *BlockContext, msg *Message) (*ExecutionResult, error) {
+	blockCtx := &evm.BlockContext{
+		Coinbase:   block.Coinbase,
+		GasLimit:   block.GasLimit,
+		Number:     block.Number,
+		Timestamp:  block.Timestamp,
+		Difficulty: block.Difficulty,
+		BaseFee:    block.BaseFee,
+		ChainID:    block.ChainID,
+		GetHash:    block.GetHash,
+	}
+	txCtx := &evm.TxContext{
+		Origin:   msg.From,
+		GasPrice: msg.GasPrice,
+	}
+
+	res := evm.NewEVM(blockCtx, txCtx, state, nil).ApplyMessage(&evm.Message{
+		From:         msg.From,
+		To:           msg.To,
+		Nonce:        msg.Nonce,
+		Value:        msg.Value,
+		Data:         msg.Data,
+		Gas:          msg.Gas,
+		IntrinsicGas: msg.IntrinsicGas,
+		AccessList:   msg.AccessList,
+	})
+
+	result := &ExecutionResult{
+		ReturnData:      res.ReturnData,
+		GasLeft:         res.GasLeft,
+		Logs:            res.Logs,
+		ContractAddress: res.ContractAddress,
+		Err:             res.Err,
+	}
+	return result, nil
+}
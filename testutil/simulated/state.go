@@ -0,0 +1,204 @@
+package simulated
+
+import (
+	"math/big"
+
+	"github.com/umbracle/ethgo"
+)
+
+// Account is an account of the simulated chain
+type Account struct {
+	Nonce   uint64
+	Balance *big.Int
+	Code    []byte
+	Storage map[ethgo.Hash]ethgo.Hash
+}
+
+func (a *Account) copy() *Account {
+	aa := &Account{
+		Nonce:   a.Nonce,
+		Balance: new(big.Int),
+		Code:    append([]byte{}, a.Code...),
+		Storage: map[ethgo.Hash]ethgo.Hash{},
+	}
+	if a.Balance != nil {
+		aa.Balance.Set(a.Balance)
+	}
+	for k, v := range a.Storage {
+		aa.Storage[k] = v
+	}
+	return aa
+}
+
+// State is the world state of the simulated chain. The changes are
+// journaled so that they can be reverted to a snapshot.
+type State struct {
+	accounts map[ethgo.Address]*Account
+	journal  []func()
+}
+
+// NewState creates a state with the given accounts
+func NewState(alloc map[ethgo.Address]*Account) *State {
+	s := &State{
+		accounts: map[ethgo.Address]*Account{},
+	}
+	for addr, acct := range alloc {
+		s.accounts[addr] = acct.copy()
+	}
+	return s
+}
+
+// Copy returns a deep copy of the state without the journal
+func (s *State) Copy() *State {
+	return NewState(s.accounts)
+}
+
+// Exist returns true if the account exists
+func (s *State) Exist(addr ethgo.Address) bool {
+	_, ok := s.accounts[addr]
+	return ok
+}
+
+// Empty returns true if the account does not exist or has no
+// nonce, balance or code
+func (s *State) Empty(addr ethgo.Address) bool {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		return true
+	}
+	return acct.Nonce == 0 && acct.Balance.Sign() == 0 && len(acct.Code) == 0
+}
+
+func (s *State) account(addr ethgo.Address) *Account {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		acct = &Account{
+			Balance: new(big.Int),
+			Storage: map[ethgo.Hash]ethgo.Hash{},
+		}
+		s.accounts[addr] = acct
+		s.journal = append(s.journal, func() {
+			delete(s.accounts, addr)
+		})
+	}
+	return acct
+}
+
+// GetBalance returns the balance of the account
+func (s *State) GetBalance(addr ethgo.Address) *big.Int {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(acct.Balance)
+}
+
+// SetBalance sets the balance of the account
+func (s *State) SetBalance(addr ethgo.Address, balance *big.Int) {
+	acct := s.account(addr)
+	prev := acct.Balance
+	acct.Balance = new(big.Int).Set(balance)
+	s.journal = append(s.journal, func() {
+		acct.Balance = prev
+	})
+}
+
+// AddBalance adds the amount to the balance of the account
+func (s *State) AddBalance(addr ethgo.Address, amount *big.Int) {
+	s.SetBalance(addr, new(big.Int).Add(s.GetBalance(addr), amount))
+}
+
+// SubBalance subtracts the amount from the balance of the account
+func (s *State) SubBalance(addr ethgo.Address, amount *big.Int) {
+	s.SetBalance(addr, new(big.Int).Sub(s.GetBalance(addr), amount))
+}
+
+// GetNonce returns the nonce of the account
+func (s *State) GetNonce(addr ethgo.Address) uint64 {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		return 0
+	}
+	return acct.Nonce
+}
+
+// SetNonce sets the nonce of the account
+func (s *State) SetNonce(addr ethgo.Address, nonce uint64) {
+	acct := s.account(addr)
+	prev := acct.Nonce
+	acct.Nonce = nonce
+	s.journal = append(s.journal, func() {
+		acct.Nonce = prev
+	})
+}
+
+// GetCode returns the code of the account
+func (s *State) GetCode(addr ethgo.Address) []byte {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		return nil
+	}
+	return acct.Code
+}
+
+// SetCode sets the code of the account
+func (s *State) SetCode(addr ethgo.Address, code []byte) {
+	acct := s.account(addr)
+	prev := acct.Code
+	acct.Code = append([]byte{}, code...)
+	s.journal = append(s.journal, func() {
+		acct.Code = prev
+	})
+}
+
+// GetState returns the value of the storage slot of the account
+func (s *State) GetState(addr ethgo.Address, key ethgo.Hash) ethgo.Hash {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		return ethgo.Hash{}
+	}
+	return acct.Storage[key]
+}
+
+// SetState sets the value of the storage slot of the account
+func (s *State) SetState(addr ethgo.Address, key, value ethgo.Hash) {
+	acct := s.account(addr)
+	prev, ok := acct.Storage[key]
+	if value == (ethgo.Hash{}) {
+		delete(acct.Storage, key)
+	} else {
+		acct.Storage[key] = value
+	}
+	s.journal = append(s.journal, func() {
+		if ok {
+			acct.Storage[key] = prev
+		} else {
+			delete(acct.Storage, key)
+		}
+	})
+}
+
+// DeleteAccount removes the account from the state
+func (s *State) DeleteAccount(addr ethgo.Address) {
+	acct, ok := s.accounts[addr]
+	if !ok {
+		return
+	}
+	delete(s.accounts, addr)
+	s.journal = append(s.journal, func() {
+		s.accounts[addr] = acct
+	})
+}
+
+// Snapshot returns an identifier of the current state
+func (s *State) Snapshot() int {
+	return len(s.journal)
+}
+
+// RevertToSnapshot reverts the changes done after the snapshot
+func (s *State) RevertToSnapshot(id int) {
+	for i := len(s.journal) - 1; i >= id; i-- {
+		s.journal[i]()
+	}
+	s.journal = s.journal[:id]
+}
@@ -0,0 +1,449 @@
+package simulated
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/umbracle/ethgo"
+)
+
+// hexUint is an uint64 encoded as a hex string
+type hexUint uint64
+
+func (h hexUint) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("0x%x", uint64(h))), nil
+}
+
+func (h *hexUint) UnmarshalText(b []byte) error {
+	str := string(b)
+	if !strings.HasPrefix(str, "0x") {
+		return fmt.Errorf("hex string without 0x prefix: %s", str)
+	}
+	num, err := strconv.ParseUint(str[2:], 16, 64)
+	if err != nil {
+		return err
+	}
+	*h = hexUint(num)
+	return nil
+}
+
+// hexBig is a big.Int encoded as a hex string
+type hexBig big.Int
+
+func newHexBig(i *big.Int) *hexBig {
+	if i == nil {
+		i = new(big.Int)
+	}
+	return (*hexBig)(i)
+}
+
+func (h *hexBig) toInt() *big.Int {
+	if h == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set((*big.Int)(h))
+}
+
+func (h hexBig) MarshalText() ([]byte, error) {
+	i := big.Int(h)
+	return []byte(fmt.Sprintf("0x%x", &i)), nil
+}
+
+func (h *hexBig) UnmarshalText(b []byte) error {
+	str := string(b)
+	if !strings.HasPrefix(str, "0x") {
+		return fmt.Errorf("hex string without 0x prefix: %s", str)
+	}
+	i, ok := new(big.Int).SetString(str[2:], 16)
+	if !ok {
+		return fmt.Errorf("failed to decode big int: %s", str)
+	}
+	*h = hexBig(*i)
+	return nil
+}
+
+// hexBytes is a byte slice encoded as a hex string
+type hexBytes []byte
+
+func (h hexBytes) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(h)), nil
+}
+
+func (h *hexBytes) UnmarshalText(b []byte) error {
+	str := string(b)
+	if !strings.HasPrefix(str, "0x") {
+		return fmt.Errorf("hex string without 0x prefix: %s", str)
+	}
+	buf, err := hex.DecodeString(str[2:])
+	if err != nil {
+		return err
+	}
+	*h = buf
+	return nil
+}
+
+// blockArg is a block number, a block tag or a block hash
+type blockArg struct {
+	num  ethgo.BlockNumber
+	hash *ethgo.Hash
+}
+
+func (b *blockArg) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		BlockNumber *string     `json:"blockNumber"`
+		BlockHash   *ethgo.Hash `json:"blockHash"`
+	}
+	if len(data) != 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if obj.BlockHash != nil {
+			b.hash = obj.BlockHash
+			return nil
+		}
+		if obj.BlockNumber == nil {
+			return fmt.Errorf("block number or hash expected")
+		}
+		return b.decode(*obj.BlockNumber)
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return b.decode(str)
+}
+
+func (b *blockArg) decode(str string) error {
+	switch str {
+	case "latest", "safe", "finalized":
+		b.num = ethgo.Latest
+		return nil
+	case "earliest":
+		b.num = ethgo.Earliest
+		return nil
+	case "pending":
+		b.num = ethgo.Pending
+		return nil
+	}
+	if len(str) == 66 {
+		var hash ethgo.Hash
+		if err := hash.UnmarshalText([]byte(str)); err != nil {
+			return err
+		}
+		b.hash = &hash
+		return nil
+	}
+	var num hexUint
+	if err := num.UnmarshalText([]byte(str)); err != nil {
+		return err
+	}
+	b.num = ethgo.BlockNumber(num)
+	return nil
+}
+
+// callArgs are the arguments of eth_call, eth_estimateGas and eth_sendTransaction
+type callArgs struct {
+	From                 *ethgo.Address   `json:"from"`
+	To                   *ethgo.Address   `json:"to"`
+	Gas                  *hexUint         `json:"gas"`
+	GasPrice             *hexBig          `json:"gasPrice"`
+	MaxFeePerGas         *hexBig          `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexBig          `json:"maxPriorityFeePerGas"`
+	Value                *hexBig          `json:"value"`
+	Nonce                *hexUint         `json:"nonce"`
+	Data                 *hexBytes        `json:"data"`
+	Input                *hexBytes        `json:"input"`
+	AccessList           ethgo.AccessList `json:"accessList"`
+}
+
+func (c *callArgs) from() ethgo.Address {
+	if c.From == nil {
+		return ethgo.ZeroAddress
+	}
+	return *c.From
+}
+
+func (c *callArgs) data() []byte {
+	if c.Input != nil {
+		return *c.Input
+	}
+	if c.Data != nil {
+		return *c.Data
+	}
+	return nil
+}
+
+func (c *callArgs) gasPrice() *big.Int {
+	if c.GasPrice != nil {
+		return c.GasPrice.toInt()
+	}
+	if c.MaxFeePerGas != nil {
+		return c.MaxFeePerGas.toInt()
+	}
+	return new(big.Int)
+}
+
+// overrideAccountArg is an account of the state override of eth_call
+type overrideAccountArg struct {
+	Nonce     *hexUint                  `json:"nonce"`
+	Code      *hexBytes                 `json:"code"`
+	Balance   *hexBig                   `json:"balance"`
+	State     map[ethgo.Hash]ethgo.Hash `json:"state"`
+	StateDiff map[ethgo.Hash]ethgo.Hash `json:"stateDiff"`
+}
+
+// blockOverridesArg are the block overrides of eth_call
+type blockOverridesArg struct {
+	Number     *hexBig        `json:"number"`
+	Difficulty *hexBig        `json:"difficulty"`
+	Time       *hexUint       `json:"time"`
+	GasLimit   *hexUint       `json:"gasLimit"`
+	Coinbase   *ethgo.Address `json:"feeRecipient"`
+	BaseFee    *hexBig        `json:"baseFeePerGas"`
+}
+
+// addressesArg is a single address or a list of addresses
+type addressesArg []ethgo.Address
+
+func (a *addressesArg) UnmarshalJSON(data []byte) error {
+	if len(data) != 0 && data[0] == '[' {
+		var addrs []ethgo.Address
+		if err := json.Unmarshal(data, &addrs); err != nil {
+			return err
+		}
+		*a = addrs
+		return nil
+	}
+	var addr ethgo.Address
+	if err := json.Unmarshal(data, &addr); err != nil {
+		return err
+	}
+	*a = []ethgo.Address{addr}
+	return nil
+}
+
+// topicArg is a single topic or a list of topics. A null topic matches any topic.
+type topicArg []ethgo.Hash
+
+func (t *topicArg) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = nil
+		return nil
+	}
+	if len(data) != 0 && data[0] == '[' {
+		var topics []ethgo.Hash
+		if err := json.Unmarshal(data, &topics); err != nil {
+			return err
+		}
+		*t = topics
+		return nil
+	}
+	var topic ethgo.Hash
+	if err := json.Unmarshal(data, &topic); err != nil {
+		return err
+	}
+	*t = []ethgo.Hash{topic}
+	return nil
+}
+
+// filterArg is the log filter of eth_getLogs and the logs subscription
+type filterArg struct {
+	Address   addressesArg `json:"address"`
+	Topics    []topicArg   `json:"topics"`
+	FromBlock *blockArg    `json:"fromBlock"`
+	ToBlock   *blockArg    `json:"toBlock"`
+	BlockHash *ethgo.Hash  `json:"blockHash"`
+}
+
+// match returns true if the log matches the address and topics of the filter
+func (f *filterArg) match(log *ethgo.Log) bool {
+	if len(f.Address) != 0 {
+		found := false
+		for _, addr := range f.Address {
+			if addr == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, topics := range f.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range topics {
+			if topic == log.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// rpcTransaction is the json encoding of a transaction
+type rpcTransaction struct {
+	Hash                 ethgo.Hash        `json:"hash"`
+	Type                 hexUint           `json:"type"`
+	From                 ethgo.Address     `json:"from"`
+	To                   *ethgo.Address    `json:"to"`
+	Input                hexBytes          `json:"input"`
+	Value                *hexBig           `json:"value"`
+	GasPrice             hexUint           `json:"gasPrice"`
+	Gas                  hexUint           `json:"gas"`
+	Nonce                hexUint           `json:"nonce"`
+	ChainID              *hexBig           `json:"chainId,omitempty"`
+	MaxFeePerGas         *hexBig           `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexBig           `json:"maxPriorityFeePerGas,omitempty"`
+	AccessList           *ethgo.AccessList `json:"accessList,omitempty"`
+	V                    hexBytes          `json:"v"`
+	R                    hexBytes          `json:"r"`
+	S                    hexBytes          `json:"s"`
+	BlockHash            *ethgo.Hash       `json:"blockHash"`
+	BlockNumber          *hexUint          `json:"blockNumber"`
+	TransactionIndex     *hexUint          `json:"transactionIndex"`
+}
+
+func newRPCTransaction(txn *ethgo.Transaction, mined bool) *rpcTransaction {
+	res := &rpcTransaction{
+		Hash:     txn.Hash,
+		Type:     hexUint(txn.Type),
+		From:     txn.From,
+		To:       txn.To,
+		Input:    txn.Input,
+		Value:    newHexBig(txn.Value),
+		GasPrice: hexUint(txn.GasPrice),
+		Gas:      hexUint(txn.Gas),
+		Nonce:    hexUint(txn.Nonce),
+		V:        txn.V,
+		R:        txn.R,
+		S:        txn.S,
+	}
+	if txn.Type != ethgo.TransactionLegacy {
+		accessList := txn.AccessList
+		if accessList == nil {
+			accessList = ethgo.AccessList{}
+		}
+		res.ChainID = newHexBig(txn.ChainID)
+		res.AccessList = &accessList
+	}
+	if txn.Type == ethgo.TransactionDynamicFee {
+		res.MaxFeePerGas = newHexBig(txn.MaxFeePerGas)
+		res.MaxPriorityFeePerGas = newHexBig(txn.MaxPriorityFeePerGas)
+	}
+	if mined {
+		blockHash := txn.BlockHash
+		blockNumber := hexUint(txn.BlockNumber)
+		index := hexUint(txn.TxnIndex)
+
+		res.BlockHash = &blockHash
+		res.BlockNumber = &blockNumber
+		res.TransactionIndex = &index
+	}
+	return res
+}
+
+// rpcBlock is the json encoding of a block
+type rpcBlock struct {
+	Number           hexUint       `json:"number"`
+	Hash             ethgo.Hash    `json:"hash"`
+	ParentHash       ethgo.Hash    `json:"parentHash"`
+	Sha3Uncles       ethgo.Hash    `json:"sha3Uncles"`
+	TransactionsRoot ethgo.Hash    `json:"transactionsRoot"`
+	StateRoot        ethgo.Hash    `json:"stateRoot"`
+	ReceiptsRoot     ethgo.Hash    `json:"receiptsRoot"`
+	Miner            ethgo.Address `json:"miner"`
+	Difficulty       *hexBig       `json:"difficulty"`
+	ExtraData        hexBytes      `json:"extraData"`
+	GasLimit         hexUint       `json:"gasLimit"`
+	GasUsed          hexUint       `json:"gasUsed"`
+	Timestamp        hexUint       `json:"timestamp"`
+	Transactions     []interface{} `json:"transactions"`
+	Uncles           []ethgo.Hash  `json:"uncles"`
+}
+
+func newRPCBlock(b *ethgo.Block, full bool) *rpcBlock {
+	res := &rpcBlock{
+		Number:           hexUint(b.Number),
+		Hash:             b.Hash,
+		ParentHash:       b.ParentHash,
+		Sha3Uncles:       b.Sha3Uncles,
+		TransactionsRoot: b.TransactionsRoot,
+		StateRoot:        b.StateRoot,
+		ReceiptsRoot:     b.ReceiptsRoot,
+		Miner:            b.Miner,
+		Difficulty:       newHexBig(b.Difficulty),
+		ExtraData:        b.ExtraData,
+		GasLimit:         hexUint(b.GasLimit),
+		GasUsed:          hexUint(b.GasUsed),
+		Timestamp:        hexUint(b.Timestamp),
+		Transactions:     []interface{}{},
+		Uncles:           []ethgo.Hash{},
+	}
+	for _, txn := range b.Transactions {
+		if full {
+			res.Transactions = append(res.Transactions, newRPCTransaction(txn, true))
+		} else {
+			res.Transactions = append(res.Transactions, txn.Hash)
+		}
+	}
+	return res
+}
+
+// rpcReceipt is the json encoding of a receipt
+type rpcReceipt struct {
+	TransactionHash   ethgo.Hash     `json:"transactionHash"`
+	TransactionIndex  hexUint        `json:"transactionIndex"`
+	BlockHash         ethgo.Hash     `json:"blockHash"`
+	BlockNumber       hexUint        `json:"blockNumber"`
+	From              ethgo.Address  `json:"from"`
+	To                *ethgo.Address `json:"to"`
+	ContractAddress   *ethgo.Address `json:"contractAddress"`
+	GasUsed           hexUint        `json:"gasUsed"`
+	CumulativeGasUsed hexUint        `json:"cumulativeGasUsed"`
+	EffectiveGasPrice hexUint        `json:"effectiveGasPrice"`
+	LogsBloom         hexBytes       `json:"logsBloom"`
+	Logs              []*ethgo.Log   `json:"logs"`
+	Status            hexUint        `json:"status"`
+	Type              hexUint        `json:"type"`
+}
+
+func newRPCReceipt(r *ethgo.Receipt, txn *ethgo.Transaction) *rpcReceipt {
+	res := &rpcReceipt{
+		TransactionHash:   r.TransactionHash,
+		TransactionIndex:  hexUint(r.TransactionIndex),
+		BlockHash:         r.BlockHash,
+		BlockNumber:       hexUint(r.BlockNumber),
+		From:              r.From,
+		To:                txn.To,
+		GasUsed:           hexUint(r.GasUsed),
+		CumulativeGasUsed: hexUint(r.CumulativeGasUsed),
+		EffectiveGasPrice: hexUint(txn.GasPrice),
+		LogsBloom:         r.LogsBloom,
+		Logs:              r.Logs,
+		Status:            hexUint(r.Status),
+		Type:              hexUint(txn.Type),
+	}
+	if txn.To == nil {
+		addr := r.ContractAddress
+		res.ContractAddress = &addr
+	}
+	if res.Logs == nil {
+		res.Logs = []*ethgo.Log{}
+	}
+	return res
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -18,7 +19,9 @@ import (
 	"github.com/umbracle/ethgo"
 	"github.com/umbracle/ethgo/blocktracker"
 	"github.com/umbracle/ethgo/etherscan"
+	"github.com/umbracle/ethgo/jsonrpc"
 	"github.com/umbracle/ethgo/jsonrpc/codec"
+	"github.com/umbracle/ethgo/metrics"
 	"github.com/umbracle/ethgo/tracker/store"
 	"github.com/umbracle/ethgo/tracker/store/inmem"
 )
@@ -87,6 +90,7 @@ type Config struct {
 	EtherscanAPIKey string
 	Filter          *FilterConfig
 	Store           store.Store
+	Tracer          metrics.Tracer
 }
 
 type ConfigOption func(*Config)
@@ -115,6 +119,13 @@ func WithFilter(f *FilterConfig) ConfigOption {
 	}
 }
 
+// WithTracer traces the sync of the log batches and the block tracker polls
+func WithTracer(tr metrics.Tracer) ConfigOption {
+	return func(c *Config) {
+		c.Tracer = tr
+	}
+}
+
 func WithEtherscan(k string) ConfigOption {
 	return func(c *Config) {
 		c.EtherscanAPIKey = k
@@ -335,17 +346,22 @@ func (t *Tracker) emitLogs(typ EventType, logs []*ethgo.Log) {
 }
 
 func tooMuchDataRequestedError(err error) bool {
-	obj, ok := err.(*codec.ErrorObject)
-	if !ok {
-		return false
-	}
-	if obj.Message == "query returned more than 10000 results" {
-		return true
-	}
-	return false
+	return errors.Is(err, codec.ErrLimitExceeded)
 }
 
-func (t *Tracker) syncBatch(ctx context.Context, from, to uint64) error {
+func (t *Tracker) syncBatch(ctx context.Context, from, to uint64) (err error) {
+	ctx, span := metrics.StartSpan(ctx, t.config.Tracer, "tracker.syncBatch",
+		metrics.Attr("from", from),
+		metrics.Attr("to", to),
+	)
+	defer func() {
+		metrics.EndSpan(span, err)
+	}()
+
+	var provider Provider = t.provider
+	if p, ok := provider.(jsonrpc.ContextProvider); ok {
+		provider = p.WithContext(ctx)
+	}
 	query := t.config.Filter.getFilterSearch()
 
 	batchSize := t.config.BatchSize
@@ -359,7 +375,7 @@ START:
 	query.SetFromUint64(i)
 	query.SetToUint64(dst)
 
-	logs, err := t.provider.GetLogs(query)
+	logs, err := provider.GetLogs(query)
 	if err != nil {
 		if tooMuchDataRequestedError(err) {
 			// multiplicative decrease
@@ -383,7 +399,7 @@ START:
 	t.emitLogs(EventAdd, logs)
 
 	// update the last block entry
-	block, err := t.provider.GetBlockByNumber(ethgo.BlockNumber(dst), false)
+	block, err := provider.GetBlockByNumber(ethgo.BlockNumber(dst), false)
 	if err != nil {
 		return err
 	}
@@ -535,7 +551,7 @@ func (t *Tracker) BatchSync(ctx context.Context) error {
 
 	if t.blockTracker == nil {
 		// run a specfic block tracker
-		t.blockTracker = blocktracker.NewBlockTracker(t.provider)
+		t.blockTracker = blocktracker.NewBlockTracker(t.provider, blocktracker.WithTracer(t.config.Tracer))
 		if err := t.blockTracker.Init(); err != nil {
 			return err
 		}
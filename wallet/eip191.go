@@ -0,0 +1,133 @@
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/umbracle/ethgo"
+)
+
+// Versions of the EIP-191 signed data
+const (
+	// EIP191ValidatorVersion is the version of the data with intended validator
+	EIP191ValidatorVersion byte = 0x00
+
+	// EIP191PersonalVersion is the version of the personal messages (personal_sign)
+	EIP191PersonalVersion byte = 0x45
+)
+
+// personalMessagePrefix is the prefix of the personal messages before the length of the message
+const personalMessagePrefix = "\x19Ethereum Signed Message:\n"
+
+// PersonalMessageHash returns the EIP-191 hash of a personal message (version 0x45)
+// as signed by personal_sign and eth_sign
+func PersonalMessageHash(msg []byte) []byte {
+	prefix := personalMessagePrefix + strconv.Itoa(len(msg))
+	return ethgo.Keccak256([]byte(prefix), msg)
+}
+
+// ValidatorMessageHash returns the EIP-191 hash of the data for an intended validator (version 0x00)
+func ValidatorMessageHash(validator ethgo.Address, msg []byte) []byte {
+	return ethgo.Keccak256([]byte{0x19, EIP191ValidatorVersion}, validator[:], msg)
+}
+
+// SignPersonalMessage signs a personal message with the key. The V value of the
+// signature is 27 or 28 as in personal_sign.
+func SignPersonalMessage(key ethgo.Key, msg []byte) ([]byte, error) {
+	return signHash27(key, PersonalMessageHash(msg))
+}
+
+// RecoverPersonalMessage returns the address that signed the personal message
+func RecoverPersonalMessage(msg, signature []byte) (ethgo.Address, error) {
+	return recoverHash(PersonalMessageHash(msg), signature)
+}
+
+// SignValidatorMessage signs the data for the intended validator with the key.
+// The V value of the signature is 27 or 28.
+func SignValidatorMessage(key ethgo.Key, validator ethgo.Address, msg []byte) ([]byte, error) {
+	return signHash27(key, ValidatorMessageHash(validator, msg))
+}
+
+// RecoverValidatorMessage returns the address that signed the data for the intended validator
+func RecoverValidatorMessage(validator ethgo.Address, msg, signature []byte) (ethgo.Address, error) {
+	return recoverHash(ValidatorMessageHash(validator, msg), signature)
+}
+
+func signHash27(key ethgo.Key, hash []byte) ([]byte, error) {
+	signature, err := key.Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Bytes27(), nil
+}
+
+func recoverHash(hash, signature []byte) (ethgo.Address, error) {
+	sig, err := ParseSignature(signature)
+	if err != nil {
+		return ethgo.Address{}, err
+	}
+	return Ecrecover(hash, sig.Bytes())
+}
+
+// Signature is a secp256k1 signature
+type Signature struct {
+	R [32]byte
+	S [32]byte
+
+	// V is the recovery id, either 0 or 1
+	V byte
+}
+
+// ParseSignature parses a 65 bytes signature in the [R || S || V] format.
+// V can be either 0 or 1 or 27 or 28.
+func ParseSignature(signature []byte) (*Signature, error) {
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("invalid signature length %d, expected 65", len(signature))
+	}
+
+	sig := &Signature{}
+	copy(sig.R[:], signature[:32])
+	copy(sig.S[:], signature[32:64])
+
+	switch v := signature[64]; v {
+	case 0, 1:
+		sig.V = v
+	case 27, 28:
+		sig.V = v - 27
+	default:
+		return nil, fmt.Errorf("invalid signature recovery id %d", v)
+	}
+	if sig.R == ([32]byte{}) || sig.S == ([32]byte{}) {
+		return nil, fmt.Errorf("invalid signature with zero values")
+	}
+	return sig, nil
+}
+
+// Bytes returns the signature with V as 0 or 1 as returned by ethgo.Key
+func (s *Signature) Bytes() []byte {
+	buf := make([]byte, 65)
+	copy(buf[:32], s.R[:])
+	copy(buf[32:64], s.S[:])
+	buf[64] = s.V
+	return buf
+}
+
+// Bytes27 returns the signature with V as 27 or 28 as returned by personal_sign
+func (s *Signature) Bytes27() []byte {
+	buf := s.Bytes()
+	buf[64] += 27
+	return buf
+}
+
+// NormalizeSignature returns the signature with V as 0 or 1
+func NormalizeSignature(signature []byte) ([]byte, error) {
+	sig, err := ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/umbracle/ethgo"
+)
+
+func TestEIP191_PersonalMessage(t *testing.T) {
+	assert.Equal(t,
+		"a1de988600a42c4b4ab089b619297c17d53cffae5d5120d82d8a92d0bb3b78f2",
+		hex.EncodeToString(PersonalMessageHash([]byte("Hello World"))),
+	)
+
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	msg := []byte("hello world")
+	sig, err := SignPersonalMessage(key, msg)
+	assert.NoError(t, err)
+	assert.True(t, sig[64] == 27 || sig[64] == 28)
+
+	addr, err := RecoverPersonalMessage(msg, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Address(), addr)
+
+	// the signature with V as 0 or 1 is valid too
+	normalized, err := NormalizeSignature(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, sig[64]-27, normalized[64])
+
+	addr, err = RecoverPersonalMessage(msg, normalized)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Address(), addr)
+
+	// it is not a signature of the raw message
+	addr, err = EcrecoverMsg(msg, sig)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.Address(), addr)
+}
+
+func TestEIP191_ValidatorMessage(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	validator := ethgo.Address{0x1}
+	msg := []byte{0x1, 0x2, 0x3}
+
+	hash := ValidatorMessageHash(validator, msg)
+	assert.Equal(t, ethgo.Keccak256(append(append([]byte{0x19, 0x0}, validator[:]...), msg...)), hash)
+
+	sig, err := SignValidatorMessage(key, validator, msg)
+	assert.NoError(t, err)
+
+	addr, err := RecoverValidatorMessage(validator, msg, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Address(), addr)
+
+	addr, err = RecoverValidatorMessage(ethgo.Address{0x2}, msg, sig)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.Address(), addr)
+}
+
+func TestParseSignature(t *testing.T) {
+	sig := make([]byte, 65)
+	sig[0], sig[32] = 0x1, 0x2
+
+	for _, v := range []byte{0, 1, 27, 28} {
+		sig[64] = v
+		s, err := ParseSignature(sig)
+		assert.NoError(t, err)
+		assert.Equal(t, v%27, s.V)
+		assert.Equal(t, v%27+27, s.Bytes27()[64])
+	}
+
+	sig[64] = 2
+	_, err := ParseSignature(sig)
+	assert.Error(t, err)
+
+	_, err = ParseSignature(sig[:64])
+	assert.Error(t, err)
+
+	_, err = ParseSignature(make([]byte, 65))
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"fmt"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/umbracle/ethgo"
@@ -29,6 +30,8 @@ func (k *Key) MarshallPrivateKey() ([]byte, error) {
 	return (*btcec.PrivateKey)(k.priv).Serialize(), nil
 }
 
+// SignMsg signs the keccak256 hash of the message. The signature is not compatible
+// with personal_sign, use SignPersonalMessage for EIP-191 personal messages.
 func (k *Key) SignMsg(msg []byte) ([]byte, error) {
 	return k.Sign(ethgo.Keccak256(msg))
 }
@@ -81,10 +84,15 @@ func Ecrecover(hash, signature []byte) (ethgo.Address, error) {
 	return pubKeyToAddress(pub), nil
 }
 
+// RecoverPubkey returns the public key that signed the hash. The V
+// value of the signature can be either 0 or 1 or 27 or 28.
 func RecoverPubkey(signature, hash []byte) (*ecdsa.PublicKey, error) {
 	size := len(signature)
+	if size == 0 {
+		return nil, fmt.Errorf("empty signature")
+	}
 	term := byte(27)
-	if signature[size-1] == 1 {
+	if v := signature[size-1]; v == 1 || v == 28 {
 		term = 28
 	}
 
@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/umbracle/ethgo"
@@ -25,9 +26,17 @@ func NewEIP155Signer(chainID uint64) *EIP1155Signer {
 
 func (e *EIP1155Signer) RecoverSender(tx *ethgo.Transaction) (ethgo.Address, error) {
 	v := new(big.Int).SetBytes(tx.V).Uint64()
-	v -= e.chainID * 2
-	v -= 8
-	v -= 27
+	if tx.Type != ethgo.TransactionLegacy {
+		// the typed transactions have the chain id in the payload
+		// and the recovery id as v
+		if tx.ChainID == nil || tx.ChainID.Uint64() != e.chainID {
+			return ethgo.Address{}, fmt.Errorf("invalid chain id for signer")
+		}
+	} else {
+		v -= e.chainID * 2
+		v -= 8
+		v -= 27
+	}
 
 	sig, err := encodeSignature(tx.R, tx.S, byte(v))
 	if err != nil {
@@ -51,6 +60,9 @@ func trimBytesZeros(b []byte) []byte {
 }
 
 func (e *EIP1155Signer) SignTx(tx *ethgo.Transaction, key ethgo.Key) (*ethgo.Transaction, error) {
+	if tx.Type != ethgo.TransactionLegacy && tx.ChainID == nil {
+		tx.ChainID = new(big.Int).SetUint64(e.chainID)
+	}
 	hash := signHash(tx, e.chainID)
 
 	sig, err := key.Sign(hash)
@@ -58,7 +70,10 @@ func (e *EIP1155Signer) SignTx(tx *ethgo.Transaction, key ethgo.Key) (*ethgo.Tra
 		return nil, err
 	}
 
-	vv := uint64(sig[64]) + 35 + e.chainID*2
+	vv := uint64(sig[64])
+	if tx.Type == ethgo.TransactionLegacy {
+		vv += 35 + e.chainID*2
+	}
 
 	tx.R = trimBytesZeros(sig[:32])
 	tx.S = trimBytesZeros(sig[32:64])
@@ -67,6 +82,9 @@ func (e *EIP1155Signer) SignTx(tx *ethgo.Transaction, key ethgo.Key) (*ethgo.Tra
 }
 
 func signHash(tx *ethgo.Transaction, chainID uint64) []byte {
+	if tx.Type != ethgo.TransactionLegacy {
+		return typedSignHash(tx)
+	}
 	a := fastrlp.DefaultArenaPool.Get()
 
 	v := a.NewArray()
@@ -93,6 +111,36 @@ func signHash(tx *ethgo.Transaction, chainID uint64) []byte {
 	return hash
 }
 
+// typedSignHash returns the hash signed by the EIP-2718 typed transactions
+func typedSignHash(tx *ethgo.Transaction) []byte {
+	a := fastrlp.DefaultArenaPool.Get()
+
+	v := a.NewArray()
+	v.Set(a.NewBigInt(tx.ChainID))
+	v.Set(a.NewUint(tx.Nonce))
+	if tx.Type == ethgo.TransactionDynamicFee {
+		v.Set(a.NewBigInt(tx.MaxPriorityFeePerGas))
+		v.Set(a.NewBigInt(tx.MaxFeePerGas))
+	} else {
+		v.Set(a.NewUint(tx.GasPrice))
+	}
+	v.Set(a.NewUint(tx.Gas))
+	if tx.To == nil {
+		v.Set(a.NewNull())
+	} else {
+		v.Set(a.NewCopyBytes((*tx.To)[:]))
+	}
+	v.Set(a.NewBigInt(tx.Value))
+	v.Set(a.NewCopyBytes(tx.Input))
+
+	accessList, _ := tx.AccessList.MarshalRLPWith(a)
+	v.Set(accessList)
+
+	hash := ethgo.Keccak256([]byte{byte(tx.Type)}, v.MarshalTo(nil))
+	fastrlp.DefaultArenaPool.Put(a)
+	return hash
+}
+
 func encodeSignature(R, S []byte, V byte) ([]byte, error) {
 	sig := make([]byte, 65)
 	copy(sig[32-len(R):32], R)
@@ -36,6 +36,46 @@ func TestSigner_EIP1155(t *testing.T) {
 	*/
 }
 
+func TestSigner_EIP1155_Typed(t *testing.T) {
+	signer := NewEIP155Signer(1337)
+
+	addr0 := ethgo.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	for _, typ := range []ethgo.TransactionType{ethgo.TransactionAccessList, ethgo.TransactionDynamicFee} {
+		txn := &ethgo.Transaction{
+			Type:                 typ,
+			To:                   &addr0,
+			Value:                big.NewInt(10),
+			Gas:                  21000,
+			GasPrice:             1,
+			MaxFeePerGas:         big.NewInt(2),
+			MaxPriorityFeePerGas: big.NewInt(1),
+			AccessList: ethgo.AccessList{
+				{Address: addr0, Storage: []ethgo.Hash{{0x1}}},
+			},
+		}
+		txn, err = signer.SignTx(txn, key)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1337), txn.ChainID.Uint64())
+
+		// the signature is kept after the encoding
+		raw, err := txn.MarshalRLPTo(nil)
+		assert.NoError(t, err)
+
+		txn2 := &ethgo.Transaction{}
+		assert.NoError(t, txn2.UnmarshalRLP(raw))
+
+		from, err := signer.RecoverSender(txn2)
+		assert.NoError(t, err)
+		assert.Equal(t, key.addr, from)
+
+		_, err = NewEIP155Signer(1).RecoverSender(txn2)
+		assert.Error(t, err)
+	}
+}
+
 func TestTrimBytesZeros(t *testing.T) {
 	assert.Equal(t, trimBytesZeros([]byte{0x1, 0x2}), []byte{0x1, 0x2})
 	assert.Equal(t, trimBytesZeros([]byte{0x0, 0x1}), []byte{0x1})